@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+)
+
+// gcloudInventory lists and deletes resources in one project via the gcloud CLI.
+type gcloudInventory struct {
+	project string
+}
+
+// run executes gcloud against the project and returns stdout.
+func (g gcloudInventory) run(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "gcloud", append([]string{"--project", g.project, "--quiet"}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("gcloud %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// listJSON runs a gcloud list command with --format=json and decodes the result.
+func (g gcloudInventory) listJSON(ctx context.Context, out any, args ...string) error {
+	data, err := g.run(ctx, append(args, "--format=json")...)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode gcloud %s output: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// List implements Inventory.
+func (g gcloudInventory) List(ctx context.Context, kind Kind) ([]Resource, error) {
+	switch kind {
+	case KindNetwork:
+		var items []struct {
+			Name              string `json:"name"`
+			CreationTimestamp string `json:"creationTimestamp"`
+		}
+		if err := g.listJSON(ctx, &items, "compute", "networks", "list"); err != nil {
+			return nil, err
+		}
+		out := make([]Resource, 0, len(items))
+		for _, it := range items {
+			out = append(out, Resource{Kind: kind, Name: it.Name, Location: "global", Created: parseTime(it.CreationTimestamp)})
+		}
+		return out, nil
+
+	case KindCluster:
+		var items []struct {
+			Name       string `json:"name"`
+			Location   string `json:"location"`
+			CreateTime string `json:"createTime"`
+		}
+		if err := g.listJSON(ctx, &items, "container", "clusters", "list"); err != nil {
+			return nil, err
+		}
+		out := make([]Resource, 0, len(items))
+		for _, it := range items {
+			out = append(out, Resource{Kind: kind, Name: it.Name, Location: it.Location, Created: parseTime(it.CreateTime)})
+		}
+		return out, nil
+
+	case KindBucket:
+		var items []struct {
+			Name         string `json:"name"`
+			Location     string `json:"location"`
+			CreationTime string `json:"creation_time"`
+		}
+		if err := g.listJSON(ctx, &items, "storage", "buckets", "list"); err != nil {
+			return nil, err
+		}
+		out := make([]Resource, 0, len(items))
+		for _, it := range items {
+			out = append(out, Resource{Kind: kind, Name: it.Name, Location: strings.ToLower(it.Location), Created: parseTime(it.CreationTime)})
+		}
+		return out, nil
+
+	case KindServiceAccount:
+		var items []struct {
+			Email string `json:"email"`
+		}
+		if err := g.listJSON(ctx, &items, "iam", "service-accounts", "list"); err != nil {
+			return nil, err
+		}
+		out := make([]Resource, 0, len(items))
+		for _, it := range items {
+			accountID, _, _ := strings.Cut(it.Email, "@")
+			// The IAM API does not expose a creation time for service accounts.
+			out = append(out, Resource{Kind: kind, Name: accountID, ID: it.Email, Location: "global"})
+		}
+		return out, nil
+
+	case KindSecret:
+		var items []struct {
+			Name       string `json:"name"`
+			CreateTime string `json:"createTime"`
+		}
+		if err := g.listJSON(ctx, &items, "secrets", "list"); err != nil {
+			return nil, err
+		}
+		out := make([]Resource, 0, len(items))
+		for _, it := range items {
+			out = append(out, Resource{Kind: kind, Name: path.Base(it.Name), Location: "global", Created: parseTime(it.CreateTime)})
+		}
+		return out, nil
+
+	case KindWIFPool:
+		var items []struct {
+			Name  string `json:"name"`
+			State string `json:"state"`
+		}
+		if err := g.listJSON(ctx, &items, "iam", "workload-identity-pools", "list", "--location=global"); err != nil {
+			return nil, err
+		}
+		out := make([]Resource, 0, len(items))
+		for _, it := range items {
+			// Soft-deleted pools are already on their way out (30 days) and
+			// cannot be deleted again.
+			if it.State == "DELETED" {
+				continue
+			}
+			// The IAM API does not expose a creation time for pools.
+			out = append(out, Resource{Kind: kind, Name: path.Base(it.Name), Location: "global"})
+		}
+		return out, nil
+
+	case KindLogSink:
+		var items []struct {
+			Name       string `json:"name"`
+			CreateTime string `json:"createTime"`
+		}
+		if err := g.listJSON(ctx, &items, "logging", "sinks", "list"); err != nil {
+			return nil, err
+		}
+		out := make([]Resource, 0, len(items))
+		for _, it := range items {
+			out = append(out, Resource{Kind: kind, Name: it.Name, Location: "global", Created: parseTime(it.CreateTime)})
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported kind %q", kind)
+}
+
+// Delete implements Inventory.
+func (g gcloudInventory) Delete(ctx context.Context, r Resource) error {
+	var err error
+	switch r.Kind {
+	case KindNetwork:
+		return g.deleteNetwork(ctx, r.Name)
+	case KindCluster:
+		_, err = g.run(ctx, "container", "clusters", "delete", r.Name, "--location", r.Location)
+	case KindBucket:
+		// Removes all objects (including noncurrent versions) and the bucket.
+		_, err = g.run(ctx, "storage", "rm", "--recursive", "gs://"+r.Name)
+	case KindServiceAccount:
+		_, err = g.run(ctx, "iam", "service-accounts", "delete", r.ID)
+	case KindSecret:
+		_, err = g.run(ctx, "secrets", "delete", r.Name)
+	case KindWIFPool:
+		_, err = g.run(ctx, "iam", "workload-identity-pools", "delete", r.Name, "--location=global")
+	case KindLogSink:
+		_, err = g.run(ctx, "logging", "sinks", "delete", r.Name)
+	default:
+		err = fmt.Errorf("unsupported kind %q", r.Kind)
+	}
+	return err
+}
+
+// deleteNetwork removes the routers (and their NATs), firewall rules and
+// subnets that block network deletion, then the network itself.
+func (g gcloudInventory) deleteNetwork(ctx context.Context, network string) error {
+	filter := fmt.Sprintf("--filter=network~/%s$", network)
+
+	var routers []struct {
+		Name   string `json:"name"`
+		Region string `json:"region"`
+	}
+	if err := g.listJSON(ctx, &routers, "compute", "routers", "list", filter); err != nil {
+		return err
+	}
+	for _, r := range routers {
+		if _, err := g.run(ctx, "compute", "routers", "delete", r.Name, "--region", path.Base(r.Region)); err != nil {
+			return err
+		}
+	}
+
+	var rules []struct {
+		Name string `json:"name"`
+	}
+	if err := g.listJSON(ctx, &rules, "compute", "firewall-rules", "list", filter); err != nil {
+		return err
+	}
+	for _, r := range rules {
+		if _, err := g.run(ctx, "compute", "firewall-rules", "delete", r.Name); err != nil {
+			return err
+		}
+	}
+
+	var subnets []struct {
+		Name   string `json:"name"`
+		Region string `json:"region"`
+	}
+	if err := g.listJSON(ctx, &subnets, "compute", "networks", "subnets", "list", "--network", network); err != nil {
+		return err
+	}
+	for _, s := range subnets {
+		if _, err := g.run(ctx, "compute", "networks", "subnets", "delete", s.Name, "--region", path.Base(s.Region)); err != nil {
+			return err
+		}
+	}
+
+	_, err := g.run(ctx, "compute", "networks", "delete", network)
+	return err
+}
+
+// timeLayouts covers the timestamp formats gcloud emits across services
+// (RFC 3339 for most APIs, a numeric zone without colon for gcloud storage).
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05.999999-0700",
+}
+
+// parseTime parses a gcloud timestamp, returning the zero time if it is
+// missing or unrecognised (the resource is then treated as unknown age).
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range timeLayouts {
+		if ts, err := time.Parse(layout, s); err == nil {
+			return ts
+		}
+	}
+	return time.Time{}
+}
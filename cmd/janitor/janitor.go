@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Kind identifies a class of GCP resource the janitor knows how to list and delete.
+type Kind string
+
+// Resource kinds created by the test suite.
+const (
+	KindNetwork        Kind = "network"
+	KindCluster        Kind = "cluster"
+	KindBucket         Kind = "bucket"
+	KindServiceAccount Kind = "service-account"
+	KindSecret         Kind = "secret"
+	KindWIFPool        Kind = "wif-pool"
+	KindLogSink        Kind = "log-sink"
+)
+
+// AllKinds lists every kind in deletion order: dependents before the things
+// they depend on (clusters before networks, sinks before buckets).
+var AllKinds = []Kind{
+	KindCluster,
+	KindLogSink,
+	KindBucket,
+	KindSecret,
+	KindWIFPool,
+	KindServiceAccount,
+	KindNetwork,
+}
+
+// DefaultPatterns are the resource-name globs used by the Go test suite.
+// Generic words are anchored on the 6-character UniqueName suffix, and the
+// service account forms also on the region code (TestRegion.Code, e.g. usc1,
+// apse1, euw10) or key the test appends, so that e.g. a "backup-agent" or
+// "backup-prod-abc123" service account is left alone.
+var DefaultPatterns = []string{
+	// networks and clusters
+	"test-vpc-*",
+	"gke-test-*",
+	"neo4j-test-*",
+	// log sinks (<project>-...)
+	"*-audit-test-sink-??????",
+	// buckets (<project>-...)
+	"*-backup-test-*",
+	"*-audit-test-*",
+	"*-audit-disabled-*",
+	"*-novers-*",
+	"*-neo4j-bkp-*",
+	"*-state-test-??????",
+	// secrets
+	"test-secret-*",
+	"secret-one-*",
+	"secret-two-*",
+	// service accounts
+	"test-sa-*",
+	"neo4j-bk-*",
+	"backup-[a-z][a-z][a-z][0-9]-??????",
+	"backup-[a-z][a-z][a-z][a-z][0-9]-??????",
+	"backup-[a-z][a-z][a-z][0-9][0-9]-??????",
+	"bkp-[a-z][a-z][a-z][0-9]-??????",
+	"bkp-[a-z][a-z][a-z][a-z][0-9]-??????",
+	"bkp-[a-z][a-z][a-z][0-9][0-9]-??????",
+	"it-??????-ci",
+	// workload identity pools (a stable ID the WIF test adopts across runs)
+	"gha-terratest",
+}
+
+// Resource is one listed cloud resource.
+type Resource struct {
+	Kind Kind
+	// Name is the short name matched against patterns (e.g. the bucket name or
+	// the service account ID without the domain).
+	Name string
+	// ID is what the inventory needs to delete the resource (e.g. SA email).
+	// Empty means Name.
+	ID string
+	// Location is the region/zone/"global" where relevant.
+	Location string
+	// Created is the creation time. The zero value means the API does not
+	// expose one (service accounts, WIF pools).
+	Created time.Time
+}
+
+// Inventory lists and deletes resources. The gcloud-backed implementation is
+// used by the command; tests use an in-memory fake.
+type Inventory interface {
+	List(ctx context.Context, kind Kind) ([]Resource, error)
+	Delete(ctx context.Context, r Resource) error
+}
+
+// Config controls a sweep.
+type Config struct {
+	Patterns []string
+	TTL      time.Duration
+	Now      time.Time
+	// Delete removes expired resources. Without it the sweep only reports.
+	Delete bool
+	// IncludeUnknownAge treats resources without a creation time as expired.
+	IncludeUnknownAge bool
+}
+
+// Status describes what the sweep decided for a matched resource.
+type Status string
+
+// Sweep outcomes.
+const (
+	StatusKept        Status = "kept"
+	StatusUnknownAge  Status = "unknown-age"
+	StatusWouldDelete Status = "would-delete"
+	StatusDeleted     Status = "deleted"
+	StatusFailed      Status = "delete-failed"
+)
+
+// Finding is a resource that matched a test naming pattern.
+type Finding struct {
+	Resource
+	Pattern string
+	Age     time.Duration
+	Status  Status
+	Err     error
+}
+
+// Sweep lists each kind, keeps resources matching a pattern, and deletes the
+// expired ones when cfg.Delete is set. Listing errors abort the sweep; delete
+// errors are recorded on the finding so one stuck resource doesn't block the rest.
+func Sweep(ctx context.Context, inv Inventory, cfg Config, kinds []Kind) ([]Finding, error) {
+	if cfg.Now.IsZero() {
+		cfg.Now = time.Now()
+	}
+
+	var findings []Finding
+	for _, kind := range kinds {
+		resources, err := inv.List(ctx, kind)
+		if err != nil {
+			return findings, fmt.Errorf("list %s: %w", kind, err)
+		}
+
+		for _, r := range resources {
+			pattern, ok := matchPattern(cfg.Patterns, r.Name)
+			if !ok {
+				continue
+			}
+
+			f := Finding{Resource: r, Pattern: pattern, Status: classify(cfg, r)}
+			if !r.Created.IsZero() {
+				f.Age = cfg.Now.Sub(r.Created)
+			}
+
+			if f.Status == StatusWouldDelete && cfg.Delete {
+				if err := inv.Delete(ctx, r); err != nil {
+					f.Status, f.Err = StatusFailed, err
+				} else {
+					f.Status = StatusDeleted
+				}
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+// classify decides whether a matched resource is past its TTL.
+func classify(cfg Config, r Resource) Status {
+	if r.Created.IsZero() {
+		if cfg.IncludeUnknownAge {
+			return StatusWouldDelete
+		}
+		return StatusUnknownAge
+	}
+	if cfg.Now.Sub(r.Created) < cfg.TTL {
+		return StatusKept
+	}
+	return StatusWouldDelete
+}
+
+// matchPattern returns the first glob in patterns that matches name.
+func matchPattern(patterns []string, name string) (string, bool) {
+	for _, p := range patterns {
+		if ok, err := path.Match(p, name); err == nil && ok {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// Report writes findings as an aligned table, oldest first within each kind.
+func Report(w io.Writer, findings []Finding) error {
+	sorted := append([]Finding(nil), findings...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Kind != sorted[j].Kind {
+			return sorted[i].Kind < sorted[j].Kind
+		}
+		return sorted[i].Age > sorted[j].Age
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAME\tLOCATION\tAGE\tPATTERN\tSTATUS")
+	for _, f := range sorted {
+		age := "unknown"
+		if !f.Created.IsZero() {
+			age = f.Age.Truncate(time.Minute).String()
+		}
+		status := string(f.Status)
+		if f.Err != nil {
+			status = fmt.Sprintf("%s: %v", f.Status, f.Err)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", f.Kind, f.Name, f.Location, age, f.Pattern, status)
+	}
+	return tw.Flush()
+}
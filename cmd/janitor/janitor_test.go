@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	testhelpers "github.com/simon-lentz/neo4j_gke/test"
+)
+
+// fakeInventory is an in-memory Inventory recording deletions.
+type fakeInventory struct {
+	resources map[Kind][]Resource
+	deleted   []string
+	failOn    map[string]error
+	listErr   map[Kind]error
+}
+
+func (f *fakeInventory) List(_ context.Context, kind Kind) ([]Resource, error) {
+	if err := f.listErr[kind]; err != nil {
+		return nil, err
+	}
+	return f.resources[kind], nil
+}
+
+func (f *fakeInventory) Delete(_ context.Context, r Resource) error {
+	if err := f.failOn[r.Name]; err != nil {
+		return err
+	}
+	f.deleted = append(f.deleted, string(r.Kind)+"/"+r.Name)
+	return nil
+}
+
+var sweepNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newFakeInventory() *fakeInventory {
+	old := sweepNow.Add(-48 * time.Hour)
+	fresh := sweepNow.Add(-1 * time.Hour)
+	return &fakeInventory{
+		resources: map[Kind][]Resource{
+			KindNetwork: {
+				{Kind: KindNetwork, Name: "test-vpc-abc123", Created: old},
+				{Kind: KindNetwork, Name: "test-vpc-fresh", Created: fresh},
+				{Kind: KindNetwork, Name: "default", Created: old},
+			},
+			KindCluster: {
+				{Kind: KindCluster, Name: "gke-test-abc123", Location: "us-central1", Created: old},
+				{Kind: KindCluster, Name: "neo4j-test-abc123", Location: "us-central1", Created: old},
+				{Kind: KindCluster, Name: "prod-cluster", Location: "us-central1", Created: old},
+			},
+			KindBucket: {
+				{Kind: KindBucket, Name: "my-proj-backup-test-abc123", Created: old},
+				{Kind: KindBucket, Name: "my-proj-tfstate", Created: old},
+			},
+			KindLogSink: {
+				{Kind: KindLogSink, Name: "my-proj-audit-test-sink-abc123", Created: old},
+				{Kind: KindLogSink, Name: "my-proj-audit-sink", Created: old},
+				{Kind: KindLogSink, Name: "_Default", Created: old},
+			},
+			KindWIFPool: {
+				{Kind: KindWIFPool, Name: "gha-terratest"},
+				{Kind: KindWIFPool, Name: "github-prod"},
+			},
+		},
+	}
+}
+
+func TestSweep_ReportOnly(t *testing.T) {
+	inv := newFakeInventory()
+	cfg := Config{Patterns: DefaultPatterns, TTL: 24 * time.Hour, Now: sweepNow}
+
+	findings, err := Sweep(context.Background(), inv, cfg, AllKinds)
+	require.NoError(t, err)
+	require.Empty(t, inv.deleted, "report-only sweep must not delete")
+
+	byName := map[string]Finding{}
+	for _, f := range findings {
+		byName[f.Name] = f
+	}
+
+	// Non-matching names are never reported.
+	require.NotContains(t, byName, "default")
+	require.NotContains(t, byName, "prod-cluster")
+	require.NotContains(t, byName, "my-proj-tfstate")
+	require.NotContains(t, byName, "github-prod")
+	require.NotContains(t, byName, "my-proj-audit-sink")
+	require.NotContains(t, byName, "_Default")
+
+	require.Equal(t, StatusWouldDelete, byName["test-vpc-abc123"].Status)
+	require.Equal(t, "test-vpc-*", byName["test-vpc-abc123"].Pattern)
+	require.Equal(t, 48*time.Hour, byName["test-vpc-abc123"].Age)
+	require.Equal(t, StatusKept, byName["test-vpc-fresh"].Status)
+	require.Equal(t, StatusWouldDelete, byName["gke-test-abc123"].Status)
+	require.Equal(t, StatusWouldDelete, byName["neo4j-test-abc123"].Status)
+	require.Equal(t, "*-backup-test-*", byName["my-proj-backup-test-abc123"].Pattern)
+	require.Equal(t, StatusWouldDelete, byName["my-proj-audit-test-sink-abc123"].Status)
+	require.Equal(t, "*-audit-test-sink-??????", byName["my-proj-audit-test-sink-abc123"].Pattern)
+	require.Equal(t, StatusUnknownAge, byName["gha-terratest"].Status)
+
+	var buf bytes.Buffer
+	require.NoError(t, Report(&buf, findings))
+	require.Contains(t, buf.String(), "gke-test-abc123")
+	require.Contains(t, buf.String(), "would-delete")
+}
+
+func TestSweep_DeleteExpired(t *testing.T) {
+	inv := newFakeInventory()
+	cfg := Config{Patterns: DefaultPatterns, TTL: 24 * time.Hour, Now: sweepNow, Delete: true}
+
+	_, err := Sweep(context.Background(), inv, cfg, AllKinds)
+	require.NoError(t, err)
+
+	// AllKinds order: clusters before sinks before buckets before networks.
+	require.Equal(t, []string{
+		"cluster/gke-test-abc123",
+		"cluster/neo4j-test-abc123",
+		"log-sink/my-proj-audit-test-sink-abc123",
+		"bucket/my-proj-backup-test-abc123",
+		"network/test-vpc-abc123",
+	}, inv.deleted)
+}
+
+func TestSweep_IncludeUnknownAge(t *testing.T) {
+	inv := newFakeInventory()
+	cfg := Config{Patterns: DefaultPatterns, TTL: 24 * time.Hour, Now: sweepNow, Delete: true, IncludeUnknownAge: true}
+
+	_, err := Sweep(context.Background(), inv, cfg, []Kind{KindWIFPool})
+	require.NoError(t, err)
+	require.Equal(t, []string{"wif-pool/gha-terratest"}, inv.deleted)
+}
+
+func TestSweep_DeleteFailureIsRecorded(t *testing.T) {
+	inv := newFakeInventory()
+	inv.failOn = map[string]error{"gke-test-abc123": errors.New("cluster is being updated")}
+	cfg := Config{Patterns: DefaultPatterns, TTL: 24 * time.Hour, Now: sweepNow, Delete: true}
+
+	findings, err := Sweep(context.Background(), inv, cfg, []Kind{KindCluster})
+	require.NoError(t, err)
+
+	require.Len(t, findings, 2)
+	require.Equal(t, StatusFailed, findings[0].Status)
+	require.ErrorContains(t, findings[0].Err, "being updated")
+	require.Equal(t, StatusDeleted, findings[1].Status)
+}
+
+func TestSweep_ListErrorAborts(t *testing.T) {
+	inv := newFakeInventory()
+	inv.listErr = map[Kind]error{KindBucket: errors.New("permission denied")}
+	cfg := Config{Patterns: DefaultPatterns, TTL: 24 * time.Hour, Now: sweepNow}
+
+	_, err := Sweep(context.Background(), inv, cfg, AllKinds)
+	require.ErrorContains(t, err, "list bucket")
+}
+
+// TestDefaultPatterns_MatchTestNames generates names the way the tests do and
+// checks that the janitor would find each of them.
+func TestDefaultPatterns_MatchTestNames(t *testing.T) {
+	region := testhelpers.TestRegion{Name: "us-central1"}
+	project := "my-proj"
+	names := map[string]string{
+		// test/
+		"vpc":                region.UniqueName(t, testhelpers.NameVPC, "test-vpc"),
+		"vpc without NAT":    region.UniqueName(t, testhelpers.NameVPC, "test-vpc-nonat"),
+		"gke vpc":            region.UniqueName(t, testhelpers.NameVPC, "gke-test-vpc"),
+		"cluster":            region.UniqueName(t, testhelpers.NameCluster, "gke-test"),
+		"backup bucket":      region.UniqueName(t, testhelpers.NameBucket, project+"-backup-test"),
+		"unversioned bucket": region.UniqueName(t, testhelpers.NameBucket, project+"-novers"),
+		"audit bucket":       testhelpers.UniqueName(t, testhelpers.NameBucket, project+"-audit-test"),
+		"audit bucket (off)": testhelpers.UniqueName(t, testhelpers.NameBucket, project+"-audit-disabled"),
+		"audit sink":         testhelpers.UniqueName(t, testhelpers.NameLogSink, project+"-audit-test-sink"),
+		"state bucket":       testhelpers.UniqueName(t, testhelpers.NameBucket, project+"-state-test"),
+		"secret":             testhelpers.UniqueName(t, testhelpers.NameSecret, "test-secret"),
+		"secret with ACL":    testhelpers.UniqueName(t, testhelpers.NameSecret, "test-secret-acl"),
+		"first secret":       testhelpers.UniqueName(t, testhelpers.NameSecret, "secret-one"),
+		"second secret":      testhelpers.UniqueName(t, testhelpers.NameSecret, "secret-two"),
+		"accessor SA":        testhelpers.UniqueName(t, testhelpers.NameServiceAccount, "test-sa"),
+		"backup SA":          region.UniqueName(t, testhelpers.NameServiceAccount, "backup"),
+		"bucket SA":          region.UniqueName(t, testhelpers.NameServiceAccount, "bkp"),
+		"backup SA (apse1)":  testhelpers.TestRegion{Name: "asia-southeast1"}.UniqueName(t, testhelpers.NameServiceAccount, "backup"),
+		"bucket SA (euw10)":  testhelpers.TestRegion{Name: "europe-west10"}.UniqueName(t, testhelpers.NameServiceAccount, "bkp"),
+		"prefixed SA":        testhelpers.UniqueName(t, testhelpers.NameServiceAccount, "it") + "-ci",
+		"wif pool":           "gha-terratest",
+		// test/e2e/, suffixed with random.UniqueId
+		"e2e vpc":           "neo4j-test-vpc-abc123",
+		"e2e cluster":       "neo4j-test-abc123",
+		"e2e backup SA":     "neo4j-bk-abc123",
+		"e2e backup bucket": project + "-neo4j-bkp-abc123",
+	}
+	for what, name := range names {
+		_, ok := matchPattern(DefaultPatterns, name)
+		require.Truef(t, ok, "no DefaultPatterns entry matches the %s name %q", what, name)
+	}
+
+	// Long-lived resources of a deployment are left alone.
+	for _, name := range []string{"neo4j-backup", "backup-agent", "my-proj-tfstate", "my-proj-state", "my-proj-audit-sink",
+		"neo4j-admin-password-dev", "gha-terratest-prod", "github-prod",
+		"backup-prod-abc123", "bkp-nightly-abc123", "backup-usc1-restore", "it-abc123-deployer",
+		// state buckets of a deployment, including bootstrap's randomize_bucket_name form
+		"my-proj-state-a1b2c3", "acme-prod-state-europe", "my-proj-state-backup"} {
+		p, ok := matchPattern(DefaultPatterns, name)
+		require.Falsef(t, ok, "%q should not match, but matches %q", name, p)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2025, 5, 30, 12, 0, 0, 0, time.UTC)
+	require.True(t, want.Equal(parseTime("2025-05-30T12:00:00Z")))
+	require.True(t, want.Equal(parseTime("2025-05-30T05:00:00.000-07:00")))
+	require.True(t, want.Equal(parseTime("2025-05-30T12:00:00+0000")))
+	require.True(t, parseTime("").IsZero())
+	require.True(t, parseTime("not a time").IsZero())
+}
+
+func TestParseKinds(t *testing.T) {
+	kinds, err := parseKinds("")
+	require.NoError(t, err)
+	require.Equal(t, AllKinds, kinds)
+
+	kinds, err = parseKinds("network, cluster")
+	require.NoError(t, err)
+	require.Equal(t, []Kind{KindCluster, KindNetwork}, kinds)
+
+	_, err = parseKinds("vm")
+	require.ErrorContains(t, err, `unknown kind "vm"`)
+}
@@ -0,0 +1,122 @@
+// Command janitor finds cloud resources left behind by the Go test suite.
+//
+// Destroys that fail in cleanup are recorded in the orphan ledger (see
+// cmd/orphans); this command collects what a killed run left behind. It lists
+// VPCs, GKE clusters, buckets, service accounts, secrets, WIF pools and log
+// sinks whose names match the test naming patterns and that are older than a
+// TTL. By default it only reports; pass -delete to remove them.
+//
+// Usage:
+//
+//	go run ./cmd/janitor -project my-project-123
+//	go run ./cmd/janitor -project my-project-123 -ttl 6h -delete
+//
+// The project defaults to NEO4J_GKE_GCP_PROJECT_ID.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "janitor:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		project     = flag.String("project", os.Getenv("NEO4J_GKE_GCP_PROJECT_ID"), "GCP project to sweep (default $NEO4J_GKE_GCP_PROJECT_ID)")
+		ttl         = flag.Duration("ttl", 24*time.Hour, "minimum age before a matching resource is considered orphaned")
+		del         = flag.Bool("delete", false, "delete expired resources (default: report only)")
+		unknownAge  = flag.Bool("include-unknown-age", false, "treat resources without a creation time (service accounts, WIF pools) as expired")
+		kindsFlag   = flag.String("kinds", "", "comma-separated kinds to sweep (default: all)")
+		extra       stringList
+		onlyPattern stringList
+	)
+	flag.Var(&extra, "pattern", "additional name glob to match (repeatable)")
+	flag.Var(&onlyPattern, "only-pattern", "replace the default name globs (repeatable)")
+	flag.Parse()
+
+	if *project == "" {
+		return fmt.Errorf("-project is required (or set NEO4J_GKE_GCP_PROJECT_ID)")
+	}
+
+	kinds, err := parseKinds(*kindsFlag)
+	if err != nil {
+		return err
+	}
+
+	patterns := DefaultPatterns
+	if len(onlyPattern) > 0 {
+		patterns = onlyPattern
+	}
+	patterns = append(append([]string(nil), patterns...), extra...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cfg := Config{
+		Patterns:          patterns,
+		TTL:               *ttl,
+		Now:               time.Now(),
+		Delete:            *del,
+		IncludeUnknownAge: *unknownAge,
+	}
+	findings, sweepErr := Sweep(ctx, gcloudInventory{project: *project}, cfg, kinds)
+	if err := Report(os.Stdout, findings); err != nil {
+		return err
+	}
+	if sweepErr != nil {
+		return sweepErr
+	}
+
+	for _, f := range findings {
+		if f.Status == StatusFailed {
+			return fmt.Errorf("one or more deletions failed")
+		}
+	}
+	if !*del {
+		fmt.Fprintln(os.Stdout, "\nReport only; re-run with -delete to remove would-delete resources.")
+	}
+	return nil
+}
+
+// parseKinds turns the -kinds flag into a list, preserving AllKinds order.
+func parseKinds(s string) ([]Kind, error) {
+	if strings.TrimSpace(s) == "" {
+		return AllKinds, nil
+	}
+	want := map[Kind]bool{}
+	for _, k := range strings.Split(s, ",") {
+		want[Kind(strings.TrimSpace(k))] = true
+	}
+	var kinds []Kind
+	for _, k := range AllKinds {
+		if want[k] {
+			kinds = append(kinds, k)
+			delete(want, k)
+		}
+	}
+	for k := range want {
+		return nil, fmt.Errorf("unknown kind %q", k)
+	}
+	return kinds, nil
+}
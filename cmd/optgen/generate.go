@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// Variable is one module input variable.
+type Variable struct {
+	Name        string
+	Description string
+	Type        cty.Type
+	// Default is the JSON-encoded default; empty when the variable is required.
+	Default  string
+	Required bool
+}
+
+// Module is a module directory and its variables, in declaration order.
+type Module struct {
+	Dir       string
+	TypeName  string
+	Variables []Variable
+}
+
+// typeNames overrides the Go type prefix derived from a module directory.
+var typeNames = map[string]string{
+	"neo4j_app": "NeoApp",
+}
+
+// initialisms are upper-cased when converting snake_case to Go names.
+var initialisms = map[string]string{
+	"api": "API", "cidr": "CIDR", "gcs": "GCS", "gke": "GKE", "gsa": "GSA",
+	"id": "ID", "ids": "IDs", "ip": "IP", "ips": "IPs", "ipv4": "IPv4", "k8s": "K8s",
+	"kms": "KMS", "nat": "NAT", "sa": "SA", "ttl": "TTL", "uri": "URI", "url": "URL",
+	"vpc": "VPC", "wif": "WIF",
+}
+
+// Generate parses every module under modulesDir, plus the extra module
+// directories (relative to modulesDir, mapped to their Go type prefix), and
+// returns the formatted Go source of the option builders.
+func Generate(modulesDir, pkg string, extra map[string]string) ([]byte, error) {
+	entries, err := os.ReadDir(modulesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := map[string]string{}
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs[e.Name()] = ""
+		}
+	}
+	for dir, typeName := range extra {
+		dirs[filepath.ToSlash(dir)] = typeName
+	}
+
+	var modules []Module
+	for dir, typeName := range dirs {
+		m, err := ParseModule(modulesDir, dir, typeName)
+		if err != nil {
+			return nil, err
+		}
+		if len(m.Variables) > 0 {
+			modules = append(modules, m)
+		}
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Dir < modules[j].Dir })
+
+	g := &generator{pkg: pkg}
+	return g.render(modules)
+}
+
+// ParseModule reads the variable blocks from every .tf file in
+// modulesDir/dir. An empty typeName derives the Go type prefix from dir.
+func ParseModule(modulesDir, dir, typeName string) (Module, error) {
+	m := Module{Dir: dir, TypeName: typeName}
+	if m.TypeName == "" {
+		m.TypeName = goName(dir)
+		if override, ok := typeNames[dir]; ok {
+			m.TypeName = override
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(modulesDir, dir, "*.tf"))
+	if err != nil {
+		return m, err
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return m, err
+		}
+		file, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+		if diags.HasErrors() {
+			return m, diags
+		}
+		for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+			if block.Type != "variable" || len(block.Labels) != 1 {
+				continue
+			}
+			v, err := parseVariable(block)
+			if err != nil {
+				return m, fmt.Errorf("%s: variable %q: %w", path, block.Labels[0], err)
+			}
+			m.Variables = append(m.Variables, v)
+		}
+	}
+	return m, nil
+}
+
+func parseVariable(block *hclsyntax.Block) (Variable, error) {
+	v := Variable{Name: block.Labels[0], Type: cty.DynamicPseudoType, Required: true}
+
+	if attr, ok := block.Body.Attributes["type"]; ok {
+		ty, _, diags := typeexpr.TypeConstraintWithDefaults(attr.Expr)
+		if diags.HasErrors() {
+			return v, diags
+		}
+		v.Type = ty
+	}
+	if attr, ok := block.Body.Attributes["description"]; ok {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return v, diags
+		}
+		if val.Type() == cty.String && val.IsKnown() && !val.IsNull() {
+			v.Description = val.AsString()
+		}
+	}
+	if attr, ok := block.Body.Attributes["default"]; ok {
+		v.Required = false
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return v, diags
+		}
+		raw, err := ctyjson.Marshal(val, val.Type())
+		if err != nil {
+			return v, err
+		}
+		v.Default = string(raw)
+	}
+	return v, nil
+}
+
+// --- rendering ---
+
+type generator struct {
+	pkg     string
+	buf     bytes.Buffer
+	structs []string
+	seen    map[string]bool
+}
+
+func (g *generator) render(modules []Module) ([]byte, error) {
+	g.seen = map[string]bool{}
+
+	fmt.Fprintf(&g.buf, "// Code generated by cmd/optgen from infra/modules/*/variables.tf; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&g.buf, "package %s\n\n", g.pkg)
+	fmt.Fprintf(&g.buf, "import (\n\t\"testing\"\n\n\t\"github.com/gruntwork-io/terratest/modules/terraform\"\n)\n")
+
+	for _, m := range modules {
+		g.structs = nil
+		g.module(m)
+		for _, s := range g.structs {
+			g.buf.WriteString(s)
+		}
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, g.buf.String())
+	}
+	return src, nil
+}
+
+func (g *generator) module(m Module) {
+	typeName := m.TypeName + "Options"
+
+	fmt.Fprintf(&g.buf, "\n// %s holds the input variables of infra/modules/%s.\n", typeName, m.Dir)
+	fmt.Fprintf(&g.buf, "// Optional variables left nil use the module default.\n")
+	fmt.Fprintf(&g.buf, "type %s struct {\n", typeName)
+	for i, v := range m.Variables {
+		if i > 0 {
+			g.buf.WriteString("\n")
+		}
+		writeDoc(&g.buf, "\t", v.Description, v.Required, v.Default)
+		goType := g.goType(m.TypeName, v.Name, v.Type, !v.Required)
+		tag := v.Name
+		if v.Required {
+			tag += ",required"
+		}
+		fmt.Fprintf(&g.buf, "\t%s %s `tf:%q`\n", goName(v.Name), goType, tag)
+	}
+	g.buf.WriteString("}\n")
+
+	fmt.Fprintf(&g.buf, "\n// Module returns the module directory relative to infra/modules.\n")
+	fmt.Fprintf(&g.buf, "func (o *%s) Module() string { return %q }\n", typeName, m.Dir)
+
+	fmt.Fprintf(&g.buf, "\n// Vars returns the non-nil fields keyed by variable name.\n")
+	fmt.Fprintf(&g.buf, "func (o *%s) Vars(t *testing.T) map[string]any {\n\tt.Helper()\n\treturn moduleVars(t, o)\n}\n", typeName)
+
+	fmt.Fprintf(&g.buf, "\n// TerraformOptions returns tofu options for the module copy in terraformDir\n")
+	fmt.Fprintf(&g.buf, "// (see NewTerraformOptions).\n")
+	fmt.Fprintf(&g.buf, "func (o *%s) TerraformOptions(t *testing.T, terraformDir string) *terraform.Options {\n", typeName)
+	fmt.Fprintf(&g.buf, "\tt.Helper()\n\treturn moduleTerraformOptions(t, terraformDir, o.Vars(t))\n}\n")
+}
+
+// goType maps a cty type to a Go type. Optional scalars become pointers so
+// nil can mean "use the module default"; slices and maps are already nilable.
+func (g *generator) goType(prefix, name string, ty cty.Type, optional bool) string {
+	base := g.baseType(prefix, name, ty)
+	if optional && !strings.HasPrefix(base, "[]") && !strings.HasPrefix(base, "map[") && base != "any" {
+		return "*" + base
+	}
+	return base
+}
+
+func (g *generator) baseType(prefix, name string, ty cty.Type) string {
+	switch {
+	case ty == cty.String:
+		return "string"
+	case ty == cty.Number:
+		// Every number variable in the modules is a whole count or duration.
+		return "int"
+	case ty == cty.Bool:
+		return "bool"
+	case ty.IsListType() || ty.IsSetType():
+		return "[]" + g.baseType(prefix, singular(name), ty.ElementType())
+	case ty.IsMapType():
+		return "map[string]" + g.baseType(prefix, singular(name), ty.ElementType())
+	case ty.IsObjectType():
+		return g.objectType(prefix+goName(name), name, ty)
+	default:
+		return "any"
+	}
+}
+
+func (g *generator) objectType(typeName, name string, ty cty.Type) string {
+	if g.seen[typeName] {
+		return typeName
+	}
+	g.seen[typeName] = true
+
+	attrs := make([]string, 0, len(ty.AttributeTypes()))
+	for a := range ty.AttributeTypes() {
+		attrs = append(attrs, a)
+	}
+	sort.Strings(attrs)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "\n// %s is the object type behind the %q variable.\n", typeName, name)
+	fmt.Fprintf(&b, "type %s struct {\n", typeName)
+	for _, a := range attrs {
+		optional := ty.AttributeOptional(a)
+		goType := g.goType(typeName, a, ty.AttributeType(a), optional)
+		tag := a
+		if !optional {
+			tag += ",required"
+		}
+		fmt.Fprintf(&b, "\t%s %s `tf:%q`\n", goName(a), goType, tag)
+	}
+	b.WriteString("}\n")
+	g.structs = append(g.structs, b.String())
+	return typeName
+}
+
+var sentenceEnd = regexp.MustCompile(`\.(\s|$)`)
+
+// writeDoc writes the first sentence of a description plus the default.
+func writeDoc(b *bytes.Buffer, indent, description string, required bool, def string) {
+	text := strings.Join(strings.Fields(description), " ")
+	if loc := sentenceEnd.FindStringIndex(text); loc != nil {
+		text = text[:loc[0]+1]
+	}
+	switch {
+	case required:
+		text = strings.TrimSpace(text + " Required.")
+	case def == "null":
+		text = strings.TrimSpace(text + " Default: null.")
+	default:
+		text = strings.TrimSpace(fmt.Sprintf("%s Default: %s.", text, def))
+	}
+	for _, line := range wrap(text, 76) {
+		fmt.Fprintf(b, "%s// %s\n", indent, line)
+	}
+}
+
+func wrap(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// goName converts snake_case to an exported Go identifier.
+func goName(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		if part == "" {
+			continue
+		}
+		if up, ok := initialisms[part]; ok {
+			b.WriteString(up)
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// singular names the element type of a collection variable.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
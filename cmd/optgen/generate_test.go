@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerate_UpToDate fails when a module's variables changed without
+// regenerating test/module_options_gen.go.
+func TestGenerate_UpToDate(t *testing.T) {
+	got, err := Generate("../../infra/modules", "test", map[string]string{
+		"neo4j_app/tests/e2e":   "NeoAppE2E",
+		"neo4j_app/tests/local": "NeoAppLocal",
+	})
+	require.NoError(t, err)
+
+	want, err := os.ReadFile("../../test/module_options_gen.go")
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got), "module_options_gen.go is stale; run go generate ./test/")
+}
+
+func TestGoName(t *testing.T) {
+	for in, want := range map[string]string{
+		"project_id":                "ProjectID",
+		"enable_cloud_nat":          "EnableCloudNAT",
+		"master_ipv4_cidr":          "MasterIPv4CIDR",
+		"neo4j_password_k8s_secret": "Neo4jPasswordK8sSecret",
+		"service_accounts":          "ServiceAccounts",
+	} {
+		require.Equal(t, want, goName(in), in)
+	}
+}
+
+func TestSingular(t *testing.T) {
+	for in, want := range map[string]string{
+		"service_accounts":           "service_account",
+		"master_authorized_networks": "master_authorized_network",
+		"policies":                   "policy",
+		"addresses":                  "address",
+		"access":                     "access",
+	} {
+		require.Equal(t, want, singular(in), in)
+	}
+}
@@ -0,0 +1,57 @@
+// Command optgen generates typed Terraform option builders for the modules in
+// infra/modules, one <Module>Options struct per module, by parsing each
+// module's variable blocks with HCL. Field names, Go types, required/optional
+// status and doc comments (description and default) follow the module, so a
+// renamed or retyped variable breaks the test build instead of the plan.
+//
+// Usage (from package test, via go generate):
+//
+//	go run ../cmd/optgen -modules ../infra/modules -out module_options_gen.go
+//
+// Nested wrapper modules (e.g. neo4j_app/tests/e2e) are added with
+// -extra dir=TypePrefix.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// extraFlag collects repeatable dir=TypePrefix pairs.
+type extraFlag map[string]string
+
+func (e extraFlag) String() string { return fmt.Sprint(map[string]string(e)) }
+
+func (e extraFlag) Set(v string) error {
+	dir, typeName, ok := strings.Cut(v, "=")
+	if !ok || dir == "" || typeName == "" {
+		return fmt.Errorf("want dir=TypePrefix, got %q", v)
+	}
+	e[dir] = typeName
+	return nil
+}
+
+func main() {
+	modules := flag.String("modules", "infra/modules", "directory containing one sub-directory per module")
+	out := flag.String("out", "", "output file (default: stdout)")
+	pkg := flag.String("package", "test", "package name of the generated file")
+	extra := extraFlag{}
+	flag.Var(extra, "extra", "additional module dir (relative to -modules) and Go type prefix, as dir=TypePrefix (repeatable)")
+	flag.Parse()
+
+	src, err := Generate(*modules, *pkg, extra)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "optgen:", err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		_, _ = os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "optgen:", err)
+		os.Exit(1)
+	}
+}
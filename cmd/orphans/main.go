@@ -0,0 +1,123 @@
+// Command orphans lists and replays the orphan ledger written by the Go test
+// suite when a cleanup destroy fails.
+//
+// DeferredTerraformCleanup retries transient destroy errors; when it still
+// fails it copies the workspace state and variables into the ledger (default
+// ~/.cache/neo4j-gke/orphans, override with NEO4J_GKE_ORPHAN_LEDGER_DIR or
+// -ledger). replay re-creates the module from this repository next to the
+// saved state and runs tofu destroy against it. Sensitive variables and
+// credentials are not saved; export them (TF_VAR_<name> for variables) before
+// replaying.
+//
+// Usage:
+//
+//	go run ./cmd/orphans list
+//	go run ./cmd/orphans replay -id <record-id>
+//	go run ./cmd/orphans replay -all [-dry-run]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/simon-lentz/neo4j_gke/test/ledger"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "orphans:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() error {
+	return errors.New("usage: orphans list|replay [flags]")
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return usage()
+	}
+	cmd, args := args[0], args[1:]
+
+	defaultDir, _ := ledger.DefaultDir()
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	dir := fs.String("ledger", defaultDir, "orphan ledger directory")
+	all := fs.Bool("all", false, "list resolved records too (list); replay every outstanding record (replay)")
+
+	switch cmd {
+	case "list":
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		l := ledger.Ledger{Dir: *dir}
+		records, err := l.Load()
+		if err != nil {
+			return err
+		}
+		return List(os.Stdout, records, *all)
+
+	case "replay":
+		id := fs.String("id", "", "record ID to replay")
+		repo := fs.String("repo", "", "repository root providing the module sources (default: search upwards for .git)")
+		binary := fs.String("binary", "tofu", "OpenTofu/Terraform binary")
+		dryRun := fs.Bool("dry-run", false, "run plan -destroy instead of destroy")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if (*id == "") == !*all {
+			return errors.New("replay needs exactly one of -id or -all")
+		}
+		root := *repo
+		if root == "" {
+			var err error
+			if root, err = findRepoRoot(); err != nil {
+				return err
+			}
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		r := Replayer{
+			Ledger:   ledger.Ledger{Dir: *dir},
+			RepoRoot: root,
+			Runner:   execRunner{binary: *binary},
+			DryRun:   *dryRun,
+			Out:      os.Stdout,
+		}
+		if *all {
+			return r.ReplayAll(ctx)
+		}
+		return r.ReplayID(ctx, *id)
+
+	default:
+		return usage()
+	}
+}
+
+// findRepoRoot honours NEO4J_GKE_REPO_ROOT, then walks up to a .git directory.
+func findRepoRoot() (string, error) {
+	if root := os.Getenv("NEO4J_GKE_REPO_ROOT"); root != "" {
+		return root, nil
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("could not locate repository root; pass -repo or set NEO4J_GKE_REPO_ROOT")
+		}
+		dir = parent
+	}
+}
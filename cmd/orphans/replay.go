@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/simon-lentz/neo4j_gke/test/ledger"
+)
+
+// backendOverride forces local state in the replay copy, whatever backend the
+// module declares, so destroy uses the state saved in the ledger.
+const backendOverride = `terraform {
+  backend "local" {}
+}
+`
+
+// Runner executes the tofu binary in dir with extra environment variables.
+type Runner interface {
+	Run(ctx context.Context, dir string, env map[string]string, args ...string) error
+}
+
+// Replayer destroys ledger records from saved state.
+type Replayer struct {
+	Ledger   ledger.Ledger
+	RepoRoot string
+	Runner   Runner
+	// DryRun runs plan -destroy and leaves the record outstanding.
+	DryRun bool
+	Out    io.Writer
+	// Now defaults to time.Now.
+	Now func() time.Time
+	// LookupEnv defaults to os.LookupEnv.
+	LookupEnv func(string) (string, bool)
+}
+
+// ReplayID replays one outstanding record.
+func (r Replayer) ReplayID(ctx context.Context, id string) error {
+	records, err := r.Ledger.Outstanding()
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if rec.ID == id {
+			return r.Replay(ctx, rec)
+		}
+	}
+	return fmt.Errorf("no outstanding record %q in %s", id, r.Ledger.Dir)
+}
+
+// ReplayAll replays every outstanding record, newest first so dependants
+// recorded later (GKE) go before what they depend on (VPC). It continues past
+// failures and returns them joined.
+func (r Replayer) ReplayAll(ctx context.Context) error {
+	records, err := r.Ledger.Outstanding()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Fprintln(r.Out, "No outstanding orphans.")
+		return nil
+	}
+	var errs []error
+	for i := len(records) - 1; i >= 0; i-- {
+		if err := r.Replay(ctx, records[i]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", records[i].ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Replay copies infra/ from the repository into the record's entry directory,
+// restores the saved state next to the module and runs destroy. On success the
+// record is resolved. The values the ledger left out must be set in the
+// environment first.
+func (r Replayer) Replay(ctx context.Context, rec ledger.Record) error {
+	entry := r.Ledger.EntryDir(rec)
+	work := filepath.Join(entry, "work")
+	fmt.Fprintf(r.Out, "Replaying %s (%s, %d resource(s))...\n", rec.ID, rec.Module, len(rec.Addresses))
+
+	if missing := r.missingEnv(rec); len(missing) > 0 {
+		return fmt.Errorf("the ledger does not hold sensitive values; set %s (any value will do if the destroy does not read it)",
+			strings.Join(missing, ", "))
+	}
+
+	if err := os.RemoveAll(work); err != nil {
+		return err
+	}
+	if err := copyTree(filepath.Join(r.RepoRoot, "infra"), filepath.Join(work, "infra")); err != nil {
+		return fmt.Errorf("copy module sources: %w", err)
+	}
+	moduleDir := filepath.Join(work, filepath.FromSlash(rec.Module))
+	if _, err := os.Stat(moduleDir); err != nil {
+		return fmt.Errorf("module %s not found in %s: %w", rec.Module, r.RepoRoot, err)
+	}
+	if err := copyFile(filepath.Join(entry, ledger.StateFile), filepath.Join(moduleDir, ledger.StateFile)); err != nil {
+		return fmt.Errorf("restore state: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(moduleDir, "orphans_override.tf"), []byte(backendOverride), 0o644); err != nil {
+		return err
+	}
+
+	env, err := loadEnv(entry)
+	if err != nil {
+		return err
+	}
+	varArgs, err := varFileArgs(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Runner.Run(ctx, moduleDir, env, "init", "-input=false", "-no-color", "-reconfigure"); err != nil {
+		return err
+	}
+	args := append([]string{"destroy", "-auto-approve", "-input=false", "-no-color"}, varArgs...)
+	if r.DryRun {
+		args = append([]string{"plan", "-destroy", "-input=false", "-no-color"}, varArgs...)
+	}
+	if err := r.Runner.Run(ctx, moduleDir, env, args...); err != nil {
+		return err
+	}
+	if r.DryRun {
+		return nil
+	}
+
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+	if err := r.Ledger.Resolve(rec, now().UTC()); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.Out, "Destroyed %s\n", rec.ID)
+	return nil
+}
+
+// missingEnv returns the environment variables rec needs that are not set:
+// TF_VAR_<name> for its sensitive variables and its redacted variables.
+func (r Replayer) missingEnv(rec ledger.Record) []string {
+	lookup := os.LookupEnv
+	if r.LookupEnv != nil {
+		lookup = r.LookupEnv
+	}
+	var missing []string
+	for _, name := range rec.SensitiveVars {
+		if _, ok := lookup("TF_VAR_" + name); !ok {
+			missing = append(missing, "TF_VAR_"+name)
+		}
+	}
+	for _, name := range rec.RedactedEnvVars {
+		if _, ok := lookup(name); !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// List writes the ledger as a table; resolved records only with all.
+func List(w io.Writer, records []ledger.Record, all bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tMODULE\tRESOURCES\tRECORDED\tSTATUS")
+	for _, rec := range records {
+		status := "outstanding"
+		if rec.Resolved != nil {
+			if !all {
+				continue
+			}
+			status = "destroyed " + rec.Resolved.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", rec.ID, rec.Module, len(rec.Addresses), rec.Recorded.Format(time.RFC3339), status)
+	}
+	return tw.Flush()
+}
+
+// varFileArgs returns -var-file flags for the saved variables, in order.
+func varFileArgs(entry string) ([]string, error) {
+	args := []string{"-var-file=" + filepath.Join(entry, ledger.VarFile)}
+	extra, err := filepath.Glob(filepath.Join(entry, "[0-9][0-9]-*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(extra)
+	for _, f := range extra {
+		args = append(args, "-var-file="+f)
+	}
+	return args, nil
+}
+
+// loadEnv reads the environment the original destroy ran with, if any.
+func loadEnv(entry string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(entry, "env.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var env map[string]string
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("decode env.json: %w", err)
+	}
+	return env, nil
+}
+
+// copyTree copies src to dst, skipping provider caches and state files.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			if d.Name() == ".terraform" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0o755)
+		}
+		if strings.HasSuffix(d.Name(), ".tfstate") || strings.HasSuffix(d.Name(), ".tfstate.backup") {
+			return nil
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o644)
+}
+
+// execRunner runs the real binary, streaming its output.
+type execRunner struct {
+	binary string
+}
+
+// Run implements Runner.
+func (e execRunner) Run(ctx context.Context, dir string, env map[string]string, args ...string) error {
+	cmd := exec.CommandContext(ctx, e.binary, args...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	var stderr bytes.Buffer
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s: %w: %s", e.binary, args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
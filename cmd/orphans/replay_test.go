@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+
+	testhelpers "github.com/simon-lentz/neo4j_gke/test"
+	"github.com/simon-lentz/neo4j_gke/test/ledger"
+)
+
+// fakeRunner records tofu invocations.
+type fakeRunner struct {
+	calls   []string
+	dirs    []string
+	failOn  string
+	sawFile []string
+}
+
+func (f *fakeRunner) Run(_ context.Context, dir string, _ map[string]string, args ...string) error {
+	f.calls = append(f.calls, strings.Join(args, " "))
+	f.dirs = append(f.dirs, dir)
+	for _, name := range []string{ledger.StateFile, "orphans_override.tf", "main.tf"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			f.sawFile = append(f.sawFile, name)
+		}
+	}
+	if f.failOn != "" && args[0] == f.failOn {
+		return errors.New("tofu " + f.failOn + " failed")
+	}
+	return nil
+}
+
+var recordedAt = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// setup creates a repo with one module and a ledger with one orphan of it.
+func setup(t *testing.T) (repo string, l ledger.Ledger, rec ledger.Record) {
+	repo = t.TempDir()
+	moduleDir := filepath.Join(repo, "infra", "modules", "vpc")
+	require.NoError(t, os.MkdirAll(filepath.Join(moduleDir, ".terraform"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf"), []byte("# vpc\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, ".terraform", "junk"), []byte("x"), 0o644))
+
+	l = ledger.Ledger{Dir: t.TempDir()}
+	rec = ledger.Record{ID: "run-TestVPC-vpc", Module: "infra/modules/vpc", Dir: "run-TestVPC-vpc",
+		Recorded: recordedAt, Addresses: []string{"google_compute_network.vpc"}}
+	entry, err := l.NewEntry(rec.ID)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(entry, ledger.StateFile), []byte(`{"version":4}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(entry, ledger.VarFile), []byte(`{"project_id":"p"}`), 0o600))
+	require.NoError(t, l.Append(rec))
+	return repo, l, rec
+}
+
+func TestReplay_DestroysAndResolves(t *testing.T) {
+	repo, l, rec := setup(t)
+	runner := &fakeRunner{}
+	var out bytes.Buffer
+
+	r := Replayer{Ledger: l, RepoRoot: repo, Runner: runner, Out: &out,
+		Now: func() time.Time { return recordedAt.Add(time.Hour) }}
+	require.NoError(t, r.ReplayID(context.Background(), rec.ID))
+
+	require.Len(t, runner.calls, 2)
+	require.True(t, strings.HasPrefix(runner.calls[0], "init "))
+	require.Contains(t, runner.calls[1], "destroy -auto-approve")
+	require.Contains(t, runner.calls[1], "-var-file="+filepath.Join(l.EntryDir(rec), ledger.VarFile))
+	require.Equal(t, filepath.Join(l.EntryDir(rec), "work", "infra", "modules", "vpc"), runner.dirs[1])
+	require.Contains(t, runner.sawFile, ledger.StateFile)
+	require.Contains(t, runner.sawFile, "orphans_override.tf")
+	require.NoDirExists(t, filepath.Join(runner.dirs[0], ".terraform"))
+
+	outstanding, err := l.Outstanding()
+	require.NoError(t, err)
+	require.Empty(t, outstanding)
+}
+
+func TestReplay_FailureLeavesRecordOutstanding(t *testing.T) {
+	repo, l, rec := setup(t)
+	runner := &fakeRunner{failOn: "destroy"}
+
+	r := Replayer{Ledger: l, RepoRoot: repo, Runner: runner, Out: &bytes.Buffer{}}
+	require.ErrorContains(t, r.ReplayAll(context.Background()), rec.ID)
+
+	outstanding, err := l.Outstanding()
+	require.NoError(t, err)
+	require.Len(t, outstanding, 1)
+}
+
+func TestReplay_DryRunPlansOnly(t *testing.T) {
+	repo, l, rec := setup(t)
+	runner := &fakeRunner{}
+
+	r := Replayer{Ledger: l, RepoRoot: repo, Runner: runner, DryRun: true, Out: &bytes.Buffer{}}
+	require.NoError(t, r.ReplayID(context.Background(), rec.ID))
+	require.True(t, strings.HasPrefix(runner.calls[1], "plan -destroy"))
+
+	outstanding, err := l.Outstanding()
+	require.NoError(t, err)
+	require.Len(t, outstanding, 1)
+}
+
+func TestReplay_RequiresRedactedValues(t *testing.T) {
+	repo, l, rec := setup(t)
+	rec.SensitiveVars = []string{"neo4j_password"}
+	rec.RedactedEnvVars = []string{"GOOGLE_OAUTH_ACCESS_TOKEN"}
+	env := map[string]string{}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	runner := &fakeRunner{}
+
+	r := Replayer{Ledger: l, RepoRoot: repo, Runner: runner, Out: &bytes.Buffer{}, LookupEnv: lookup}
+	err := r.Replay(context.Background(), rec)
+	require.ErrorContains(t, err, "TF_VAR_neo4j_password, GOOGLE_OAUTH_ACCESS_TOKEN")
+	require.Empty(t, runner.calls, "nothing runs until the values are supplied")
+
+	env["TF_VAR_neo4j_password"] = "x"
+	env["GOOGLE_OAUTH_ACCESS_TOKEN"] = "ya29.token"
+	require.NoError(t, r.Replay(context.Background(), rec))
+	require.Len(t, runner.calls, 2)
+}
+
+func TestList_HidesResolved(t *testing.T) {
+	resolved := recordedAt.Add(time.Hour)
+	records := []ledger.Record{
+		{ID: "a", Module: "infra/modules/vpc", Recorded: recordedAt, Resolved: &resolved},
+		{ID: "b", Module: "infra/modules/gke", Recorded: recordedAt, Addresses: []string{"x", "y"}},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, List(&out, records, false))
+	require.NotContains(t, out.String(), "infra/modules/vpc")
+	require.Contains(t, out.String(), "infra/modules/gke")
+
+	out.Reset()
+	require.NoError(t, List(&out, records, true))
+	require.Contains(t, out.String(), "destroyed")
+}
+
+// TestRecordOrphan_NothingToReplay checks that a failed destroy whose state
+// can't be read, or holds no managed resources, leaves neither an entry
+// directory nor a record for replay to act on.
+func TestRecordOrphan_NothingToReplay(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   string // written as terraform.tfstate when set
+		binary  string // stands in for tofu when there is no local state
+		wantErr string
+	}{
+		{name: "state pull fails", binary: "false", wantErr: "read state"},
+		{name: "state pull prints nothing", binary: "true", wantErr: "decode state"},
+		{name: "no managed resources", state: `{"version": 4, "resources": [{"mode": "data", "type": "google_project", "name": "p", "instances": [{}]}]}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ledgerDir := t.TempDir()
+			t.Setenv("NEO4J_GKE_ORPHAN_LEDGER_DIR", ledgerDir)
+
+			tfDir := filepath.Join(t.TempDir(), "infra", "modules", "vpc")
+			require.NoError(t, os.MkdirAll(tfDir, 0o755))
+			options := &terraform.Options{TerraformDir: tfDir, TerraformBinary: tc.binary}
+			if tc.state != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(tfDir, ledger.StateFile), []byte(tc.state), 0o644))
+			} else if _, err := exec.LookPath(tc.binary); err != nil {
+				t.Skipf("%s is not on PATH", tc.binary)
+			}
+
+			rec, err := testhelpers.RecordOrphan(t, options, errors.New("googleapi: Error 403: Permission denied"))
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Empty(t, rec.ID)
+
+			entries, err := os.ReadDir(ledgerDir)
+			require.NoError(t, err)
+			require.Empty(t, entries, "no entry directory or index is written")
+
+			runner := &fakeRunner{}
+			var out bytes.Buffer
+			r := Replayer{Ledger: ledger.Ledger{Dir: ledgerDir}, RepoRoot: t.TempDir(), Runner: runner, Out: &out}
+			require.NoError(t, r.ReplayAll(context.Background()))
+			require.Empty(t, runner.calls)
+			require.Contains(t, out.String(), "No outstanding orphans.")
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Command rotate rotates the Neo4j admin password of a neo4j_app deployment
+// without reinstalling it.
+//
+// It reads the current password from the latest version of the Secret
+// Manager secret, adds the new password as a new version, runs ALTER USER
+// against the running database and updates the Kubernetes secret holding
+// NEO4J_AUTH (the chart's <instance>-auth secret unless -k8s-secret names
+// the neo4j_password_k8s_secret one). Re-apply the environment afterwards so
+// the Helm release picks up the new version; that upgrades it in place.
+//
+// The database is reached over Bolt, e.g. through a port-forward:
+//
+//	kubectl port-forward -n neo4j svc/neo4j-dev 7687:7687
+//	openssl rand -base64 24 | go run ./cmd/rotate -project my-project-123
+//	cd infra/envs/dev && tofu apply ...
+//
+// The new password is read from stdin. The project defaults to
+// NEO4J_GKE_GCP_PROJECT_ID.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v6/neo4j"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/simon-lentz/neo4j_gke/internal/rotation"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "rotate:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	project := flag.String("project", os.Getenv("NEO4J_GKE_GCP_PROJECT_ID"), "GCP project holding the secret")
+	secret := flag.String("secret", "neo4j-admin-password-dev", "Secret Manager secret neo4j_app reads the password from")
+	bolt := flag.String("bolt", "bolt://localhost:7687", "Bolt URI of the running database")
+	user := flag.String("user", "neo4j", "Neo4j admin user")
+	namespace := flag.String("namespace", "neo4j", "Kubernetes namespace of the deployment")
+	instance := flag.String("instance", "neo4j-dev", "neo4j_instance_name of the deployment")
+	k8sSecret := flag.String("k8s-secret", "", "Kubernetes secret holding NEO4J_AUTH (default <instance>-auth)")
+	kubeContext := flag.String("context", "", "kubeconfig context (default: current context)")
+	flag.Parse()
+
+	if *project == "" {
+		return errors.New("-project or NEO4J_GKE_GCP_PROJECT_ID is required")
+	}
+	if *k8sSecret == "" {
+		*k8sSecret = *instance + "-auth"
+	}
+	raw, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("read new password: %w", err)
+	}
+	next := strings.TrimRight(string(raw), "\r\n")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	store := rotation.Gcloud{Project: *project}
+	current, err := store.Latest(ctx, *secret)
+	if err != nil {
+		return fmt.Errorf("read current password: %w", err)
+	}
+
+	driver, err := neo4j.NewDriver(*bolt, neo4j.BasicAuth(*user, string(current), ""))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = driver.Close(context.Background()) }()
+	if err := driver.VerifyConnectivity(ctx); err != nil {
+		return fmt.Errorf("connect to %s with the current password: %w", *bolt, err)
+	}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: *kubeContext},
+	).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("load kubeconfig: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	r := rotation.Rotator{
+		Secrets:    store,
+		SecretID:   *secret,
+		Database:   rotation.Neo4j{Driver: driver},
+		User:       *user,
+		KubeSecret: &rotation.KubeSecret{Client: client, Namespace: *namespace, Name: *k8sSecret},
+	}
+	res, err := r.Rotate(ctx, string(current), next)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("secret %s: new version %s\n", *secret, res.SecretVersion)
+	fmt.Printf("user %s: password changed\n", *user)
+	fmt.Printf("kubernetes secret %s: updated\n", res.KubeSecret)
+
+	if err := rotation.Verify(ctx, *bolt, *user, next); err != nil {
+		return fmt.Errorf("verify the new password: %w", err)
+	}
+	fmt.Println("new password verified; re-apply the environment so the Helm release uses the new secret version")
+	return nil
+}
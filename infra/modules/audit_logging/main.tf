@@ -3,6 +3,7 @@
 
 locals {
   logs_bucket_name = var.logs_bucket_name != null ? var.logs_bucket_name : "${var.project_id}-audit-logs"
+  log_sink_name    = var.log_sink_name != null ? var.log_sink_name : "${var.project_id}-audit-sink"
 }
 
 # GCS bucket for storing audit logs
@@ -91,7 +92,7 @@ locals {
 # Cloud Logging sink to route audit logs to GCS bucket
 resource "google_logging_project_sink" "audit_sink" {
   count       = var.enable_log_sink && length(local.sink_filter_services) > 0 ? 1 : 0
-  name        = "${var.project_id}-audit-sink"
+  name        = local.log_sink_name
   project     = var.project_id
   destination = "storage.googleapis.com/${google_storage_bucket.logs.name}"
 
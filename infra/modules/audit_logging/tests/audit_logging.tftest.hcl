@@ -61,6 +61,20 @@ run "plan_custom_bucket_name" {
   }
 }
 
+run "plan_custom_sink_name" {
+  command = plan
+
+  variables {
+    project_id    = "test-project"
+    log_sink_name = "my-custom-audit-sink"
+  }
+
+  assert {
+    condition     = google_logging_project_sink.audit_sink[0].name == "my-custom-audit-sink"
+    error_message = "Should use custom sink name."
+  }
+}
+
 run "plan_disabled_audit_logs" {
   command = plan
 
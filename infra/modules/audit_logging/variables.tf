@@ -49,6 +49,16 @@ variable "enable_log_sink" {
   default     = true
 }
 
+variable "log_sink_name" {
+  type        = string
+  description = "Custom name for the log sink. If null, auto-generated as {project_id}-audit-sink."
+  default     = null
+  validation {
+    condition     = var.log_sink_name == null || can(regex("^[A-Za-z0-9_.-]{1,100}$", var.log_sink_name))
+    error_message = "log_sink_name must be 1-100 characters of letters, digits, '_', '-' and '.'."
+  }
+}
+
 variable "log_retention_days" {
   type        = number
   description = "Number of days to retain logs in the bucket before deletion."
@@ -35,13 +35,14 @@ resource "kubernetes_namespace" "neo4j" {
 }
 
 # Kubernetes Service Account for backups with Workload Identity annotation
+# (omitted when enable_workload_identity is false)
 resource "kubernetes_service_account" "neo4j_backup" {
   metadata {
     name      = "neo4j-backup"
     namespace = kubernetes_namespace.neo4j.metadata[0].name
-    annotations = {
+    annotations = var.enable_workload_identity ? {
       "iam.gke.io/gcp-service-account" = var.backup_gsa_email
-    }
+    } : {}
     labels = {
       "app.kubernetes.io/name"       = "neo4j-backup"
       "app.kubernetes.io/managed-by" = "terraform"
@@ -51,6 +52,8 @@ resource "kubernetes_service_account" "neo4j_backup" {
 
 # Workload Identity binding: GSA -> KSA
 resource "google_service_account_iam_member" "backup_wi_binding" {
+  count = var.enable_workload_identity ? 1 : 0
+
   service_account_id = var.backup_gsa_name
   role               = "roles/iam.workloadIdentityUser"
   member             = "serviceAccount:${var.workload_identity_pool}[${kubernetes_namespace.neo4j.metadata[0].name}/${kubernetes_service_account.neo4j_backup.metadata[0].name}]"
@@ -267,6 +270,73 @@ resource "kubernetes_network_policy" "neo4j_to_backup" {
   }
 }
 
+# Allow backup pods to reach the Neo4j backup listener. neo4j-admin database
+# backup connects from the backup pod to the server on 6362, so the backup
+# pods need egress to Neo4j and Neo4j needs ingress from them.
+resource "kubernetes_network_policy" "backup_to_neo4j" {
+  metadata {
+    name      = "backup-to-neo4j-egress"
+    namespace = kubernetes_namespace.neo4j.metadata[0].name
+  }
+
+  spec {
+    pod_selector {
+      match_labels = {
+        "app.kubernetes.io/name" = var.backup_pod_label
+      }
+    }
+
+    egress {
+      ports {
+        port     = "6362"
+        protocol = "TCP"
+      }
+      to {
+        pod_selector {
+          match_labels = {
+            # Neo4j Helm chart uses "app" label
+            "app" = var.neo4j_instance_name
+          }
+        }
+      }
+    }
+
+    policy_types = ["Egress"]
+  }
+}
+
+resource "kubernetes_network_policy" "neo4j_backup_ingress" {
+  metadata {
+    name      = "allow-backup-ingress"
+    namespace = kubernetes_namespace.neo4j.metadata[0].name
+  }
+
+  spec {
+    pod_selector {
+      match_labels = {
+        # Neo4j Helm chart uses "app" label
+        "app" = var.neo4j_instance_name
+      }
+    }
+
+    ingress {
+      ports {
+        port     = "6362"
+        protocol = "TCP"
+      }
+      from {
+        pod_selector {
+          match_labels = {
+            "app.kubernetes.io/name" = var.backup_pod_label
+          }
+        }
+      }
+    }
+
+    policy_types = ["Ingress"]
+  }
+}
+
 # Neo4j Helm release
 resource "helm_release" "neo4j" {
   name       = var.neo4j_instance_name
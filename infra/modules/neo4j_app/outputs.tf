@@ -10,9 +10,16 @@ output "neo4j_instance_name" {
   value       = var.neo4j_instance_name
 }
 
+output "neo4j_chart_version" {
+  description = "Version of the Neo4j Helm chart deployed."
+  value       = helm_release.neo4j.version
+}
+
+# The chart's default ClusterIP service. The <instance>-lb-neo4j service is
+# only rendered when services.neo4j.enabled, which main.tf turns off.
 output "neo4j_bolt_service" {
   description = "Kubernetes service name for Bolt protocol access."
-  value       = "${var.neo4j_instance_name}-lb-neo4j"
+  value       = var.neo4j_instance_name
 }
 
 output "neo4j_bolt_port" {
@@ -35,8 +42,8 @@ output "backup_bucket_url" {
 output "connection_info" {
   description = "Neo4j connection information."
   value = {
-    bolt_uri     = "bolt://${var.neo4j_instance_name}-lb-neo4j.${var.neo4j_namespace}.svc.cluster.local:7687"
-    http_uri     = "http://${var.neo4j_instance_name}-lb-neo4j.${var.neo4j_namespace}.svc.cluster.local:7474"
+    bolt_uri     = "bolt://${var.neo4j_instance_name}.${var.neo4j_namespace}.svc.cluster.local:7687"
+    http_uri     = "http://${var.neo4j_instance_name}.${var.neo4j_namespace}.svc.cluster.local:7474"
     username     = "neo4j"
     password_ref = var.neo4j_password_secret_id != null ? "Secret Manager: ${var.neo4j_password_secret_id}" : "Provided via variable"
   }
@@ -63,8 +70,18 @@ output "network_policy_neo4j_to_backup" {
   value       = kubernetes_network_policy.neo4j_to_backup.metadata[0].name
 }
 
+output "network_policy_backup_to_neo4j" {
+  description = "Name of the backup-to-neo4j egress network policy."
+  value       = kubernetes_network_policy.backup_to_neo4j.metadata[0].name
+}
+
+output "network_policy_backup_ingress" {
+  description = "Name of the policy admitting backup pods to the Neo4j backup port."
+  value       = kubernetes_network_policy.neo4j_backup_ingress.metadata[0].name
+}
+
 # Workload Identity binding member (for verification)
 output "wi_binding_member" {
-  description = "Workload Identity binding member string."
-  value       = "serviceAccount:${var.workload_identity_pool}[${kubernetes_namespace.neo4j.metadata[0].name}/${kubernetes_service_account.neo4j_backup.metadata[0].name}]"
+  description = "Workload Identity binding member string (null when enable_workload_identity is false)."
+  value       = one(google_service_account_iam_member.backup_wi_binding[*].member)
 }
@@ -14,6 +14,11 @@ module "neo4j_app" {
   neo4j_instance_name    = var.neo4j_instance_name
   backup_pod_label       = var.backup_pod_label
 
+  # NetworkPolicy inputs (exercised by the connectivity matrix)
+  enable_neo4j_browser       = var.enable_neo4j_browser
+  enable_external_access     = var.enable_external_access
+  allowed_ingress_namespaces = var.allowed_ingress_namespaces
+
   # Test environment settings
   environment = "test"
 }
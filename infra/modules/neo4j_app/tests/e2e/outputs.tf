@@ -56,6 +56,16 @@ output "network_policy_neo4j_to_backup" {
   value       = module.neo4j_app.network_policy_neo4j_to_backup
 }
 
+output "network_policy_backup_to_neo4j" {
+  description = "Name of the backup-to-neo4j egress network policy."
+  value       = module.neo4j_app.network_policy_backup_to_neo4j
+}
+
+output "network_policy_backup_ingress" {
+  description = "Name of the policy admitting backup pods to the Neo4j backup port."
+  value       = module.neo4j_app.network_policy_backup_ingress
+}
+
 output "wi_binding_member" {
   description = "Workload Identity binding member string."
   value       = module.neo4j_app.wi_binding_member
@@ -66,3 +66,21 @@ variable "backup_pod_label" {
   description = "Label value to identify backup pods for network policy."
   default     = "neo4j-backup"
 }
+
+variable "enable_neo4j_browser" {
+  type        = bool
+  description = "Enable HTTP for Neo4j Browser access (port 7474)."
+  default     = true
+}
+
+variable "enable_external_access" {
+  type        = bool
+  description = "Allow ingress from any IP on the Neo4j ports."
+  default     = false
+}
+
+variable "allowed_ingress_namespaces" {
+  type        = list(string)
+  description = "Additional namespaces allowed to access Neo4j."
+  default     = []
+}
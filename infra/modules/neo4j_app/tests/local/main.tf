@@ -0,0 +1,31 @@
+# Local Test Wrapper
+# Calls the neo4j_app module on a kind cluster, with the GCP-only pieces
+# switched off: no Workload Identity binding, and the password passed directly
+# so the Secret Manager data source is never read.
+
+module "neo4j_app" {
+  source = "../.."
+
+  # Stand-ins: with enable_workload_identity = false the module only uses
+  # these in outputs, and nothing is created in GCP.
+  project_id               = "local"
+  enable_workload_identity = false
+  workload_identity_pool   = "local.svc.id.goog"
+  backup_gsa_email         = "neo4j-backup@local.iam.gserviceaccount.com"
+  backup_gsa_name          = "projects/local/serviceAccounts/neo4j-backup@local.iam.gserviceaccount.com"
+  backup_bucket_url        = "gs://local-neo4j-backups"
+
+  neo4j_password      = var.neo4j_password
+  neo4j_namespace     = var.neo4j_namespace
+  neo4j_instance_name = var.neo4j_instance_name
+  neo4j_storage_size  = var.neo4j_storage_size
+  neo4j_chart_version = var.neo4j_chart_version
+  backup_pod_label    = var.backup_pod_label
+
+  # NetworkPolicy inputs (exercised by the connectivity matrix)
+  enable_neo4j_browser       = var.enable_neo4j_browser
+  allowed_ingress_namespaces = var.allowed_ingress_namespaces
+
+  # Test environment settings
+  environment = "test"
+}
@@ -0,0 +1,42 @@
+# Local Test Outputs
+# Pass through module outputs for test assertions
+
+output "namespace" {
+  description = "Kubernetes namespace where Neo4j is deployed."
+  value       = module.neo4j_app.namespace
+}
+
+output "neo4j_instance_name" {
+  description = "Name of the Neo4j instance."
+  value       = module.neo4j_app.neo4j_instance_name
+}
+
+output "neo4j_bolt_service" {
+  description = "Kubernetes service name for Bolt protocol access."
+  value       = module.neo4j_app.neo4j_bolt_service
+}
+
+output "neo4j_chart_version" {
+  description = "Version of the Neo4j Helm chart deployed."
+  value       = module.neo4j_app.neo4j_chart_version
+}
+
+output "backup_ksa_name" {
+  description = "Kubernetes service account for backups."
+  value       = module.neo4j_app.backup_ksa_name
+}
+
+output "network_policy_default_deny" {
+  description = "Name of the default-deny network policy."
+  value       = module.neo4j_app.network_policy_default_deny
+}
+
+output "network_policy_allow_neo4j" {
+  description = "Name of the allow-neo4j network policy."
+  value       = module.neo4j_app.network_policy_allow_neo4j
+}
+
+output "wi_binding_member" {
+  description = "Workload Identity binding member string (null on kind)."
+  value       = module.neo4j_app.wi_binding_member
+}
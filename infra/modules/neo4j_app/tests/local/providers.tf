@@ -0,0 +1,22 @@
+# Local Test Providers
+# Configures providers for a kind cluster from a kubeconfig
+
+# The module requires the google provider, but with Workload Identity and
+# Secret Manager switched off it makes no API calls. A static access token
+# stops the provider from looking for application default credentials.
+provider "google" {
+  project      = "local"
+  access_token = "local-no-gcp"
+}
+
+provider "kubernetes" {
+  config_path    = var.kubeconfig_path
+  config_context = var.kube_context
+}
+
+provider "helm" {
+  kubernetes {
+    config_path    = var.kubeconfig_path
+    config_context = var.kube_context
+  }
+}
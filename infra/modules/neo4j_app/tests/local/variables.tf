@@ -0,0 +1,62 @@
+# Local Test Variables
+# Accepts direct inputs for test isolation
+
+# Provider configuration
+variable "kubeconfig_path" {
+  type        = string
+  description = "Path to the kubeconfig of the kind cluster."
+}
+
+variable "kube_context" {
+  type        = string
+  description = "Kubeconfig context of the kind cluster (kind-<cluster name>)."
+}
+
+# Module inputs
+variable "neo4j_password" {
+  type        = string
+  description = "Neo4j admin password."
+  sensitive   = true
+}
+
+variable "neo4j_namespace" {
+  type        = string
+  description = "Kubernetes namespace for Neo4j deployment."
+  default     = "neo4j"
+}
+
+variable "neo4j_instance_name" {
+  type        = string
+  description = "Name for the Neo4j instance."
+  default     = "neo4j-local"
+}
+
+variable "neo4j_chart_version" {
+  type        = string
+  description = "Version of the Neo4j Helm chart to deploy (null for the module default)."
+  default     = null
+}
+
+variable "neo4j_storage_size" {
+  type        = string
+  description = "Storage size for Neo4j data volume."
+  default     = "2Gi"
+}
+
+variable "backup_pod_label" {
+  type        = string
+  description = "Label value to identify backup pods for network policy."
+  default     = "neo4j-backup"
+}
+
+variable "enable_neo4j_browser" {
+  type        = bool
+  description = "Enable HTTP for Neo4j Browser access (port 7474)."
+  default     = true
+}
+
+variable "allowed_ingress_namespaces" {
+  type        = list(string)
+  description = "Additional namespaces allowed to access Neo4j."
+  default     = []
+}
@@ -108,6 +108,36 @@ run "neo4j_to_backup_egress_policy" {
   }
 }
 
+# Test: Backup pods can reach the Neo4j backup listener (backup client -> server)
+run "backup_pods_reach_neo4j_backup_port" {
+  command = plan
+
+  assert {
+    condition     = kubernetes_network_policy.backup_to_neo4j.spec[0].pod_selector[0].match_labels["app.kubernetes.io/name"] == "neo4j-backup"
+    error_message = "Backup-to-neo4j policy should select backup pods"
+  }
+
+  assert {
+    condition     = kubernetes_network_policy.backup_to_neo4j.spec[0].egress[0].ports[0].port == "6362"
+    error_message = "Backup pods should be allowed egress on port 6362"
+  }
+
+  assert {
+    condition     = kubernetes_network_policy.backup_to_neo4j.spec[0].egress[0].to[0].pod_selector[0].match_labels["app"] == "neo4j-dev"
+    error_message = "Backup pod egress should target Neo4j pods"
+  }
+
+  assert {
+    condition     = kubernetes_network_policy.neo4j_backup_ingress.spec[0].ingress[0].ports[0].port == "6362"
+    error_message = "Neo4j pods should admit backup pods on port 6362"
+  }
+
+  assert {
+    condition     = kubernetes_network_policy.neo4j_backup_ingress.spec[0].ingress[0].from[0].pod_selector[0].match_labels["app.kubernetes.io/name"] == "neo4j-backup"
+    error_message = "Neo4j backup ingress should only admit backup pods"
+  }
+}
+
 # Test: Allow Neo4j policy includes Bolt port and correct pod selector
 run "neo4j_policy_allows_bolt" {
   command = plan
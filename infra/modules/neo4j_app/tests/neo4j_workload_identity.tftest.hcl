@@ -0,0 +1,63 @@
+# Neo4j Workload Identity Plan Tests
+#
+# These tests validate that the Workload Identity binding can be switched off
+# for clusters without GKE Workload Identity (the kind-based local tier).
+#
+# Run with: tofu test
+
+mock_provider "kubernetes" {}
+mock_provider "helm" {}
+mock_provider "google" {}
+
+variables {
+  project_id             = "test-project"
+  workload_identity_pool = "test-project.svc.id.goog"
+  backup_gsa_email       = "backup@test-project.iam.gserviceaccount.com"
+  backup_gsa_name        = "projects/test-project/serviceAccounts/backup@test-project.iam.gserviceaccount.com"
+  backup_bucket_url      = "gs://test-project-backup"
+  neo4j_password         = "test-password"
+}
+
+# Test: Workload Identity is bound by default
+run "workload_identity_bound_by_default" {
+  command = plan
+
+  assert {
+    condition     = length(google_service_account_iam_member.backup_wi_binding) == 1
+    error_message = "Workload Identity binding should be created by default"
+  }
+
+  assert {
+    condition     = kubernetes_service_account.neo4j_backup.metadata[0].annotations["iam.gke.io/gcp-service-account"] == "backup@test-project.iam.gserviceaccount.com"
+    error_message = "Backup KSA should be annotated with the backup GSA"
+  }
+
+  assert {
+    condition     = output.wi_binding_member == "serviceAccount:test-project.svc.id.goog[neo4j/neo4j-backup]"
+    error_message = "wi_binding_member should name the backup KSA in the Workload Identity pool"
+  }
+}
+
+# Test: Workload Identity can be switched off
+run "workload_identity_disabled" {
+  command = plan
+
+  variables {
+    enable_workload_identity = false
+  }
+
+  assert {
+    condition     = length(google_service_account_iam_member.backup_wi_binding) == 0
+    error_message = "Workload Identity binding should not be created when disabled"
+  }
+
+  assert {
+    condition     = !contains(keys(try(kubernetes_service_account.neo4j_backup.metadata[0].annotations, {})), "iam.gke.io/gcp-service-account")
+    error_message = "Backup KSA should not carry the GSA annotation when Workload Identity is disabled"
+  }
+
+  assert {
+    condition     = output.wi_binding_member == null
+    error_message = "wi_binding_member should be null when Workload Identity is disabled"
+  }
+}
@@ -33,6 +33,17 @@ variable "backup_bucket_url" {
   description = "GCS bucket URL for Neo4j backups."
 }
 
+variable "enable_workload_identity" {
+  type        = bool
+  description = <<-EOT
+    Bind the backup KSA to backup_gsa_name through Workload Identity.
+    Set to false on clusters without GKE Workload Identity (e.g. kind); the
+    backup KSA is then created without the GSA annotation, and
+    workload_identity_pool, backup_gsa_email and backup_gsa_name are unused.
+  EOT
+  default     = true
+}
+
 variable "neo4j_password_secret_id" {
   type        = string
   description = "Secret Manager secret ID for Neo4j password. Used when neo4j_password_k8s_secret is null."
@@ -53,8 +64,9 @@ variable "environment" {
 
 variable "neo4j_chart_version" {
   type        = string
-  description = "Version of the Neo4j Helm chart to deploy. Requires 2025.10+ for native Vector type."
+  description = "Version of the Neo4j Helm chart to deploy. Requires 2025.10+ for native Vector type. Null selects the default."
   default     = "2025.10.1"
+  nullable    = false
 }
 
 variable "neo4j_namespace" {
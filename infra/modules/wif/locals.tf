@@ -25,11 +25,12 @@ locals {
   ]))
 
   # refs: exact -> attribute.ref == "refs/heads/main"
-  #       prefix -> startsWith(attribute.ref, "refs/heads/release/")
+  #       prefix -> attribute.ref.startsWith("refs/heads/release/")
+  # startsWith is a member function in CEL; the global form does not compile.
   ref_terms = [
     for ref in var.allowed_refs :
     endswith(ref, "/*")
-    ? format("startsWith(attribute.ref, %q)", format("%s/", trimsuffix(ref, "/*")))
+    ? format("attribute.ref.startsWith(%q)", format("%s/", trimsuffix(ref, "/*")))
     : format("attribute.ref == %q", ref)
   ]
   ref_cond = length(local.ref_terms) == 0 ? null : format("(%s)", join(" || ", local.ref_terms))
@@ -64,10 +64,10 @@ run "plan_with_refs_and_aud" {
     error_message = "Exact ref equality must be present."
   }
   assert {
-    condition = can(regex("startsWith\\(attribute\\.ref, \"refs/heads/release/\"\\)",
+    condition = can(regex("attribute\\.ref\\.startsWith\\(\"refs/heads/release/\"\\)",
       try(google_iam_workload_identity_pool_provider.provider_protected[0].attribute_condition,
     google_iam_workload_identity_pool_provider.provider_unprotected[0].attribute_condition)))
-    error_message = "Wildcard prefix must use startsWith(attribute.ref, ...)."
+    error_message = "Wildcard prefix must use attribute.ref.startsWith(...)."
   }
 
   # Assert that OIDC.allowed_audiences was set (one value in this test)
@@ -0,0 +1,181 @@
+// Package rotation rotates the Neo4j admin password across the places it is
+// kept: the Secret Manager secret neo4j_app reads, the user in the running
+// database and the Kubernetes secret the pod starts from (see cmd/rotate).
+//
+// The new secret version is added first so that a failed ALTER USER can be
+// rolled back by re-adding the current password as the latest version. The
+// Kubernetes secret is updated last, as it is only read when a pod starts.
+// Neither step reinstalls the Helm release: re-applying neo4j_app afterwards
+// picks up the latest version and upgrades the release in place.
+package rotation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v6/neo4j"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// AuthKey is the Kubernetes secret key the Neo4j chart reads the admin
+// credentials from, as "<user>/<password>".
+const AuthKey = "NEO4J_AUTH"
+
+// SecretStore holds the password versions neo4j_app reads.
+type SecretStore interface {
+	// Latest returns the latest version's data.
+	Latest(ctx context.Context, secret string) ([]byte, error)
+	// AddVersion stores data as the new latest version and returns its ID.
+	AddVersion(ctx context.Context, secret string, data []byte) (string, error)
+}
+
+// Database changes a user's password on the running server.
+type Database interface {
+	SetPassword(ctx context.Context, user, password string) error
+}
+
+// Rotator rotates one password. Secrets and KubeSecret are optional; nil
+// skips that copy (e.g. when neo4j_app is given the password directly).
+type Rotator struct {
+	Secrets    SecretStore
+	SecretID   string
+	Database   Database
+	User       string
+	KubeSecret *KubeSecret
+}
+
+// Result reports what a rotation changed.
+type Result struct {
+	// SecretVersion is the Secret Manager version holding the new password.
+	SecretVersion string
+	// KubeSecret is the namespace/name of the updated Kubernetes secret.
+	KubeSecret string
+}
+
+// Rotate moves every copy of the password from current to next.
+func (r Rotator) Rotate(ctx context.Context, current, next string) (Result, error) {
+	var res Result
+	if next == "" || next == current {
+		return res, errors.New("the new password must be non-empty and differ from the current one")
+	}
+
+	if r.Secrets != nil {
+		version, err := r.Secrets.AddVersion(ctx, r.SecretID, []byte(next))
+		if err != nil {
+			return res, fmt.Errorf("add version to secret %s: %w", r.SecretID, err)
+		}
+		res.SecretVersion = version
+	}
+
+	if err := r.Database.SetPassword(ctx, r.User, next); err != nil {
+		err = fmt.Errorf("alter user %s: %w", r.User, err)
+		if r.Secrets != nil {
+			rollback, rbErr := r.Secrets.AddVersion(ctx, r.SecretID, []byte(current))
+			if rbErr != nil {
+				return res, fmt.Errorf("%w; rolling secret %s back also failed, so its latest version (%s) holds a password the database does not use: %v",
+					err, r.SecretID, res.SecretVersion, rbErr)
+			}
+			err = fmt.Errorf("%w; secret %s rolled back to the current password as version %s", err, r.SecretID, rollback)
+		}
+		return res, err
+	}
+
+	if r.KubeSecret != nil {
+		if err := r.KubeSecret.Update(ctx, r.User, next); err != nil {
+			return res, fmt.Errorf("the database and Secret Manager use the new password, but updating Kubernetes secret %s failed (re-run to retry): %w",
+				r.KubeSecret, err)
+		}
+		res.KubeSecret = r.KubeSecret.String()
+	}
+	return res, nil
+}
+
+// Neo4j is the Database of a driver authenticated as an admin.
+type Neo4j struct {
+	Driver neo4j.Driver
+}
+
+// SetPassword implements Database.
+func (n Neo4j) SetPassword(ctx context.Context, user, password string) error {
+	_, err := neo4j.ExecuteQuery(ctx, n.Driver,
+		"ALTER USER $user SET PASSWORD $password CHANGE NOT REQUIRED",
+		map[string]any{"user": user, "password": password},
+		neo4j.EagerResultTransformer, neo4j.ExecuteQueryWithDatabase("system"))
+	return err
+}
+
+// Verify reports whether user can authenticate with password at uri.
+func Verify(ctx context.Context, uri, user, password string) error {
+	driver, err := neo4j.NewDriver(uri, neo4j.BasicAuth(user, password, ""))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = driver.Close(context.Background()) }()
+	return driver.VerifyConnectivity(ctx)
+}
+
+// KubeSecret is the Kubernetes secret holding AuthKey: the chart's
+// <instance>-auth secret, or the neo4j_password_k8s_secret secret.
+type KubeSecret struct {
+	Client    kubernetes.Interface
+	Namespace string
+	Name      string
+}
+
+func (k *KubeSecret) String() string {
+	return k.Namespace + "/" + k.Name
+}
+
+// Update sets AuthKey to user/password, keeping the secret's other keys.
+func (k *KubeSecret) Update(ctx context.Context, user, password string) error {
+	secrets := k.Client.CoreV1().Secrets(k.Namespace)
+	secret, err := secrets.Get(ctx, k.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[AuthKey] = []byte(user + "/" + password)
+	delete(secret.StringData, AuthKey)
+	_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	return err
+}
+
+// Gcloud is a SecretStore backed by Secret Manager through the gcloud CLI.
+type Gcloud struct {
+	Project string
+}
+
+// Latest implements SecretStore.
+func (g Gcloud) Latest(ctx context.Context, secret string) ([]byte, error) {
+	return g.run(ctx, nil, "secrets", "versions", "access", "latest", "--secret", secret)
+}
+
+// AddVersion implements SecretStore. The data is passed on stdin so it never
+// appears in a process listing.
+func (g Gcloud) AddVersion(ctx context.Context, secret string, data []byte) (string, error) {
+	out, err := g.run(ctx, data, "secrets", "versions", "add", secret, "--data-file=-", "--format=value(name)")
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(string(out))
+	return name[strings.LastIndex(name, "/")+1:], nil
+}
+
+func (g Gcloud) run(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "gcloud", append([]string{"--project", g.Project, "--quiet"}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("gcloud %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,128 @@
+package rotation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// memoryStore is an in-memory SecretStore.
+type memoryStore struct {
+	versions map[string][][]byte
+	fail     error
+}
+
+func (m *memoryStore) Latest(_ context.Context, secret string) ([]byte, error) {
+	v := m.versions[secret]
+	if len(v) == 0 {
+		return nil, fmt.Errorf("secret %s has no versions", secret)
+	}
+	return v[len(v)-1], nil
+}
+
+func (m *memoryStore) AddVersion(_ context.Context, secret string, data []byte) (string, error) {
+	if m.fail != nil {
+		return "", m.fail
+	}
+	m.versions[secret] = append(m.versions[secret], data)
+	return fmt.Sprint(len(m.versions[secret])), nil
+}
+
+// fakeDatabase records password changes.
+type fakeDatabase struct {
+	passwords map[string]string
+	fail      error
+}
+
+func (f *fakeDatabase) SetPassword(_ context.Context, user, password string) error {
+	if f.fail != nil {
+		return f.fail
+	}
+	f.passwords[user] = password
+	return nil
+}
+
+func newRotator(t *testing.T) (Rotator, *memoryStore, *fakeDatabase, *fake.Clientset) {
+	t.Helper()
+	store := &memoryStore{versions: map[string][][]byte{"neo4j-pw": {[]byte("old")}}}
+	db := &fakeDatabase{passwords: map[string]string{"neo4j": "old"}}
+	client := fake.NewClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "neo4j-dev-auth", Namespace: "neo4j"},
+		Data:       map[string][]byte{AuthKey: []byte("neo4j/old"), "other": []byte("kept")},
+	})
+	return Rotator{
+		Secrets:    store,
+		SecretID:   "neo4j-pw",
+		Database:   db,
+		User:       "neo4j",
+		KubeSecret: &KubeSecret{Client: client, Namespace: "neo4j", Name: "neo4j-dev-auth"},
+	}, store, db, client
+}
+
+func authData(t *testing.T, client *fake.Clientset) map[string][]byte {
+	t.Helper()
+	secret, err := client.CoreV1().Secrets("neo4j").Get(context.Background(), "neo4j-dev-auth", metav1.GetOptions{})
+	require.NoError(t, err)
+	return secret.Data
+}
+
+func TestRotate(t *testing.T) {
+	r, store, db, client := newRotator(t)
+
+	res, err := r.Rotate(context.Background(), "old", "new")
+	require.NoError(t, err)
+	require.Equal(t, Result{SecretVersion: "2", KubeSecret: "neo4j/neo4j-dev-auth"}, res)
+
+	latest, err := store.Latest(context.Background(), "neo4j-pw")
+	require.NoError(t, err)
+	require.Equal(t, "new", string(latest))
+	require.Equal(t, "new", db.passwords["neo4j"])
+	require.Equal(t, map[string][]byte{AuthKey: []byte("neo4j/new"), "other": []byte("kept")}, authData(t, client))
+}
+
+func TestRotate_AlterFailureRollsBackSecret(t *testing.T) {
+	r, store, db, client := newRotator(t)
+	db.fail = errors.New("Neo.ClientError.Security.Forbidden")
+
+	_, err := r.Rotate(context.Background(), "old", "new")
+	require.ErrorContains(t, err, "Forbidden")
+	require.ErrorContains(t, err, "rolled back to the current password as version 3")
+
+	latest, err := store.Latest(context.Background(), "neo4j-pw")
+	require.NoError(t, err)
+	require.Equal(t, "old", string(latest), "latest version must match the database again")
+	require.Equal(t, []byte("neo4j/old"), authData(t, client)[AuthKey], "Kubernetes secret must be untouched")
+}
+
+func TestRotate_SecretFailureLeavesDatabase(t *testing.T) {
+	r, store, db, _ := newRotator(t)
+	store.fail = errors.New("PERMISSION_DENIED")
+
+	_, err := r.Rotate(context.Background(), "old", "new")
+	require.ErrorContains(t, err, "PERMISSION_DENIED")
+	require.Equal(t, "old", db.passwords["neo4j"])
+}
+
+func TestRotate_WithoutSecretManager(t *testing.T) {
+	r, _, db, client := newRotator(t)
+	r.Secrets = nil
+
+	res, err := r.Rotate(context.Background(), "old", "new")
+	require.NoError(t, err)
+	require.Empty(t, res.SecretVersion)
+	require.Equal(t, "new", db.passwords["neo4j"])
+	require.Equal(t, []byte("neo4j/new"), authData(t, client)[AuthKey])
+}
+
+func TestRotate_RejectsSamePassword(t *testing.T) {
+	r, _, db, _ := newRotator(t)
+	_, err := r.Rotate(context.Background(), "old", "old")
+	require.ErrorContains(t, err, "differ")
+	require.Equal(t, "old", db.passwords["neo4j"])
+}
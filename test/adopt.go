@@ -0,0 +1,231 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+// --- adopt or create ---
+
+// AdoptSpec describes a resource a test must adopt rather than create when it
+// already exists: one GCP will not delete (KMS key rings and keys) or keeps
+// soft-deleted, blocking its name (WIF pools and providers, for 30 days).
+//
+// ImportID and the Probe and Undelete arguments are text/template strings
+// expanded with the vars given to Adopt, e.g. "{{.ring}}"; "project" is
+// always set.
+type AdoptSpec struct {
+	// Address is the resource address in the root, e.g.
+	// google_kms_key_ring.state_ring.
+	Address string
+	// ImportID is the `tofu import` ID.
+	ImportID string
+	// Probe are gcloud arguments (after --project) that exit 0 when the
+	// resource exists. When the output is "DELETED" (probe with
+	// --format=value(state)) the resource is soft-deleted.
+	Probe []string
+	// Undelete are gcloud arguments that restore a soft-deleted resource so
+	// it can be imported. Without them a soft-deleted resource fails the
+	// test, as its name cannot be reused.
+	Undelete []string
+	// Release removes the resource from state at cleanup, whether it was
+	// adopted or created, so destroy leaves it in place.
+	Release bool
+}
+
+// AdoptState is what a probe found.
+type AdoptState int
+
+// Probe results.
+const (
+	AdoptAbsent AdoptState = iota
+	AdoptPresent
+	AdoptSoftDeleted
+)
+
+func (s AdoptState) String() string {
+	return [...]string{"absent", "present", "soft-deleted"}[s]
+}
+
+// BootstrapKMSAdoption adopts the bootstrap root's state key ring and key,
+// which GCP never deletes. Vars: location, ring, key.
+var BootstrapKMSAdoption = []AdoptSpec{
+	{
+		Address:  "google_kms_key_ring.state_ring",
+		ImportID: "projects/{{.project}}/locations/{{.location}}/keyRings/{{.ring}}",
+		Probe:    []string{"kms", "keyrings", "describe", "{{.ring}}", "--location", "{{.location}}"},
+		Release:  true,
+	},
+	{
+		Address:  "google_kms_crypto_key.state_key",
+		ImportID: "projects/{{.project}}/locations/{{.location}}/keyRings/{{.ring}}/cryptoKeys/{{.key}}",
+		Probe:    []string{"kms", "keys", "describe", "{{.key}}", "--keyring", "{{.ring}}", "--location", "{{.location}}"},
+		Release:  true,
+	},
+}
+
+// WIFAdoption adopts the wif module's unprotected pool and provider, restoring
+// them first if a previous run left them soft-deleted. Vars: pool, provider.
+var WIFAdoption = []AdoptSpec{
+	{
+		Address:  "google_iam_workload_identity_pool.pool_unprotected[0]",
+		ImportID: "projects/{{.project}}/locations/global/workloadIdentityPools/{{.pool}}",
+		Probe:    []string{"iam", "workload-identity-pools", "describe", "{{.pool}}", "--location", "global", "--format=value(state)"},
+		Undelete: []string{"iam", "workload-identity-pools", "undelete", "{{.pool}}", "--location", "global"},
+	},
+	{
+		Address:  "google_iam_workload_identity_pool_provider.provider_unprotected[0]",
+		ImportID: "projects/{{.project}}/locations/global/workloadIdentityPools/{{.pool}}/providers/{{.provider}}",
+		Probe: []string{"iam", "workload-identity-pools", "providers", "describe", "{{.provider}}",
+			"--workload-identity-pool", "{{.pool}}", "--location", "global", "--format=value(state)"},
+		Undelete: []string{"iam", "workload-identity-pools", "providers", "undelete", "{{.provider}}",
+			"--workload-identity-pool", "{{.pool}}", "--location", "global"},
+	},
+}
+
+// Adopt initializes tf, then imports each spec's resource that already exists
+// in project, restoring soft-deleted ones first, and returns the adopted
+// addresses. Resources already in state are left alone. Specs are handled in
+// order, so list parents first.
+//
+// It registers a cleanup that removes the Release specs from state. Call it
+// after registering the destroy cleanup (budget.Cleanup), so the release runs
+// first and destroy skips those resources.
+//
+// Usage:
+//
+//	budget.Cleanup("envs/bootstrap", tf)
+//	Adopt(t, tf, projectID, map[string]string{"location": loc, "ring": ring, "key": key}, BootstrapKMSAdoption)
+//	budget.Apply("envs/bootstrap", tf)
+func Adopt(t *testing.T, tf *terraform.Options, project string, vars map[string]string, specs []AdoptSpec) []string {
+	t.Helper()
+
+	terraform.Init(t, tf)
+	tfvarsPath := ensureTfVarsJSON(t, tf)
+
+	t.Cleanup(func() {
+		for i := len(specs) - 1; i >= 0; i-- {
+			if !specs[i].Release {
+				continue
+			}
+			if err := runTofuStateRmE(t, tf, specs[i].Address); err != nil {
+				t.Logf("cleanup: tofu state rm %s returned error (ignored): %v", specs[i].Address, err)
+			}
+		}
+	})
+
+	var adopted []string
+	for _, s := range specs {
+		if inState(t, tf, s.Address) {
+			continue
+		}
+		state, err := s.State(t, project, vars)
+		require.NoError(t, err)
+		switch state {
+		case AdoptAbsent:
+			continue
+		case AdoptSoftDeleted:
+			require.NotEmptyf(t, s.Undelete, "%s is soft-deleted and its name cannot be reused until it is purged", s.Address)
+			args, err := expandArgs(s.Undelete, project, vars)
+			require.NoError(t, err)
+			t.Logf("Restoring soft-deleted %s", s.Address)
+			runGCLOUDNoOut(t, project, args...)
+		}
+		id, err := expand(s.ImportID, project, vars)
+		require.NoError(t, err)
+		t.Logf("Adopting existing %s (%s)", s.Address, id)
+		runTofu(t, tf, tfvarsPath, "import", s.Address, id)
+		adopted = append(adopted, s.Address)
+	}
+	return adopted
+}
+
+// ensureTfVarsJSON writes tf.Vars next to the root as an auto-loaded tfvars
+// file, so import and targeted destroy see the same variables as apply.
+func ensureTfVarsJSON(t *testing.T, tf *terraform.Options) string {
+	t.Helper()
+	path := filepath.Join(tf.TerraformDir, "terratest.auto.tfvars.json")
+	data, err := json.MarshalIndent(tf.Vars, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+// State runs the spec's Probe. Only a NOT_FOUND failure means absent; any
+// other failure (auth, permissions, quota, a disabled API) is returned, as
+// skipping the import would make the apply fail later with a 409.
+func (s AdoptSpec) State(t *testing.T, project string, vars map[string]string) (AdoptState, error) {
+	t.Helper()
+	args, err := expandArgs(s.Probe, project, vars)
+	if err != nil {
+		return AdoptAbsent, err
+	}
+	out, stderr, err := shell.RunCommandAndGetStdOutErrE(t, shell.Command{
+		Command: "gcloud",
+		Args:    append([]string{"--project", project}, args...),
+	})
+	switch {
+	case err != nil && gcloudNotFound.MatchString(stderr):
+		return AdoptAbsent, nil
+	case err != nil:
+		return AdoptAbsent, fmt.Errorf("probe %s: %w: %s", s.Address, err, strings.TrimSpace(stderr))
+	case strings.TrimSpace(out) == "DELETED":
+		return AdoptSoftDeleted, nil
+	}
+	return AdoptPresent, nil
+}
+
+// gcloudNotFound matches gcloud's error output for a missing resource.
+var gcloudNotFound = regexp.MustCompile(`\bNOT_FOUND\b|HTTPError 404\b`)
+
+// inState reports whether addr is already in tf's state.
+func inState(t *testing.T, tf *terraform.Options, addr string) bool {
+	t.Helper()
+	out, err := shell.RunCommandAndGetStdOutE(t, shell.Command{
+		Command:    tf.TerraformBinary,
+		Args:       []string{"state", "list", addr},
+		WorkingDir: tf.TerraformDir,
+		Env:        tf.EnvVars,
+	})
+	return err == nil && strings.TrimSpace(out) != ""
+}
+
+func expandArgs(args []string, project string, vars map[string]string) ([]string, error) {
+	out := make([]string, len(args))
+	for i, a := range args {
+		v, err := expand(a, project, vars)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// expand fills a template from vars plus project. A missing var is an error
+// rather than an empty string, which would probe or import the wrong name.
+func expand(text, project string, vars map[string]string) (string, error) {
+	data := map[string]string{"project": project}
+	for k, v := range vars {
+		data[k] = v
+	}
+	tmpl, err := template.New("adopt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("expand %q: %w", text, err)
+	}
+	return b.String(), nil
+}
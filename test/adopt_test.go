@@ -0,0 +1,42 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdoptSpec_Templates(t *testing.T) {
+	vars := map[string]string{"location": "us-central1", "ring": "p-tfstate-ring", "key": "tfstate-key", "pool": "gha", "provider": "github"}
+
+	id, err := expand(BootstrapKMSAdoption[1].ImportID, "p", vars)
+	require.NoError(t, err)
+	require.Equal(t, "projects/p/locations/us-central1/keyRings/p-tfstate-ring/cryptoKeys/tfstate-key", id)
+
+	args, err := expandArgs(WIFAdoption[1].Undelete, "p", vars)
+	require.NoError(t, err)
+	require.Equal(t, []string{"iam", "workload-identity-pools", "providers", "undelete", "github",
+		"--workload-identity-pool", "gha", "--location", "global"}, args)
+
+	// A missing var must not expand to an empty name.
+	_, err = expand(WIFAdoption[0].ImportID, "p", map[string]string{"provider": "github"})
+	require.ErrorContains(t, err, "pool")
+}
+
+func TestAdoptSpecs_Tables(t *testing.T) {
+	for _, specs := range [][]AdoptSpec{BootstrapKMSAdoption, WIFAdoption} {
+		for _, s := range specs {
+			require.NotEmpty(t, s.Address)
+			require.NotEmpty(t, s.ImportID, s.Address)
+			require.NotEmpty(t, s.Probe, s.Address)
+		}
+	}
+	// KMS cannot be destroyed, so it is always released; WIF is destroyed
+	// (soft-deleted) with the test's other resources.
+	for _, s := range BootstrapKMSAdoption {
+		require.True(t, s.Release, s.Address)
+	}
+	for _, s := range WIFAdoption {
+		require.False(t, s.Release, s.Address)
+	}
+}
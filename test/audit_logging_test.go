@@ -2,45 +2,39 @@ package test
 
 import (
 	"fmt"
-	"strings"
 	"testing"
 
-	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/require"
 )
 
 func TestAuditLogging_CreateDescribeDestroy(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
+	t.Parallel()
 
-	RequireMinimumTimeout(t, DefaultTestTimeout)
+	budget := NewBudget(t, "audit_logging")
 
 	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
 
-	tfDir := CopyModuleToTemp(t, "audit_logging")
-	suffix := strings.ToLower(random.UniqueId())
-	bucketName := fmt.Sprintf("%s-audit-test-%s", projectID, suffix)
-
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    tfDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":            projectID,
-			"logs_bucket_name":      bucketName,
-			"logs_bucket_location":  "US",
-			"enable_kms_audit_logs": true,
-			"enable_gcs_audit_logs": true,
-			"log_retention_days":    30, // Short retention for tests
-			"labels": map[string]string{
-				"test": "true",
-			},
-		},
-		NoColor: true,
-	})
-
-	DeferredTerraformCleanup(t, tf)
-	terraform.InitAndApply(t, tf)
+	// The audit configs are project singletons.
+	AcquireLease(t, AuditConfigLeaseKey(projectID))
+
+	bucketName := UniqueName(t, NameBucket, fmt.Sprintf("%s-audit-test", projectID))
+	sinkName := UniqueName(t, NameLogSink, fmt.Sprintf("%s-audit-test-sink", projectID))
+
+	audit := &AuditLoggingOptions{
+		ProjectID:          projectID,
+		LogsBucketName:     Ptr(bucketName),
+		LogsBucketLocation: Ptr("US"),
+		LogSinkName:        Ptr(sinkName),
+		EnableKMSAuditLogs: Ptr(true),
+		EnableGCSAuditLogs: Ptr(true),
+		LogRetentionDays:   Ptr(30), // Short retention for tests
+		Labels:             map[string]string{"test": "true"},
+	}
+	tf := audit.TerraformOptions(t, CopyModuleToTemp(t, audit.Module()))
+
+	budget.Cleanup(audit.Module(), tf)
+	budget.Apply(audit.Module(), tf)
 
 	// Verify outputs
 	outputBucketName, err := terraform.OutputE(t, tf, "logs_bucket_name")
@@ -51,56 +45,49 @@ func TestAuditLogging_CreateDescribeDestroy(t *testing.T) {
 	require.NoError(t, err, "failed to get logs_bucket_url output")
 	require.Equal(t, fmt.Sprintf("gs://%s", bucketName), bucketURL)
 
-	// Verify bucket exists via gcloud
-	out := runGCLOUD(t, projectID, "storage", "buckets", "describe",
-		fmt.Sprintf("gs://%s", bucketName), "--format=value(name)")
-	require.Equal(t, bucketName, strings.TrimSpace(out))
-
-	// Verify UBLA is enabled
-	out = runGCLOUD(t, projectID, "storage", "buckets", "describe",
-		fmt.Sprintf("gs://%s", bucketName),
-		"--format=value(iamConfiguration.uniformBucketLevelAccess.enabled)")
-	requireGcloudBoolTrue(t, out)
+	outputSinkName, err := terraform.OutputE(t, tf, "log_sink_name")
+	require.NoError(t, err, "failed to get log_sink_name output")
+	require.Equal(t, sinkName, outputSinkName)
 
-	// Verify PAP is enforced
-	out = runGCLOUD(t, projectID, "storage", "buckets", "describe",
-		fmt.Sprintf("gs://%s", bucketName),
-		"--format=value(iamConfiguration.publicAccessPrevention)")
-	require.Equal(t, "enforced", strings.TrimSpace(strings.ToLower(out)))
+	// Verify the bucket exists in GCP, then the applied settings from state
+	require.Equal(t, bucketName, DescribeBucket(t, projectID, bucketName).Name)
+	bucket := StateBucket(t, StateJSON(t, tf), "google_storage_bucket.logs")
+	require.Equal(t, bucketName, bucket.Name)
+	require.True(t, bucket.UniformBucketLevelAccess, "expected UBLA to be enabled")
+	require.Equal(t, "enforced", bucket.PublicAccessPrevention)
 
 	// Verify labels
-	out = runGCLOUD(t, projectID, "storage", "buckets", "describe",
-		fmt.Sprintf("gs://%s", bucketName), "--format=value(labels.test)")
-	require.Equal(t, "true", strings.TrimSpace(out))
+	require.Equal(t, "true", bucket.Labels["test"])
+
+	// Verify the audit configs on the project policy
+	policy := GetProjectIAMPolicy(t, projectID)
+	require.ElementsMatch(t, []string{"DATA_READ", "DATA_WRITE"}, policy.AuditLogTypes("cloudkms.googleapis.com"))
+	require.ElementsMatch(t, []string{"DATA_READ", "DATA_WRITE"}, policy.AuditLogTypes("storage.googleapis.com"))
 }
 
 func TestAuditLogging_DisabledAuditConfigs(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
+	t.Parallel()
 
-	RequireMinimumTimeout(t, DefaultTestTimeout)
+	budget := NewBudget(t, "audit_logging")
 
 	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
 
-	tfDir := CopyModuleToTemp(t, "audit_logging")
-	suffix := strings.ToLower(random.UniqueId())
-	bucketName := fmt.Sprintf("%s-audit-disabled-%s", projectID, suffix)
-
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    tfDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":            projectID,
-			"logs_bucket_name":      bucketName,
-			"logs_bucket_location":  "US",
-			"enable_kms_audit_logs": false,
-			"enable_gcs_audit_logs": false,
-		},
-		NoColor: true,
-	})
-
-	DeferredTerraformCleanup(t, tf)
-	terraform.InitAndApply(t, tf)
+	// The audit configs are project singletons.
+	AcquireLease(t, AuditConfigLeaseKey(projectID))
+
+	bucketName := UniqueName(t, NameBucket, fmt.Sprintf("%s-audit-disabled", projectID))
+
+	audit := &AuditLoggingOptions{
+		ProjectID:          projectID,
+		LogsBucketName:     Ptr(bucketName),
+		LogsBucketLocation: Ptr("US"),
+		EnableKMSAuditLogs: Ptr(false),
+		EnableGCSAuditLogs: Ptr(false),
+	}
+	tf := audit.TerraformOptions(t, CopyModuleToTemp(t, audit.Module()))
+
+	budget.Cleanup(audit.Module(), tf)
+	budget.Apply(audit.Module(), tf)
 
 	// Verify bucket still created
 	outputBucketName, err := terraform.OutputE(t, tf, "logs_bucket_name")
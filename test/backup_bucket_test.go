@@ -5,173 +5,130 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/require"
 )
 
 func TestBackupBucket_CreateDescribeDestroy(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
-
-	// Validate timeout before creating resources
-	RequireMinimumTimeout(t, DefaultTestTimeout)
-
-	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
-
-	// First create a service account for the bucket IAM bindings
-	saDir := CopyModuleToTemp(t, "service_accounts")
-	suffix := strings.ToLower(random.UniqueId())
-
-	saTf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    saDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id": projectID,
-			"service_accounts": map[string]any{
-				fmt.Sprintf("backup-%s", suffix): map[string]any{
-					"description": "Test backup SA",
-				},
-			},
-			"prevent_destroy_service_accounts": false,
-		},
-		NoColor: true,
-	})
+	t.Parallel()
 
-	// Get SA email (known before creation)
-	saEmail := fmt.Sprintf("backup-%s@%s.iam.gserviceaccount.com", suffix, projectID)
-
-	// Create backup bucket config
-	bucketDir := CopyModuleToTemp(t, "backup_bucket")
-	bucketName := fmt.Sprintf("%s-backup-test-%s", projectID, suffix)
-
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    bucketDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":              projectID,
-			"bucket_name":             bucketName,
-			"location":                GetTestRegion(t),
-			"backup_sa_email":         saEmail,
-			"backup_retention_days":   30,
-			"backup_versions_to_keep": 5,
-			"force_destroy":           true,
-			"labels": map[string]string{
-				"test": "true",
-			},
-		},
-		NoColor: true,
-	})
+	ForEachRegion(t, func(t *testing.T, region TestRegion) {
+		budget := NewBudget(t, "service_accounts", "backup_bucket")
 
-	// Register cleanup for both resources BEFORE creating anything
-	// Order: bucket cleanup first, then SA (LIFO)
-	DeferredTerraformCleanup(t, saTf)
-	DeferredTerraformCleanup(t, tf)
-
-	terraform.InitAndApply(t, saTf)
-	terraform.InitAndApply(t, tf)
-
-	// Verify bucket was created
-	outputBucketName, err := terraform.OutputE(t, tf, "bucket_name")
-	require.NoError(t, err, "failed to get bucket_name output")
-	require.Equal(t, bucketName, outputBucketName)
-
-	bucketURL, err := terraform.OutputE(t, tf, "bucket_url")
-	require.NoError(t, err, "failed to get bucket_url output")
-	require.Equal(t, fmt.Sprintf("gs://%s", bucketName), bucketURL)
-
-	// Verify bucket exists via gcloud
-	out := runGCLOUD(t, projectID, "storage", "buckets", "describe", fmt.Sprintf("gs://%s", bucketName), "--format=value(name)")
-	require.Contains(t, out, bucketName)
-
-	// Verify UBLA is enabled
-	out = runGCLOUD(t, projectID, "storage", "buckets", "describe", fmt.Sprintf("gs://%s", bucketName),
-		"--format=value(uniform_bucket_level_access)")
-	requireGcloudBoolTrue(t, out)
-
-	// Verify PAP is enforced
-	out = runGCLOUD(t, projectID, "storage", "buckets", "describe", fmt.Sprintf("gs://%s", bucketName),
-		"--format=value(public_access_prevention)")
-	require.Equal(t, "enforced", strings.TrimSpace(strings.ToLower(out)))
-
-	// Verify versioning is enabled
-	out = runGCLOUD(t, projectID, "storage", "buckets", "describe", fmt.Sprintf("gs://%s", bucketName),
-		"--format=value(versioning_enabled)")
-	requireGcloudBoolTrue(t, out)
-
-	// Verify IAM bindings
-	out = runGCLOUD(t, projectID, "storage", "buckets", "get-iam-policy", fmt.Sprintf("gs://%s", bucketName), "--format=json")
-	require.Contains(t, out, saEmail)
-	require.Contains(t, out, "roles/storage.objectCreator")
-	require.Contains(t, out, "roles/storage.objectViewer")
-}
+		projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
 
-func TestBackupBucket_WithoutVersioning(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
-
-	// Validate timeout before creating resources
-	RequireMinimumTimeout(t, DefaultTestTimeout)
-
-	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
-
-	// Create a minimal service account
-	saDir := CopyModuleToTemp(t, "service_accounts")
-	suffix := strings.ToLower(random.UniqueId())
-
-	saTf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    saDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id": projectID,
-			"service_accounts": map[string]any{
-				fmt.Sprintf("bkp-%s", suffix): map[string]any{
-					"description": "Test backup SA no versioning",
-				},
-			},
-			"prevent_destroy_service_accounts": false,
-		},
-		NoColor: true,
-	})
+		// First create a service account for the bucket IAM bindings
+		saName := region.UniqueName(t, NameServiceAccount, "backup")
 
-	saEmail := fmt.Sprintf("bkp-%s@%s.iam.gserviceaccount.com", suffix, projectID)
-
-	// Create bucket without versioning
-	bucketDir := CopyModuleToTemp(t, "backup_bucket")
-	bucketName := fmt.Sprintf("%s-novers-%s", projectID, suffix)
-
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    bucketDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":        projectID,
-			"bucket_name":       bucketName,
-			"location":          GetTestRegion(t),
-			"backup_sa_email":   saEmail,
-			"enable_versioning": false,
-			"force_destroy":     true,
-		},
-		NoColor: true,
+		sa := &ServiceAccountsOptions{
+			ProjectID: projectID,
+			ServiceAccounts: map[string]ServiceAccountsServiceAccount{
+				saName: {Description: "Test backup SA"},
+			},
+			PreventDestroyServiceAccounts: Ptr(false),
+		}
+		saTf := sa.TerraformOptions(t, CopyModuleToTemp(t, sa.Module()))
+
+		// Get SA email (known before creation)
+		saEmail := fmt.Sprintf("%s@%s.iam.gserviceaccount.com", saName, projectID)
+
+		// Create backup bucket config
+		bucketName := region.UniqueName(t, NameBucket, fmt.Sprintf("%s-backup-test", projectID))
+
+		bb := &BackupBucketOptions{
+			ProjectID:            projectID,
+			BucketName:           bucketName,
+			Location:             region.Name,
+			BackupSAEmail:        saEmail,
+			BackupRetentionDays:  Ptr(30),
+			BackupVersionsToKeep: Ptr(5),
+			ForceDestroy:         Ptr(true),
+			Labels:               map[string]string{"test": "true"},
+		}
+		tf := bb.TerraformOptions(t, CopyModuleToTemp(t, bb.Module()))
+
+		// Register cleanup for both resources BEFORE creating anything
+		// Order: bucket cleanup first, then SA (LIFO)
+		budget.Cleanup(sa.Module(), saTf)
+		budget.Cleanup(bb.Module(), tf)
+
+		budget.Apply(sa.Module(), saTf)
+		budget.Apply(bb.Module(), tf)
+
+		// Verify bucket was created
+		outputBucketName, err := terraform.OutputE(t, tf, "bucket_name")
+		require.NoError(t, err, "failed to get bucket_name output")
+		require.Equal(t, bucketName, outputBucketName)
+
+		bucketURL, err := terraform.OutputE(t, tf, "bucket_url")
+		require.NoError(t, err, "failed to get bucket_url output")
+		require.Equal(t, fmt.Sprintf("gs://%s", bucketName), bucketURL)
+
+		// Verify the bucket exists in GCP, then the applied settings from state
+		require.Equal(t, bucketName, DescribeBucket(t, projectID, bucketName).Name)
+		bucket := StateBucket(t, StateJSON(t, tf), "google_storage_bucket.backups")
+		require.Equal(t, bucketName, bucket.Name)
+		require.True(t, bucket.UniformBucketLevelAccess, "expected UBLA to be enabled")
+		require.Equal(t, "enforced", bucket.PublicAccessPrevention)
+		require.True(t, bucket.VersioningEnabled, "expected versioning to be enabled")
+		require.Equal(t, strings.ToUpper(region.Name), bucket.Location)
+
+		// Verify IAM bindings as GCP enforces them
+		policy := GetBucketIAMPolicy(t, projectID, bucketName)
+		RequireIAMBinding(t, policy, "roles/storage.objectCreator", saEmail)
+		RequireIAMBinding(t, policy, "roles/storage.objectViewer", saEmail)
 	})
+}
 
-	// Register cleanup for both resources BEFORE creating anything
-	DeferredTerraformCleanup(t, saTf)
-	DeferredTerraformCleanup(t, tf)
+func TestBackupBucket_WithoutVersioning(t *testing.T) {
+	t.Parallel()
 
-	terraform.InitAndApply(t, saTf)
-	terraform.InitAndApply(t, tf)
+	ForEachRegion(t, func(t *testing.T, region TestRegion) {
+		budget := NewBudget(t, "service_accounts", "backup_bucket")
 
-	// Verify versioning is disabled
-	out := runGCLOUD(t, projectID, "storage", "buckets", "describe", fmt.Sprintf("gs://%s", bucketName),
-		"--format=value(versioning_enabled)")
-	requireGcloudBoolEquals(t, out, false)
+		projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
 
-	// Security features should still be enabled
-	out = runGCLOUD(t, projectID, "storage", "buckets", "describe", fmt.Sprintf("gs://%s", bucketName),
-		"--format=value(uniform_bucket_level_access)")
-	requireGcloudBoolTrue(t, out)
+		// Create a minimal service account
+		saName := region.UniqueName(t, NameServiceAccount, "bkp")
 
-	out = runGCLOUD(t, projectID, "storage", "buckets", "describe", fmt.Sprintf("gs://%s", bucketName),
-		"--format=value(public_access_prevention)")
-	require.Equal(t, "enforced", strings.TrimSpace(strings.ToLower(out)))
+		sa := &ServiceAccountsOptions{
+			ProjectID: projectID,
+			ServiceAccounts: map[string]ServiceAccountsServiceAccount{
+				saName: {Description: "Test backup SA no versioning"},
+			},
+			PreventDestroyServiceAccounts: Ptr(false),
+		}
+		saTf := sa.TerraformOptions(t, CopyModuleToTemp(t, sa.Module()))
+
+		saEmail := fmt.Sprintf("%s@%s.iam.gserviceaccount.com", saName, projectID)
+
+		// Create bucket without versioning
+		bucketName := region.UniqueName(t, NameBucket, fmt.Sprintf("%s-novers", projectID))
+
+		bb := &BackupBucketOptions{
+			ProjectID:        projectID,
+			BucketName:       bucketName,
+			Location:         region.Name,
+			BackupSAEmail:    saEmail,
+			EnableVersioning: Ptr(false),
+			ForceDestroy:     Ptr(true),
+		}
+		tf := bb.TerraformOptions(t, CopyModuleToTemp(t, bb.Module()))
+
+		// Register cleanup for both resources BEFORE creating anything
+		budget.Cleanup(sa.Module(), saTf)
+		budget.Cleanup(bb.Module(), tf)
+
+		budget.Apply(sa.Module(), saTf)
+		budget.Apply(bb.Module(), tf)
+
+		// Verify the bucket exists in GCP and versioning is disabled
+		require.Equal(t, bucketName, DescribeBucket(t, projectID, bucketName).Name)
+		bucket := StateBucket(t, StateJSON(t, tf), "google_storage_bucket.backups")
+		require.False(t, bucket.VersioningEnabled, "expected versioning to be disabled")
+
+		// Security features should still be enabled
+		require.True(t, bucket.UniformBucketLevelAccess, "expected UBLA to be enabled")
+		require.Equal(t, "enforced", bucket.PublicAccessPrevention)
+	})
 }
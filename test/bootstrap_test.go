@@ -1,7 +1,6 @@
 package test
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,18 +8,15 @@ import (
 	"testing"
 	"time"
 
-	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/require"
 )
 
 func TestBootstrapSmoke(t *testing.T) {
-	// Do not parallelize: we adopt a fixed KMS ring/key per project.
-	// t.Parallel()
+	t.Parallel()
 
-	// Validate timeout before creating resources
-	RequireMinimumTimeout(t, DefaultTestTimeout)
+	budget := NewBudget(t, "envs/bootstrap")
 
 	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
 	location := MustEnv(t, "NEO4J_GKE_STATE_BUCKET_LOCATION") // e.g., us-central1
@@ -28,16 +24,18 @@ func TestBootstrapSmoke(t *testing.T) {
 	// Work in a temp copy so state and .terraform are isolated per run.
 	tfDir := CopyEnvToTemp(t, "bootstrap")
 
-	unique := strings.ToLower(random.UniqueId())
-
 	// Stable KMS names (match module defaults) so we can adopt/import if they exist.
 	ringName := fmt.Sprintf("%s-tfstate-ring", projectID)
 	keyName := "tfstate-key"
 
+	// The ring/key are adopted into this test's state and untracked again in
+	// cleanup; hold the lease until then so no other test imports them meanwhile.
+	AcquireLease(t, KMSKeyRingLeaseKey(projectID, location, ringName))
+
 	// Ephemeral bucket
-	bucketName := fmt.Sprintf("%s-state-%s", projectID, unique)
+	bucketName := UniqueName(t, NameBucket, fmt.Sprintf("%s-state-test", projectID))
 
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
+	tf := NewTerraformOptions(t, &terraform.Options{
 		TerraformDir:    tfDir,
 		TerraformBinary: "tofu", // ensure OpenTofu binary
 		Vars: map[string]any{
@@ -62,50 +60,36 @@ func TestBootstrapSmoke(t *testing.T) {
 		},
 	})
 
-	// Custom cleanup: destroy only ephemeral resources, then untrack KMS in state.
+	// Custom cleanup: destroy only ephemeral resources (Adopt untracks KMS).
 	// Using t.Cleanup() for better cleanup guarantees.
+	budget.Reserve("envs/bootstrap")
 	t.Cleanup(func() {
 		cleanupEphemeral(t, tf, projectID, bucketName)
 	})
 
-	terraform.Init(t, tf)
-
-	// Ensure a tfvars JSON is present for non-interactive tofu commands (import/destroy).
-	tfvarsPath := ensureTfVarsJSON(t, tf)
-
-	// Adopt KMS resources (rings/keys are not deletable in GCP).
-	ringID := fmt.Sprintf("projects/%s/locations/%s/keyRings/%s", projectID, location, ringName)
-	if kmsKeyRingExists(t, projectID, location, ringName) {
-		tofuImport(t, tf, tfvarsPath, "google_kms_key_ring.state_ring", ringID)
-	}
-
-	keyID := fmt.Sprintf("%s/cryptoKeys/%s", ringID, keyName)
-	if kmsCryptoKeyExists(t, projectID, location, ringName, keyName) {
-		tofuImport(t, tf, tfvarsPath, "google_kms_crypto_key.state_key", keyID)
-	}
+	// Adopt KMS resources (rings/keys are not deletable in GCP); they are
+	// released from state before cleanupEphemeral destroys the rest.
+	Adopt(t, tf, projectID, map[string]string{"location": location, "ring": ringName, "key": keyName}, BootstrapKMSAdoption)
+	keyID := fmt.Sprintf("projects/%s/locations/%s/keyRings/%s/cryptoKeys/%s", projectID, location, ringName, keyName)
 
 	// Apply (terratest wrapper will pass Vars for us)
-	terraform.Apply(t, tf)
-
-	// We need the bucket URL in gs://bucket format for the describe command
-	bucketURL := fmt.Sprintf("gs://%s", bucketName)
+	budget.Apply("envs/bootstrap", tf)
 
 	// --- Assertions ---
+	// 2-4 and 7a check what was applied, from state; 1, 5, 6 and 7b check
+	// what GCP has.
 
 	// 1) Bucket exists
-	out := runGCLOUD(t, projectID, "storage", "buckets", "describe", bucketURL)
-	require.Contains(t, out, bucketName)
-
-	// 2) UBLA & PAP (use snake_case fields for gcloud storage)
-	out = runGCLOUD(t, projectID, "storage", "buckets", "describe", bucketURL, "--format=value(uniform_bucket_level_access)")
-	requireGcloudBoolTrue(t, out)
+	require.Equal(t, bucketName, DescribeBucket(t, projectID, bucketName).Name)
+	bucket := StateBucket(t, StateJSON(t, tf), "google_storage_bucket.state_bucket")
+	require.Equal(t, bucketName, bucket.Name)
 
-	out = runGCLOUD(t, projectID, "storage", "buckets", "describe", bucketURL, "--format=value(public_access_prevention)")
-	require.Equal(t, "enforced", strings.TrimSpace(strings.ToLower(out)))
+	// 2) UBLA & PAP
+	require.True(t, bucket.UniformBucketLevelAccess, "expected UBLA to be enabled")
+	require.Equal(t, "enforced", bucket.PublicAccessPrevention)
 
 	// 3) Versioning equals our configured value (we passed bucket_versioning=false)
-	out = runGCLOUD(t, projectID, "storage", "buckets", "describe", bucketURL, "--format=value(versioning_enabled)")
-	requireGcloudBoolEquals(t, out, false)
+	require.False(t, bucket.VersioningEnabled, "expected versioning to be disabled")
 
 	// 4) Default KMS key set and usable (we only need the field presence)
 	stateObj, outputErr := terraform.OutputE(t, tf, "state")
@@ -134,28 +118,16 @@ func TestBootstrapSmoke(t *testing.T) {
 	require.Error(t, err, "anonymous read unexpectedly succeeded; PAP should be enforced")
 
 	// 7a) Bucket default key is set to our key
-	out = runGCLOUD(t, projectID, "storage", "buckets", "describe", bucketURL, "--format=default(default_kms_key)")
-	require.Equal(t, fmt.Sprintf("default_kms_key: %s", keyID), strings.TrimSpace(out))
+	require.Equal(t, keyID, bucket.DefaultKMSKey)
 
 	// 7b) The uploaded object was encrypted with our CMEK
-	out = runGCLOUD(t, projectID, "storage", "objects", "describe", fmt.Sprintf("gs://%s/%s", bucketName, obj), "--format=default(kms_key)")
-	require.Contains(t, out, fmt.Sprintf("kms_key: %s", keyID), strings.TrimSpace(out))
+	require.Equal(t, keyID, DescribeObject(t, projectID, fmt.Sprintf("gs://%s/%s", bucketName, obj)).CryptoKey())
 
 	// Proactively delete the test object to keep the bucket empty.
 	runGCLOUDNoOut(t, projectID, "storage", "rm", "--quiet", fmt.Sprintf("gs://%s/%s", bucketName, obj))
 }
 
-// --- write a tfvars JSON alongside the temp module so tofu import/destroy have vars ---
-func ensureTfVarsJSON(t *testing.T, tf *terraform.Options) string {
-	path := filepath.Join(tf.TerraformDir, "terratest.auto.tfvars.json")
-	data, err := json.MarshalIndent(tf.Vars, "", "  ")
-	require.NoError(t, err)
-	err = os.WriteFile(path, data, 0600)
-	require.NoError(t, err)
-	return path
-}
-
-// --- cleanup: only ephemeral resources, then drop imported KMS from state ---
+// --- cleanup: only ephemeral resources ---
 func cleanupEphemeral(t *testing.T, tf *terraform.Options, projectID, bucketName string) {
 	tfvarsPath := filepath.Join(tf.TerraformDir, "terratest.auto.tfvars.json")
 
@@ -169,15 +141,6 @@ func cleanupEphemeral(t *testing.T, tf *terraform.Options, projectID, bucketName
 		t.Logf("cleanup: tofu destroy returned error (ignored): %v", err)
 	}
 
-	// Remove KMS from state so future runs don't try to delete/import incorrectly.
-	// (Skip var-file for 'state' sub-commands; they don't need config vars.)
-	if err := runTofuStateRmE(t, tf, "google_kms_crypto_key.state_key"); err != nil {
-		t.Logf("cleanup: tofu state rm (crypto key) returned error (ignored): %v", err)
-	}
-	if err := runTofuStateRmE(t, tf, "google_kms_key_ring.state_ring"); err != nil {
-		t.Logf("cleanup: tofu state rm (key ring) returned error (ignored): %v", err)
-	}
-
 	// Final safeguard: attempt to delete the bucket directly, ignoring errors if it
 	// already vanished or was never created.
 	if bucketName != "" {
@@ -186,27 +149,3 @@ func cleanupEphemeral(t *testing.T, tf *terraform.Options, projectID, bucketName
 		}
 	}
 }
-
-// --- tofu import wrapper (no terratest Import available in your version) ---
-func tofuImport(t *testing.T, tf *terraform.Options, tfvarsPath, addr, id string) {
-	runTofu(t, tf, tfvarsPath, "import", addr, id)
-}
-
-// --- existence checks: rely on exit code; no --format to avoid zsh quirks ---
-func kmsKeyRingExists(t *testing.T, project, location, ring string) bool {
-	cmd := shell.Command{
-		Command: "gcloud",
-		Args:    []string{"--project", project, "kms", "keyrings", "describe", ring, "--location", location},
-	}
-	_, err := shell.RunCommandAndGetStdOutE(t, cmd)
-	return err == nil
-}
-
-func kmsCryptoKeyExists(t *testing.T, project, location, ring, key string) bool {
-	cmd := shell.Command{
-		Command: "gcloud",
-		Args:    []string{"--project", project, "kms", "keys", "describe", key, "--keyring", ring, "--location", location},
-	}
-	_, err := shell.RunCommandAndGetStdOutE(t, cmd)
-	return err == nil
-}
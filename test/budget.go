@@ -0,0 +1,190 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+// ModuleEstimate is how long a module usually takes to apply and to destroy.
+type ModuleEstimate struct {
+	Apply   time.Duration
+	Destroy time.Duration
+}
+
+// ModuleEstimates are keyed by module path relative to infra/modules (as
+// returned by the generated Module methods); environments are keyed envs/<name>.
+// Every module a test applies must be listed here.
+var ModuleEstimates = map[string]ModuleEstimate{
+	"audit_logging":         {Apply: 3 * time.Minute, Destroy: 2 * time.Minute},
+	"backup_bucket":         {Apply: 2 * time.Minute, Destroy: 2 * time.Minute},
+	"gke":                   {Apply: 12 * time.Minute, Destroy: 6 * time.Minute},
+	"neo4j_app":             {Apply: 8 * time.Minute, Destroy: 4 * time.Minute},
+	"neo4j_app/tests/e2e":   {Apply: 8 * time.Minute, Destroy: 4 * time.Minute},
+	"neo4j_app/tests/local": {Apply: 6 * time.Minute, Destroy: 2 * time.Minute},
+	"secrets":               {Apply: 2 * time.Minute, Destroy: 1 * time.Minute},
+	"service_accounts":      {Apply: 1 * time.Minute, Destroy: 1 * time.Minute},
+	"vpc":                   {Apply: 4 * time.Minute, Destroy: 4 * time.Minute},
+	"wif":                   {Apply: 2 * time.Minute, Destroy: 2 * time.Minute},
+	"envs/bootstrap":        {Apply: 3 * time.Minute, Destroy: 2 * time.Minute},
+}
+
+// budgetSlack is kept free on top of the estimates for init, provider
+// downloads and test exit.
+var budgetSlack = 2 * time.Minute
+
+// Budget tracks the time left before the test deadline and how much of it is
+// reserved for destroying what has been registered so far. Apply refuses to
+// start a module that would eat into that reserve, and its cleanups (the
+// teardown) run with the time that is left: the test is skipped if nothing
+// has been applied yet, and fails otherwise.
+//
+// Usage:
+//
+//	budget := NewBudget(t, "vpc", "gke") // skip now if both cannot fit
+//	budget.Cleanup("vpc", vpcTf)        // DeferredTerraformCleanup + reserve
+//	budget.Apply("vpc", vpcTf)          // InitAndApply if time allows
+//
+// Estimates are scaled by NEO4J_GKE_BUDGET_SCALE (e.g. 1.5 for a slow region).
+// Without a -timeout (go test -timeout 0) every check passes.
+type Budget struct {
+	t        *testing.T
+	deadline time.Time // zero means no deadline
+	scale    float64
+
+	mu       sync.Mutex
+	reserved time.Duration
+	applied  bool
+}
+
+// NewBudget starts a budget from t.Deadline() and skips the test if applying
+// and destroying all of modules cannot fit before the deadline.
+func NewBudget(t *testing.T, modules ...string) *Budget {
+	t.Helper()
+
+	deadline, _ := t.Deadline()
+	b := newBudget(t, deadline)
+
+	var need time.Duration
+	for _, m := range modules {
+		est := b.Estimate(m)
+		need += est.Apply + est.Destroy
+	}
+	if !b.deadline.IsZero() && need+budgetSlack > time.Until(b.deadline) {
+		t.Skipf("Skipping: %s left before the test deadline, but %s needs about %s (+%s slack). "+
+			"Run with a larger -timeout, e.g. -timeout=%s",
+			time.Until(b.deadline).Round(time.Second), strings.Join(modules, ", "), need, budgetSlack,
+			(need + budgetSlack + 5*time.Minute).Round(5*time.Minute))
+	}
+	return b
+}
+
+func newBudget(t *testing.T, deadline time.Time) *Budget {
+	t.Helper()
+
+	scale := 1.0
+	if s := strings.TrimSpace(os.Getenv("NEO4J_GKE_BUDGET_SCALE")); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		require.NoErrorf(t, err, "NEO4J_GKE_BUDGET_SCALE must be a number, got %q", s)
+		require.Greater(t, v, 0.0, "NEO4J_GKE_BUDGET_SCALE must be positive")
+		scale = v
+	}
+	return &Budget{t: t, deadline: deadline, scale: scale}
+}
+
+// Estimate returns the scaled estimate for module, failing the test for an
+// undeclared module.
+func (b *Budget) Estimate(module string) ModuleEstimate {
+	b.t.Helper()
+
+	est, ok := ModuleEstimates[module]
+	require.Truef(b.t, ok, "no ModuleEstimates entry for module %q; declare one in budget.go", module)
+	return ModuleEstimate{
+		Apply:   time.Duration(float64(est.Apply) * b.scale),
+		Destroy: time.Duration(float64(est.Destroy) * b.scale),
+	}
+}
+
+// Reserve sets aside module's destroy time. Call it when the module's
+// destroy is registered by other means than Cleanup (custom or staged
+// teardown).
+func (b *Budget) Reserve(module string) {
+	b.t.Helper()
+
+	est := b.Estimate(module)
+	b.mu.Lock()
+	b.reserved += est.Destroy
+	b.mu.Unlock()
+}
+
+// Cleanup registers DeferredTerraformCleanup for options and reserves the
+// module's destroy time.
+func (b *Budget) Cleanup(module string, options *terraform.Options) {
+	b.t.Helper()
+
+	b.Reserve(module)
+	DeferredTerraformCleanup(b.t, options)
+}
+
+// Remaining returns the time left before the deadline minus the teardown
+// reserve and slack. It is negative when the reserve is already eaten into,
+// and a very large value when the test has no deadline.
+func (b *Budget) Remaining() time.Duration {
+	if b.deadline.IsZero() {
+		return time.Duration(1<<63 - 1)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Until(b.deadline) - b.reserved - budgetSlack
+}
+
+// MarkApplied records that the test has created resources, e.g. stages an
+// earlier run provisioned. Apply calls it.
+func (b *Budget) MarkApplied() {
+	b.mu.Lock()
+	b.applied = true
+	b.mu.Unlock()
+}
+
+// Check ends the rest of the test, leaving only its cleanups to run, if stage
+// needs more than the remaining budget. Before anything is applied the test is
+// skipped; after that it fails, as a run that stopped halfway is not a pass.
+func (b *Budget) Check(stage string, need time.Duration) {
+	b.t.Helper()
+
+	remaining := b.Remaining()
+	if need <= remaining {
+		return
+	}
+	b.t.Logf("BUDGET: %s needs about %s but only %s remains after reserving teardown; going straight to teardown",
+		stage, need, remaining.Round(time.Second))
+
+	b.mu.Lock()
+	applied := b.applied
+	b.mu.Unlock()
+	if applied {
+		b.t.Fatalf("Not enough time left for %s before the test deadline after resources were applied; "+
+			"run with a larger -timeout", stage)
+	}
+	b.t.Skipf("Skipping remaining stages: not enough time left for %s before the test deadline", stage)
+}
+
+// Apply runs terraform.InitAndApply for module if its apply estimate fits in
+// the remaining budget, and ends the test as Check does otherwise.
+func (b *Budget) Apply(module string, options *terraform.Options) {
+	b.t.Helper()
+
+	b.Check(fmt.Sprintf("apply of %s", module), b.Estimate(module).Apply)
+	// A failed apply can leave resources behind too.
+	b.MarkApplied()
+	start := time.Now()
+	terraform.InitAndApply(b.t, options)
+	b.t.Logf("Applied %s in %s (estimate %s)", module, time.Since(start).Round(time.Second), b.Estimate(module).Apply)
+}
@@ -0,0 +1,50 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudget_CheckSkipsWhenReserveWouldBeUsed(t *testing.T) {
+	var ran bool
+	t.Run("stage", func(t *testing.T) {
+		b := newBudget(t, time.Now().Add(10*time.Minute))
+		b.Reserve("gke") // 6m destroy + 2m slack leaves ~2m
+		b.Check("apply of vpc", b.Estimate("vpc").Apply)
+		ran = true
+	})
+	require.False(t, ran, "Check should have skipped the stage")
+}
+
+func TestBudget_CheckPassesWithTime(t *testing.T) {
+	b := newBudget(t, time.Now().Add(time.Hour))
+	b.Reserve("vpc")
+	b.Check("apply of gke", b.Estimate("gke").Apply)
+	require.InDelta(t, float64(time.Hour-4*time.Minute-budgetSlack), float64(b.Remaining()), float64(time.Minute))
+}
+
+func TestBudget_NoDeadline(t *testing.T) {
+	b := newBudget(t, time.Time{})
+	b.Reserve("gke")
+	b.Check("apply of gke", 24*time.Hour)
+}
+
+func TestBudget_Scale(t *testing.T) {
+	t.Setenv("NEO4J_GKE_BUDGET_SCALE", "1.5")
+	b := newBudget(t, time.Time{})
+	require.Equal(t, 18*time.Minute, b.Estimate("gke").Apply)
+}
+
+func TestModuleEstimates_CoverEveryModule(t *testing.T) {
+	entries, err := os.ReadDir(filepath.Join(RepoRoot(t), "infra", "modules"))
+	require.NoError(t, err)
+	for _, e := range entries {
+		if e.IsDir() {
+			require.Containsf(t, ModuleEstimates, e.Name(), "declare a ModuleEstimates entry for module %s", e.Name())
+		}
+	}
+}
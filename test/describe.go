@@ -0,0 +1,422 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Typed gcloud describers. Each fetches `--format=json` once and decodes it,
+// so assertions compare fields instead of scraping `--format=value(...)`
+// output. Booleans the API omits when false decode to false, which removes
+// the empty-string quirk handled by requireGcloudBoolEquals.
+
+// --- IAM policies ---
+
+// IAMPolicy is a decoded `get-iam-policy --format=json` response.
+type IAMPolicy struct {
+	Version  int          `json:"version"`
+	Etag     string       `json:"etag"`
+	Bindings []IAMBinding `json:"bindings"`
+	// AuditConfigs is only set on project policies.
+	AuditConfigs []IAMAuditConfig `json:"auditConfigs"`
+}
+
+// IAMBinding grants a role to a list of members, optionally under a condition.
+type IAMBinding struct {
+	Role      string        `json:"role"`
+	Members   []string      `json:"members"`
+	Condition *IAMCondition `json:"condition,omitempty"`
+}
+
+// IAMAuditConfig enables Data Access audit logs for a service.
+type IAMAuditConfig struct {
+	Service         string `json:"service"`
+	AuditLogConfigs []struct {
+		LogType         string   `json:"logType"`
+		ExemptedMembers []string `json:"exemptedMembers"`
+	} `json:"auditLogConfigs"`
+}
+
+// IAMCondition is an IAM condition expression.
+type IAMCondition struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Expression  string `json:"expression"`
+}
+
+// Members returns every member bound to role (across all bindings for it).
+func (p *IAMPolicy) Members(role string) []string {
+	var members []string
+	for _, b := range p.Bindings {
+		if b.Role == role {
+			members = append(members, b.Members...)
+		}
+	}
+	return members
+}
+
+// HasBinding reports whether member is bound to role. member may be a bare
+// service account email; it then matches "serviceAccount:<email>".
+func (p *IAMPolicy) HasBinding(role, member string) bool {
+	for _, m := range p.Members(role) {
+		if m == member || m == "serviceAccount:"+member {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditLogTypes returns the audit log types (e.g. DATA_READ) enabled for
+// service.
+func (p *IAMPolicy) AuditLogTypes(service string) []string {
+	var types []string
+	for _, c := range p.AuditConfigs {
+		if c.Service == service {
+			for _, l := range c.AuditLogConfigs {
+				types = append(types, l.LogType)
+			}
+		}
+	}
+	return types
+}
+
+// RequireIAMBinding asserts that policy binds member to role.
+func RequireIAMBinding(t *testing.T, policy *IAMPolicy, role, member string) {
+	t.Helper()
+	require.Truef(t, policy.HasBinding(role, member),
+		"expected %s to be bound to %s; members of that role: %v", member, role, policy.Members(role))
+}
+
+// GetBucketIAMPolicy returns the IAM policy of a bucket (name or gs:// URL).
+func GetBucketIAMPolicy(t *testing.T, project, bucket string) *IAMPolicy {
+	t.Helper()
+	var policy IAMPolicy
+	describeJSON(t, project, &policy, "storage", "buckets", "get-iam-policy", gsURL(bucket))
+	return &policy
+}
+
+// GetSecretIAMPolicy returns the IAM policy of a Secret Manager secret.
+func GetSecretIAMPolicy(t *testing.T, project, secretID string) *IAMPolicy {
+	t.Helper()
+	var policy IAMPolicy
+	describeJSON(t, project, &policy, "secrets", "get-iam-policy", secretID)
+	return &policy
+}
+
+// GetProjectIAMPolicy returns the project IAM policy, including the audit
+// configs the audit_logging module sets.
+func GetProjectIAMPolicy(t *testing.T, project string) *IAMPolicy {
+	t.Helper()
+	var policy IAMPolicy
+	describeJSON(t, project, &policy, "projects", "get-iam-policy", project)
+	return &policy
+}
+
+// --- GKE ---
+
+// Cluster is the subset of `gcloud container clusters describe` the tests use.
+type Cluster struct {
+	Name                 string            `json:"name"`
+	Location             string            `json:"location"`
+	Status               string            `json:"status"`
+	Network              string            `json:"network"`
+	Subnetwork           string            `json:"subnetwork"`
+	CurrentMasterVersion string            `json:"currentMasterVersion"`
+	ResourceLabels       map[string]string `json:"resourceLabels"`
+	Autopilot            struct {
+		Enabled bool `json:"enabled"`
+	} `json:"autopilot"`
+	PrivateClusterConfig struct {
+		EnablePrivateNodes    bool `json:"enablePrivateNodes"`
+		EnablePrivateEndpoint bool `json:"enablePrivateEndpoint"`
+	} `json:"privateClusterConfig"`
+	ReleaseChannel struct {
+		Channel string `json:"channel"`
+	} `json:"releaseChannel"`
+	WorkloadIdentityConfig struct {
+		WorkloadPool string `json:"workloadPool"`
+	} `json:"workloadIdentityConfig"`
+}
+
+// DescribeCluster describes a regional GKE cluster.
+func DescribeCluster(t *testing.T, project, region, name string) *Cluster {
+	t.Helper()
+	var c Cluster
+	describeJSON(t, project, &c, "container", "clusters", "describe", name, "--region", region)
+	return &c
+}
+
+// --- Networking ---
+
+// Network is the subset of `gcloud compute networks describe` the tests use.
+type Network struct {
+	Name                  string `json:"name"`
+	AutoCreateSubnetworks bool   `json:"autoCreateSubnetworks"`
+	RoutingConfig         struct {
+		RoutingMode string `json:"routingMode"`
+	} `json:"routingConfig"`
+	Subnetworks []string `json:"subnetworks"`
+}
+
+// DescribeNetwork describes a VPC network.
+func DescribeNetwork(t *testing.T, project, name string) *Network {
+	t.Helper()
+	var n Network
+	describeJSON(t, project, &n, "compute", "networks", "describe", name)
+	return &n
+}
+
+// Subnet is the subset of `gcloud compute networks subnets describe` the tests use.
+type Subnet struct {
+	Name                  string           `json:"name"`
+	Region                string           `json:"region"`
+	Network               string           `json:"network"`
+	IPCIDRRange           string           `json:"ipCidrRange"`
+	PrivateIPGoogleAccess bool             `json:"privateIpGoogleAccess"`
+	SecondaryIPRanges     []SecondaryRange `json:"secondaryIpRanges"`
+}
+
+// SecondaryRange is a named alias IP range on a subnet.
+type SecondaryRange struct {
+	RangeName   string `json:"rangeName"`
+	IPCIDRRange string `json:"ipCidrRange"`
+}
+
+// SecondaryRange returns the secondary range called name, if present.
+func (s *Subnet) SecondaryRange(name string) (SecondaryRange, bool) {
+	for _, r := range s.SecondaryIPRanges {
+		if r.RangeName == name {
+			return r, true
+		}
+	}
+	return SecondaryRange{}, false
+}
+
+// DescribeSubnet describes a regional subnet.
+func DescribeSubnet(t *testing.T, project, region, name string) *Subnet {
+	t.Helper()
+	var s Subnet
+	describeJSON(t, project, &s, "compute", "networks", "subnets", "describe", name, "--region", region)
+	return &s
+}
+
+// RouterNAT is the subset of `gcloud compute routers nats describe` the tests use.
+type RouterNAT struct {
+	Name                          string `json:"name"`
+	NatIPAllocateOption           string `json:"natIpAllocateOption"`
+	SourceSubnetworkIPRangesToNat string `json:"sourceSubnetworkIpRangesToNat"`
+}
+
+// DescribeRouterNAT describes a Cloud NAT config on a Cloud Router.
+func DescribeRouterNAT(t *testing.T, project, region, router, name string) *RouterNAT {
+	t.Helper()
+	var n RouterNAT
+	describeJSON(t, project, &n, "compute", "routers", "nats", "describe", name,
+		"--router", router, "--region", region)
+	return &n
+}
+
+// --- Cloud Storage ---
+
+// Bucket is a Cloud Storage bucket as reported by `gcloud storage buckets
+// describe`. Fields are normalized from either the gcloud storage shape
+// (snake_case) or the raw API shape (camelCase), whichever gcloud returned.
+// StateBucket fills it from applied state instead.
+type Bucket struct {
+	Name                     string
+	Location                 string
+	UniformBucketLevelAccess bool
+	PublicAccessPrevention   string
+	VersioningEnabled        bool
+	DefaultKMSKey            string
+	Labels                   map[string]string
+}
+
+// bucketJSON accepts both bucket JSON shapes emitted by gcloud.
+type bucketJSON struct {
+	Name     string            `json:"name"`
+	Location string            `json:"location"`
+	Labels   map[string]string `json:"labels"`
+
+	// gcloud storage shape
+	UniformBucketLevelAccess *bool  `json:"uniform_bucket_level_access"`
+	PublicAccessPrevention   string `json:"public_access_prevention"`
+	VersioningEnabled        *bool  `json:"versioning_enabled"`
+	DefaultKMSKey            string `json:"default_kms_key"`
+
+	// API shape
+	IAMConfiguration struct {
+		UniformBucketLevelAccess struct {
+			Enabled bool `json:"enabled"`
+		} `json:"uniformBucketLevelAccess"`
+		PublicAccessPrevention string `json:"publicAccessPrevention"`
+	} `json:"iamConfiguration"`
+	Versioning struct {
+		Enabled bool `json:"enabled"`
+	} `json:"versioning"`
+	Encryption struct {
+		DefaultKMSKeyName string `json:"defaultKmsKeyName"`
+	} `json:"encryption"`
+}
+
+// DescribeBucket describes a bucket (name or gs:// URL).
+func DescribeBucket(t *testing.T, project, bucket string) *Bucket {
+	t.Helper()
+	var raw bucketJSON
+	describeJSON(t, project, &raw, "storage", "buckets", "describe", gsURL(bucket))
+
+	b := &Bucket{
+		Name:                     raw.Name,
+		Location:                 raw.Location,
+		Labels:                   raw.Labels,
+		UniformBucketLevelAccess: raw.IAMConfiguration.UniformBucketLevelAccess.Enabled,
+		PublicAccessPrevention:   raw.IAMConfiguration.PublicAccessPrevention,
+		VersioningEnabled:        raw.Versioning.Enabled,
+		DefaultKMSKey:            raw.Encryption.DefaultKMSKeyName,
+	}
+	if raw.UniformBucketLevelAccess != nil {
+		b.UniformBucketLevelAccess = *raw.UniformBucketLevelAccess
+	}
+	if raw.PublicAccessPrevention != "" {
+		b.PublicAccessPrevention = raw.PublicAccessPrevention
+	}
+	if raw.VersioningEnabled != nil {
+		b.VersioningEnabled = *raw.VersioningEnabled
+	}
+	if raw.DefaultKMSKey != "" {
+		b.DefaultKMSKey = raw.DefaultKMSKey
+	}
+	b.PublicAccessPrevention = strings.ToLower(b.PublicAccessPrevention)
+	return b
+}
+
+// Object is a Cloud Storage object as reported by `gcloud storage objects
+// describe`, normalized from either JSON shape like Bucket.
+type Object struct {
+	Bucket string
+	Name   string
+	// KMSKey is the key version that encrypted the object; empty for
+	// Google-managed encryption.
+	KMSKey string
+}
+
+// CryptoKey returns KMSKey without its /cryptoKeyVersions/N suffix, for
+// comparison with a bucket's default key.
+func (o *Object) CryptoKey() string {
+	key, _, _ := strings.Cut(o.KMSKey, "/cryptoKeyVersions/")
+	return key
+}
+
+type objectJSON struct {
+	Bucket     string `json:"bucket"`
+	Name       string `json:"name"`
+	KMSKey     string `json:"kms_key"`
+	KMSKeyName string `json:"kmsKeyName"`
+}
+
+// DescribeObject describes an object by gs:// URL.
+func DescribeObject(t *testing.T, project, url string) *Object {
+	t.Helper()
+	var raw objectJSON
+	describeJSON(t, project, &raw, "storage", "objects", "describe", url)
+
+	o := &Object{Bucket: raw.Bucket, Name: raw.Name, KMSKey: raw.KMSKey}
+	if o.KMSKey == "" {
+		o.KMSKey = raw.KMSKeyName
+	}
+	return o
+}
+
+// ListObjects returns the gs:// URLs of every object under prefix (a gs://
+// URL), recursively.
+func ListObjects(t *testing.T, project, prefix string) []string {
+	t.Helper()
+	out := runGCLOUD(t, project, "storage", "ls", strings.TrimSuffix(gsURL(prefix), "/")+"/**")
+	var urls []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			urls = append(urls, line)
+		}
+	}
+	return urls
+}
+
+// --- Secret Manager ---
+
+// Secret is the subset of `gcloud secrets describe` the tests use.
+type Secret struct {
+	// Name is the full resource name, projects/<number>/secrets/<id>.
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels"`
+	CreateTime  string            `json:"createTime"`
+	Replication struct {
+		Automatic   *struct{} `json:"automatic,omitempty"`
+		UserManaged *struct {
+			Replicas []struct {
+				Location string `json:"location"`
+			} `json:"replicas"`
+		} `json:"userManaged,omitempty"`
+	} `json:"replication"`
+}
+
+// ID returns the short secret ID (the last segment of Name).
+func (s *Secret) ID() string {
+	return path.Base(s.Name)
+}
+
+// DescribeSecret describes a Secret Manager secret.
+func DescribeSecret(t *testing.T, project, secretID string) *Secret {
+	t.Helper()
+	var s Secret
+	describeJSON(t, project, &s, "secrets", "describe", secretID)
+	return &s
+}
+
+// --- Workload Identity Federation ---
+
+// WIFProvider is the subset of `gcloud iam workload-identity-pools providers
+// describe` the tests use.
+type WIFProvider struct {
+	Name               string            `json:"name"`
+	DisplayName        string            `json:"displayName"`
+	State              string            `json:"state"`
+	Disabled           bool              `json:"disabled"`
+	AttributeCondition string            `json:"attributeCondition"`
+	AttributeMapping   map[string]string `json:"attributeMapping"`
+	OIDC               struct {
+		IssuerURI        string   `json:"issuerUri"`
+		AllowedAudiences []string `json:"allowedAudiences"`
+	} `json:"oidc"`
+}
+
+// DescribeWIFProvider describes a provider by its full resource name
+// (projects/<n>/locations/global/workloadIdentityPools/<pool>/providers/<id>).
+func DescribeWIFProvider(t *testing.T, project, providerName string) *WIFProvider {
+	t.Helper()
+	var p WIFProvider
+	describeJSON(t, project, &p, "iam", "workload-identity-pools", "providers", "describe", providerName)
+	return &p
+}
+
+// --- helpers ---
+
+// describeJSON runs a gcloud command with --format=json and decodes stdout into v.
+func describeJSON(t *testing.T, project string, v any, args ...string) {
+	t.Helper()
+	out := runGCLOUD(t, project, append(args, "--format=json")...)
+	require.NoErrorf(t, json.Unmarshal([]byte(out), v),
+		"failed to decode gcloud %s output", strings.Join(args, " "))
+}
+
+// gsURL returns bucket as a gs:// URL.
+func gsURL(bucket string) string {
+	if strings.HasPrefix(bucket, "gs://") {
+		return bucket
+	}
+	return fmt.Sprintf("gs://%s", bucket)
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Offline tests for the typed describers, answered by the fake gcloud from
+// testdata/gcloud.
+
+func TestDescribeCluster_Offline(t *testing.T) {
+	UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+
+	cluster := DescribeCluster(t, offlineProject, "us-central1", "gke-test-offline")
+	require.Equal(t, "gke-test-offline", cluster.Name)
+	require.True(t, cluster.Autopilot.Enabled)
+	require.True(t, cluster.PrivateClusterConfig.EnablePrivateNodes)
+	// Omitted by the API, so false rather than gcloud's empty string.
+	require.False(t, cluster.PrivateClusterConfig.EnablePrivateEndpoint)
+	require.Equal(t, "REGULAR", cluster.ReleaseChannel.Channel)
+}
+
+func TestDescribeNetworkAndSubnet_Offline(t *testing.T) {
+	UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+
+	network := DescribeNetwork(t, offlineProject, "test-vpc-offline")
+	require.False(t, network.AutoCreateSubnetworks)
+
+	subnet := DescribeSubnet(t, offlineProject, "us-central1", "test-vpc-offline-subnet")
+	require.True(t, subnet.PrivateIPGoogleAccess)
+	pods, ok := subnet.SecondaryRange("pods")
+	require.True(t, ok)
+	require.Equal(t, "10.20.0.0/14", pods.IPCIDRRange)
+	_, ok = subnet.SecondaryRange("missing")
+	require.False(t, ok)
+}
+
+func TestDescribeBucket_OfflineBothShapes(t *testing.T) {
+	UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+
+	// gcloud storage (snake_case) shape
+	bucket := DescribeBucket(t, offlineProject, "offline-project-backup-test-offline")
+	require.Equal(t, "offline-project-backup-test-offline", bucket.Name)
+	require.True(t, bucket.UniformBucketLevelAccess)
+	require.Equal(t, "enforced", bucket.PublicAccessPrevention)
+	require.True(t, bucket.VersioningEnabled)
+	require.Contains(t, bucket.DefaultKMSKey, "/cryptoKeys/tfstate-key")
+	require.Equal(t, "true", bucket.Labels["test"])
+
+	// API (camelCase) shape, addressed by URL
+	audit := DescribeBucket(t, offlineProject, "gs://offline-project-audit-logs")
+	require.True(t, audit.UniformBucketLevelAccess)
+	require.Equal(t, "enforced", audit.PublicAccessPrevention)
+	require.False(t, audit.VersioningEnabled)
+	require.Empty(t, audit.DefaultKMSKey)
+}
+
+func TestDescribeObject_Offline(t *testing.T) {
+	UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+
+	urls := ListObjects(t, offlineProject, "gs://offline-project-backup-test-offline/neo4j/run1/")
+	require.Len(t, urls, 2)
+
+	bucket := DescribeBucket(t, offlineProject, "offline-project-backup-test-offline")
+	obj := DescribeObject(t, offlineProject, urls[0])
+	require.Equal(t, "neo4j/run1/neo4j-2025-06-01T12-00-00.backup", obj.Name)
+	require.True(t, strings.HasSuffix(obj.KMSKey, "/cryptoKeyVersions/3"))
+	require.Equal(t, bucket.DefaultKMSKey, obj.CryptoKey())
+
+	plain := DescribeObject(t, offlineProject, "gs://offline-project-audit-logs/2025/06/01/log.json")
+	require.Empty(t, plain.KMSKey)
+	require.Empty(t, plain.CryptoKey())
+}
+
+func TestDescribeSecret_Offline(t *testing.T) {
+	UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+
+	secret := DescribeSecret(t, offlineProject, "neo4j-admin-password-offline")
+	require.Equal(t, "neo4j-admin-password-offline", secret.ID())
+	require.Equal(t, "true", secret.Labels["test"])
+	require.NotNil(t, secret.Replication.Automatic)
+
+	policy := GetSecretIAMPolicy(t, offlineProject, "neo4j-admin-password-offline")
+	RequireIAMBinding(t, policy, "roles/secretmanager.secretAccessor", "neo4j-offline@offline-project.iam.gserviceaccount.com")
+	require.False(t, policy.HasBinding("roles/secretmanager.admin", "neo4j-offline@offline-project.iam.gserviceaccount.com"))
+}
+
+func TestGetBucketIAMPolicy_Offline(t *testing.T) {
+	UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+
+	policy := GetBucketIAMPolicy(t, offlineProject, "offline-project-backup-test-offline")
+	sa := "backup-offline@offline-project.iam.gserviceaccount.com"
+	RequireIAMBinding(t, policy, "roles/storage.objectCreator", sa)
+	RequireIAMBinding(t, policy, "roles/storage.objectViewer", "serviceAccount:"+sa)
+	require.Equal(t, []string{"serviceAccount:" + sa}, policy.Members("roles/storage.objectViewer"))
+	require.Empty(t, policy.Members("roles/storage.admin"))
+}
+
+func TestDescribeWIFProvider_Offline(t *testing.T) {
+	UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+
+	provider := DescribeWIFProvider(t, offlineProject,
+		"projects/123456789/locations/global/workloadIdentityPools/gha-offline/providers/github")
+	require.Equal(t, "ACTIVE", provider.State)
+	require.False(t, provider.Disabled)
+	require.Equal(t, "https://token.actions.githubusercontent.com", provider.OIDC.IssuerURI)
+	require.Contains(t, provider.AttributeCondition, `attribute.repository == "acme/example"`)
+	require.Equal(t, "assertion.sub", provider.AttributeMapping["google.subject"])
+}
+
+func TestGetProjectIAMPolicy_Offline(t *testing.T) {
+	UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+
+	policy := GetProjectIAMPolicy(t, offlineProject)
+	RequireIAMBinding(t, policy, "roles/owner", "user:owner@example.com")
+	require.Equal(t, []string{"DATA_READ", "DATA_WRITE"}, policy.AuditLogTypes("cloudkms.googleapis.com"))
+	require.Equal(t, []string{"DATA_READ", "DATA_WRITE"}, policy.AuditLogTypes("storage.googleapis.com"))
+	require.Empty(t, policy.AuditLogTypes("container.googleapis.com"))
+}
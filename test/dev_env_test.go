@@ -0,0 +1,89 @@
+package test
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+)
+
+// Addresses in the dev environment's plan.
+const (
+	devBackupBucket   = "module.backup_bucket.google_storage_bucket.backups"
+	devBackupSA       = `module.backup_sa.google_service_account.service_account_unprotected["neo4j-backup"]`
+	devBackupKMS      = "google_kms_crypto_key_iam_member.backup_sa_kms"
+	devNeo4jRelease   = "module.neo4j[0].helm_release.neo4j"
+	devNeo4jPassword  = "module.neo4j[0].data.google_secret_manager_secret_version.neo4j_password[0]"
+	devPasswordSecret = "neo4j-admin-password-dev"
+)
+
+// TestDevEnv_PlanWiring plans infra/envs/dev against the bootstrap state
+// fixture instead of the state bucket, with and without the Neo4j app. It
+// needs tofu but no GCP project: everything is planned for creation, and the
+// Secret Manager read waits for the secret. It runs in -short mode.
+func TestDevEnv_PlanWiring(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("tofu"); err != nil {
+		t.Skip("Skipping dev environment plan test: tofu is not on PATH")
+	}
+
+	for _, deploy := range []bool{false, true} {
+		t.Run(fmt.Sprintf("deploy_neo4j_app=%t", deploy), func(t *testing.T) {
+			t.Parallel()
+
+			dir := CopyEnvToTemp(t, "dev")
+			bootstrap := UseLocalBootstrapState(t, dir, BootstrapStateFixture)
+			tf := NewTerraformOptions(t, &terraform.Options{
+				TerraformDir:    dir,
+				TerraformBinary: "tofu",
+				Vars: map[string]any{
+					"project_id":       "plan-only-project",
+					"state_bucket":     "unused-with-local-bootstrap-state",
+					"deploy_neo4j_app": deploy,
+				},
+				NoColor: true,
+				EnvVars: map[string]string{"GOOGLE_OAUTH_ACCESS_TOKEN": "plan-only"},
+			})
+
+			requireDevWiring(t, PlanJSON(t, tf), bootstrap, deploy)
+		})
+	}
+}
+
+// requireDevWiring checks that the backup bucket and the backup SA's KMS grant
+// use the bootstrap key, and that neo4j_app reads the dev admin password
+// secret when it is deployed.
+func requireDevWiring(t *testing.T, plan *terraform.PlanStruct, bootstrap map[string]any, deploy bool) {
+	t.Helper()
+
+	kmsKey := bootstrap["kms_key_name"]
+	require.NotEmpty(t, kmsKey, "bootstrap state has no kms_key_name")
+	RequirePlannedAttribute(t, plan, devBackupBucket, "encryption.0.default_kms_key_name", kmsKey)
+	RequirePlannedAttribute(t, plan, devBackupKMS, "crypto_key_id", kmsKey)
+	RequirePlannedAttribute(t, plan, devBackupKMS, "role", "roles/cloudkms.cryptoKeyEncrypterDecrypter")
+
+	// The SA email is only known after apply, so check the member is built
+	// from it and that it is the backup SA.
+	RequirePlannedAttribute(t, plan, devBackupSA, "account_id", "neo4j-backup")
+	RequireConfigReference(t, plan, devBackupKMS, "member", `module.backup_sa.service_accounts["neo4j-backup"].email`)
+
+	if !deploy {
+		for _, addr := range plannedAddresses(plan) {
+			require.Falsef(t, strings.HasPrefix(addr, "module.neo4j["), "deploy_neo4j_app = false but the plan has %s", addr)
+		}
+		return
+	}
+
+	RequireResourceCreated(t, plan, devNeo4jRelease)
+	RequireConfigReference(t, plan, "module.neo4j", "neo4j_password_secret_id",
+		fmt.Sprintf("module.secrets.secret_ids[%q]", devPasswordSecret))
+	// The read is deferred until the secret exists, but its arguments are
+	// known at plan time.
+	RequireResourceActions(t, plan, devNeo4jPassword, tfjson.Actions{tfjson.ActionRead})
+	RequirePlannedAttribute(t, plan, devNeo4jPassword, "secret", devPasswordSecret)
+}
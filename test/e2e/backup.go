@@ -0,0 +1,180 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/neo4j/neo4j-go-driver/v6/neo4j"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	testhelpers "github.com/simon-lentz/neo4j_gke/test"
+)
+
+// Neo4jImage returns the enterprise server image for a Neo4j version, e.g. as
+// reported by KernelVersion. Online backup is an enterprise feature, and
+// neo4j-admin must match the server it backs up.
+func Neo4jImage(version string) string {
+	return fmt.Sprintf("neo4j:%s-enterprise", version)
+}
+
+// BackupArtifactSuffix is the extension neo4j-admin gives full backups.
+const BackupArtifactSuffix = ".backup"
+
+// BackupSpec describes a neo4j-admin database backup run in its own pod.
+type BackupSpec struct {
+	Namespace    string
+	InstanceName string
+	// ServiceAccount is the backup KSA (backup_ksa_name output); through
+	// Workload Identity it acts as the backup GSA when writing to GCS.
+	ServiceAccount string
+	// PodLabel is backup_pod_label, which the NetworkPolicies key on.
+	PodLabel string
+	Database string
+	// ToPath is the gs:// folder the artifact is written to.
+	ToPath string
+	// Image runs neo4j-admin; see Neo4jImage.
+	Image string
+}
+
+// From is the address of the instance's backup listener, via the admin
+// service the chart creates.
+func (s BackupSpec) From() string {
+	return fmt.Sprintf("%s-admin.%s.svc.cluster.local:6362", s.InstanceName, s.Namespace)
+}
+
+// Pod returns the run-to-completion backup pod.
+func (s BackupSpec) Pod(name string) *corev1.Pod {
+	uid := int64(7474)
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.Namespace,
+			Labels:    map[string]string{"app.kubernetes.io/name": s.PodLabel},
+		},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: s.ServiceAccount,
+			RestartPolicy:      corev1.RestartPolicyNever,
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot: testhelpers.Ptr(true),
+				RunAsUser:    &uid,
+				RunAsGroup:   &uid,
+			},
+			Containers: []corev1.Container{{
+				Name:  "backup",
+				Image: s.Image,
+				Env:   []corev1.EnvVar{{Name: "NEO4J_ACCEPT_LICENSE_AGREEMENT", Value: "yes"}},
+				Command: []string{
+					"neo4j-admin", "database", "backup",
+					"--from=" + s.From(),
+					"--to-path=" + s.ToPath,
+					"--type=FULL",
+					s.Database,
+				},
+			}},
+		},
+	}
+}
+
+// RunBackup starts the backup pod, waits for it to exit and fails the test
+// with its logs if it did not succeed. The pod is deleted at cleanup.
+func RunBackup(t *testing.T, w *Waiter, spec BackupSpec, timeout time.Duration) {
+	t.Helper()
+
+	pods := w.Client.CoreV1().Pods(spec.Namespace)
+	pod := spec.Pod(fmt.Sprintf("neo4j-backup-%d", time.Now().Unix()))
+	_, err := pods.Create(t.Context(), pod, metav1.CreateOptions{})
+	require.NoError(t, err, "failed to create backup pod")
+	t.Cleanup(func() {
+		if err := pods.Delete(context.Background(), pod.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			t.Logf("WARNING: failed to delete backup pod %s: %v", pod.Name, err)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), timeout)
+	defer cancel()
+	pw := *w
+	pw.Namespace = spec.Namespace
+	_, err = pw.ForPod(ctx, pod.Name, PodSucceeded)
+	logs := pw.containerLogs(context.Background(), pod.Name, "backup", false)
+	require.NoError(t, err, "backup failed; neo4j-admin output:\n%s", logs)
+	t.Logf("neo4j-admin backup output:\n%s", logs)
+}
+
+// BackupArtifacts filters object URLs down to backup artifacts.
+func BackupArtifacts(urls []string) []string {
+	var artifacts []string
+	for _, u := range urls {
+		if strings.HasSuffix(u, BackupArtifactSuffix) {
+			artifacts = append(artifacts, u)
+		}
+	}
+	return artifacts
+}
+
+// RestoreFromBucket copies a backup artifact from GCS into pod, restores it
+// as database with neo4j-admin and creates the database. It runs through
+// gcloud and kubectl on the test host, so the target instance needs no GCS
+// access of its own.
+func RestoreFromBucket(t *testing.T, project string, options *k8s.KubectlOptions, pod, artifactURL, database string, driver neo4j.Driver) {
+	t.Helper()
+
+	local := filepath.Join(t.TempDir(), path.Base(artifactURL))
+	require.NoError(t, shell.RunCommandE(t, shell.Command{
+		Command: "gcloud",
+		Args:    []string{"--project", project, "storage", "cp", artifactURL, local},
+	}), "failed to download %s", artifactURL)
+
+	remoteDir := "/tmp/restore"
+	remote := remoteDir + "/" + path.Base(artifactURL)
+	_, err := k8s.RunKubectlAndGetOutputE(t, options, "exec", pod, "--", "mkdir", "-p", remoteDir)
+	require.NoError(t, err)
+	_, err = k8s.RunKubectlAndGetOutputE(t, options, "cp", local, fmt.Sprintf("%s/%s:%s", options.Namespace, pod, remote))
+	require.NoError(t, err, "failed to copy the artifact into %s", pod)
+
+	out, err := k8s.RunKubectlAndGetOutputE(t, options, "exec", pod, "--",
+		"neo4j-admin", "database", "restore", "--from-path="+remote, database)
+	require.NoError(t, err, "neo4j-admin database restore failed:\n%s", out)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Minute)
+	defer cancel()
+	_, err = neo4j.ExecuteQuery(ctx, driver, fmt.Sprintf("CREATE DATABASE `%s` IF NOT EXISTS WAIT", database), nil,
+		neo4j.EagerResultTransformer, neo4j.ExecuteQueryWithDatabase("system"))
+	require.NoError(t, err, "failed to create restored database %s", database)
+}
+
+// SeedMarkers creates n :BackupMarker nodes tagged with run.
+func SeedMarkers(ctx context.Context, driver neo4j.Driver, run string, n int) error {
+	_, err := neo4j.ExecuteQuery(ctx, driver,
+		"UNWIND range(1, $n) AS i CREATE (:BackupMarker {run: $run, i: i})",
+		map[string]any{"run": run, "n": n}, neo4j.EagerResultTransformer)
+	return err
+}
+
+// CountMarkers counts the :BackupMarker nodes of run in database.
+func CountMarkers(ctx context.Context, driver neo4j.Driver, database, run string) (int64, error) {
+	result, err := neo4j.ExecuteQuery(ctx, driver,
+		"MATCH (m:BackupMarker {run: $run}) RETURN count(m) AS n",
+		map[string]any{"run": run}, neo4j.EagerResultTransformer, neo4j.ExecuteQueryWithDatabase(database))
+	if err != nil {
+		return 0, err
+	}
+	v, err := singleValue(result, "n")
+	if err != nil {
+		return 0, err
+	}
+	n, ok := v.(int64)
+	if !ok {
+		return 0, fmt.Errorf("count is %T, want int64", v)
+	}
+	return n, nil
+}
@@ -0,0 +1,43 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupSpec_Pod(t *testing.T) {
+	spec := BackupSpec{
+		Namespace:      "neo4j",
+		InstanceName:   "neo4j-abc",
+		ServiceAccount: "neo4j-backup",
+		PodLabel:       "neo4j-backup",
+		Database:       "neo4j",
+		ToPath:         "gs://bkp/e2e/run1",
+		Image:          Neo4jImage("2025.10.1"),
+	}
+	require.Equal(t, "neo4j-abc-admin.neo4j.svc.cluster.local:6362", spec.From())
+
+	pod := spec.Pod("neo4j-backup-1")
+	require.Equal(t, "neo4j", pod.Namespace)
+	require.Equal(t, "neo4j-backup", pod.Spec.ServiceAccountName, "backup must run as the Workload Identity KSA")
+	require.Equal(t, "neo4j-backup", pod.Labels["app.kubernetes.io/name"], "NetworkPolicies select on this label")
+	require.Equal(t, []string{
+		"neo4j-admin", "database", "backup",
+		"--from=neo4j-abc-admin.neo4j.svc.cluster.local:6362",
+		"--to-path=gs://bkp/e2e/run1",
+		"--type=FULL",
+		"neo4j",
+	}, pod.Spec.Containers[0].Command)
+	require.Equal(t, "neo4j:2025.10.1-enterprise", pod.Spec.Containers[0].Image)
+}
+
+func TestBackupArtifacts(t *testing.T) {
+	got := BackupArtifacts([]string{
+		"gs://bkp/e2e/run1/",
+		"gs://bkp/e2e/run1/neo4j-2026-10-16T10-00-00.backup",
+		"gs://bkp/e2e/run1/neo4j.inspect",
+	})
+	require.Equal(t, []string{"gs://bkp/e2e/run1/neo4j-2026-10-16T10-00-00.backup"}, got)
+	require.Empty(t, BackupArtifacts(nil))
+}
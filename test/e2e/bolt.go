@@ -0,0 +1,163 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/neo4j/neo4j-go-driver/v6/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+// BoltPort is the container port of the Bolt protocol.
+const BoltPort = 7687
+
+// Neo4jUser is the admin user the neo4j_app module sets the password for.
+const Neo4jUser = "neo4j"
+
+// OpenBolt port-forwards to the Bolt port of service (the neo4j_bolt_service
+// output) and returns a driver connected through the tunnel. The tunnel and
+// driver are closed when the test ends.
+func OpenBolt(t *testing.T, options *k8s.KubectlOptions, service, password string) neo4j.Driver {
+	t.Helper()
+
+	driver, err := OpenBoltE(t, options, service, password)
+	require.NoError(t, err)
+	return driver
+}
+
+// OpenBoltE is OpenBolt returning the handshake error (e.g. an authentication
+// failure) instead of failing the test.
+func OpenBoltE(t *testing.T, options *k8s.KubectlOptions, service, password string) (neo4j.Driver, error) {
+	t.Helper()
+
+	tunnel := k8s.NewTunnel(options, k8s.ResourceTypeService, service, 0, BoltPort)
+	require.NoError(t, tunnel.ForwardPortE(t), "failed to port-forward to service %s", service)
+	t.Cleanup(tunnel.Close)
+
+	driver, err := neo4j.NewDriver("bolt://"+tunnel.Endpoint(), neo4j.BasicAuth(Neo4jUser, password, ""))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = driver.Close(context.Background()) })
+
+	if err := driver.VerifyConnectivity(t.Context()); err != nil {
+		return nil, fmt.Errorf("bolt handshake through %s failed: %w", tunnel.Endpoint(), err)
+	}
+	return driver, nil
+}
+
+// CypherCheck is a query plus an assertion on its result.
+type CypherCheck struct {
+	Name   string
+	Query  string
+	Params map[string]any
+	Verify func(*neo4j.EagerResult) error
+}
+
+// Cypher25Checks are the checks the Makefile's neo4j-test target runs by
+// hand with cypher-shell. The queries carry no CYPHER prefix, so they also
+// prove the server defaults to Cypher 25.
+var Cypher25Checks = []CypherCheck{
+	{
+		Name:   "dbms.components",
+		Query:  "CALL dbms.components() YIELD name, versions, edition RETURN name, versions, edition",
+		Verify: verifyComponents,
+	},
+	{
+		Name:   "vector constructor",
+		Query:  "RETURN vector([1.0, 2.0, 3.0], 3, FLOAT32) AS vec",
+		Verify: verifyVector,
+	},
+	{
+		Name: "vector.similarity.cosine",
+		Query: "WITH vector([1.0, 0.0], 2, FLOAT32) AS v1, vector([1.0, 0.0], 2, FLOAT32) AS v2 " +
+			"RETURN vector.similarity.cosine(v1, v2) AS similarity",
+		Verify: verifyCosine,
+	},
+}
+
+// RunCypherChecks runs each check in order and returns the first failure.
+// Server errors are wrapped unchanged so the Neo4j code and message reach the
+// test output.
+func RunCypherChecks(ctx context.Context, driver neo4j.Driver, checks []CypherCheck) error {
+	for _, c := range checks {
+		result, err := neo4j.ExecuteQuery(ctx, driver, c.Query, c.Params, neo4j.EagerResultTransformer)
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.Name, err)
+		}
+		if err := c.Verify(result); err != nil {
+			return fmt.Errorf("%s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+func singleValue(result *neo4j.EagerResult, key string) (any, error) {
+	if len(result.Records) != 1 {
+		return nil, fmt.Errorf("expected 1 record, got %d", len(result.Records))
+	}
+	v, ok := result.Records[0].Get(key)
+	if !ok {
+		return nil, fmt.Errorf("record has no %q column (keys %v)", key, result.Keys)
+	}
+	return v, nil
+}
+
+func verifyComponents(result *neo4j.EagerResult) error {
+	for _, r := range result.Records {
+		name, _ := r.Get("name")
+		versions, _ := r.Get("versions")
+		if name == "Neo4j Kernel" {
+			if vs, ok := versions.([]any); !ok || len(vs) == 0 {
+				return fmt.Errorf("Neo4j Kernel reports no versions: %v", versions)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no Neo4j Kernel component in %d records", len(result.Records))
+}
+
+func verifyVector(result *neo4j.EagerResult) error {
+	v, err := singleValue(result, "vec")
+	if err != nil {
+		return err
+	}
+	vec, ok := v.(neo4j.Vector[float32])
+	if !ok {
+		return fmt.Errorf("vec is %T, want a FLOAT32 vector", v)
+	}
+	if want := []float32{1, 2, 3}; !slices.Equal(vec.Elems, want) {
+		return fmt.Errorf("vec = %v, want %v", vec.Elems, want)
+	}
+	return nil
+}
+
+func verifyCosine(result *neo4j.EagerResult) error {
+	v, err := singleValue(result, "similarity")
+	if err != nil {
+		return err
+	}
+	sim, ok := v.(float64)
+	if !ok {
+		return fmt.Errorf("similarity is %T, want a float", v)
+	}
+	if math.Abs(sim-1.0) > 1e-6 {
+		return fmt.Errorf("cosine similarity of identical vectors = %v, want 1", sim)
+	}
+	return nil
+}
+
+// VerifyNeo4jBolt connects to the Bolt service through a port-forward and
+// runs the Cypher 25 checks, failing with the server error if any fails.
+func VerifyNeo4jBolt(t *testing.T, options *k8s.KubectlOptions, boltService, password string) {
+	t.Helper()
+
+	driver := OpenBolt(t, options, boltService, password)
+	ctx, cancel := context.WithTimeout(t.Context(), 2*time.Minute)
+	defer cancel()
+	require.NoError(t, RunCypherChecks(ctx, driver, Cypher25Checks))
+	t.Logf("Cypher 25 checks passed via service %s", boltService)
+}
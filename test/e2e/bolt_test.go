@@ -0,0 +1,49 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v6/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+func eager(keys []string, rows ...[]any) *neo4j.EagerResult {
+	result := &neo4j.EagerResult{Keys: keys}
+	for _, values := range rows {
+		result.Records = append(result.Records, &neo4j.Record{Keys: keys, Values: values})
+	}
+	return result
+}
+
+func TestCypherChecks_Verify(t *testing.T) {
+	components := []string{"name", "versions", "edition"}
+	vec := []string{"vec"}
+	sim := []string{"similarity"}
+
+	cases := []struct {
+		name    string
+		verify  func(*neo4j.EagerResult) error
+		result  *neo4j.EagerResult
+		wantErr string
+	}{
+		{"kernel present", verifyComponents, eager(components, []any{"Neo4j Kernel", []any{"2025.10.1"}, "enterprise"}), ""},
+		{"kernel missing", verifyComponents, eager(components), "no Neo4j Kernel"},
+		{"kernel without versions", verifyComponents, eager(components, []any{"Neo4j Kernel", []any{}, "community"}), "no versions"},
+		{"float32 vector", verifyVector, eager(vec, []any{neo4j.Vector[float32]{Elems: []float32{1, 2, 3}}}), ""},
+		{"list instead of vector", verifyVector, eager(vec, []any{[]any{1.0, 2.0, 3.0}}), "want a FLOAT32 vector"},
+		{"wrong elements", verifyVector, eager(vec, []any{neo4j.Vector[float32]{Elems: []float32{1, 2}}}), "want [1 2 3]"},
+		{"cosine one", verifyCosine, eager(sim, []any{1.0}), ""},
+		{"cosine off", verifyCosine, eager(sim, []any{0.5}), "want 1"},
+		{"no rows", verifyCosine, eager(sim), "expected 1 record"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.verify(tc.result)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/stretchr/testify/require"
+
+	testhelpers "github.com/simon-lentz/neo4j_gke/test"
+)
+
+// KindConfig is the kind cluster configuration the local tier creates
+// clusters from, relative to the repository root (same as `make kind-create`).
+const KindConfig = "local/kind-config.yaml"
+
+// KindClusterEnv names an existing kind cluster for the local tier to reuse
+// instead of creating (and deleting) its own.
+const KindClusterEnv = "NEO4J_GKE_KIND_CLUSTER"
+
+// KindCalicoEnv installs Calico into the kind cluster when set, so the
+// NetworkPolicy matrix can run; kind's default CNI does not enforce policies.
+const KindCalicoEnv = "NEO4J_GKE_KIND_CALICO"
+
+// CalicoManifest is the manifest `make calico-install` applies.
+var CalicoManifest = "https://raw.githubusercontent.com/projectcalico/calico/v3.27.0/manifests/calico.yaml"
+
+// KindCluster is a kind cluster with a kubeconfig of its own, so tests never
+// touch the user's current context.
+type KindCluster struct {
+	Name       string
+	Kubeconfig string
+}
+
+// Context is the kubeconfig context kind creates for the cluster.
+func (c KindCluster) Context() string {
+	return "kind-" + c.Name
+}
+
+// NewKindCluster creates a kind cluster from KindConfig and deletes it at
+// cleanup. If KindClusterEnv is set, that cluster is reused and left running.
+func NewKindCluster(t *testing.T, name string) KindCluster {
+	t.Helper()
+
+	c := KindCluster{Name: name, Kubeconfig: filepath.Join(t.TempDir(), "kubeconfig")}
+	if existing := os.Getenv(KindClusterEnv); existing != "" {
+		c.Name = existing
+		t.Logf("Reusing kind cluster %s (%s)", c.Name, KindClusterEnv)
+		require.NoError(t, runKind(t, "export", "kubeconfig", "--name", c.Name, "--kubeconfig", c.Kubeconfig),
+			"failed to export kubeconfig for kind cluster %s", c.Name)
+		return c
+	}
+
+	t.Cleanup(func() {
+		if err := runKind(t, "delete", "cluster", "--name", c.Name, "--kubeconfig", c.Kubeconfig); err != nil {
+			t.Logf("WARNING: failed to delete kind cluster %s: %v", c.Name, err)
+		}
+	})
+	t.Logf("Creating kind cluster %s...", c.Name)
+	require.NoError(t, runKind(t, "create", "cluster",
+		"--name", c.Name,
+		"--config", filepath.Join(testhelpers.RepoRoot(t), KindConfig),
+		"--kubeconfig", c.Kubeconfig,
+		"--wait", "2m",
+	), "failed to create kind cluster %s", c.Name)
+	return c
+}
+
+// InstallCalico applies CalicoManifest and waits for calico-node to be ready.
+func (c KindCluster) InstallCalico(t *testing.T) {
+	t.Helper()
+
+	t.Log("Installing Calico for NetworkPolicy enforcement...")
+	require.NoError(t, c.kubectl(t, "apply", "-f", CalicoManifest), "failed to apply Calico")
+	require.NoError(t, c.kubectl(t, "wait", "--for=condition=ready", "pod",
+		"-l", "k8s-app=calico-node", "-n", "kube-system", "--timeout=180s"), "Calico did not become ready")
+}
+
+func (c KindCluster) kubectl(t *testing.T, args ...string) error {
+	return shell.RunCommandE(t, shell.Command{
+		Command: "kubectl",
+		Args:    append([]string{"--kubeconfig", c.Kubeconfig, "--context", c.Context()}, args...),
+	})
+}
+
+func runKind(t *testing.T, args ...string) error {
+	return shell.RunCommandE(t, shell.Command{Command: "kind", Args: args})
+}
@@ -0,0 +1,307 @@
+//go:build local
+
+package e2e
+
+// The local tier deploys the neo4j_app module to a kind cluster instead of
+// GKE, with the GCP-only pieces switched off (see
+// infra/modules/neo4j_app/tests/local), and runs the same readiness and
+// Cypher checks as the e2e tier. It needs kind, kubectl and tofu, but no
+// cloud account:
+//
+//	go test -tags=local -timeout 20m -v ./test/e2e/... -run TestNeo4jApp_Local
+//
+// Optional environment variables:
+//   - NEO4J_GKE_KIND_CLUSTER: reuse an existing kind cluster (left running)
+//   - NEO4J_GKE_KIND_CALICO: install Calico and run the NetworkPolicy matrix
+//   - NEO4J_GKE_RECOVERY_LIMIT: recovery limit for TestNeo4jApp_PodLoss
+//   - NEO4J_GKE_UPGRADE_FROM_VERSION / NEO4J_GKE_UPGRADE_TO_VERSION: chart
+//     versions for TestNeo4jApp_ChartUpgrade (skipped unless "from" is set)
+//
+// TestNeo4jApp_PasswordRotation needs no extra variables.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/neo4j/neo4j-go-driver/v6/neo4j"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simon-lentz/neo4j_gke/internal/rotation"
+	testhelpers "github.com/simon-lentz/neo4j_gke/test"
+)
+
+// TestNeo4jApp_Local applies neo4j_app to a kind cluster and verifies Neo4j
+// over Bolt. The NetworkPolicy matrix runs only with Calico installed.
+func TestNeo4jApp_Local(t *testing.T) {
+	// Namespace and instance are unique per run, so a release left behind on
+	// a reused kind cluster doesn't collide with the next test's.
+	id := strings.ToLower(random.UniqueId())
+	app := &testhelpers.NeoAppLocalOptions{
+		Neo4jPassword:     fmt.Sprintf("local-pwd-%s", random.UniqueId()),
+		Neo4jNamespace:    testhelpers.Ptr("neo4j-" + id),
+		Neo4jInstanceName: testhelpers.Ptr("neo4j-local-" + id),
+		// The connectivity matrix probes from this namespace (see netpol.go).
+		AllowedIngressNamespaces: []string{ProbeAllowedNamespace},
+	}
+	budget := testhelpers.NewBudget(t, app.Module())
+
+	cluster := NewKindCluster(t, "neo4j-local-"+id)
+	calico := os.Getenv(KindCalicoEnv) != ""
+	if calico {
+		cluster.InstallCalico(t)
+	}
+
+	app.KubeconfigPath = cluster.Kubeconfig
+	app.KubeContext = cluster.Context()
+	appTf := app.TerraformOptions(t, testhelpers.CopyModuleToTemp(t, app.Module()))
+
+	budget.Cleanup(app.Module(), appTf)
+	budget.Apply(app.Module(), appTf)
+
+	namespace := terraform.Output(t, appTf, "namespace")
+	instance := terraform.Output(t, appTf, "neo4j_instance_name")
+	boltService := terraform.Output(t, appTf, "neo4j_bolt_service")
+
+	t.Log("Waiting for Neo4j pod to be ready...")
+	kubectlOptionsNs := k8s.NewKubectlOptions(cluster.Context(), cluster.Kubeconfig, namespace)
+	WaitForNeo4jReady(t, NewWaiter(t, cluster.Kubeconfig, namespace), instance, 10*time.Minute)
+
+	t.Log("Running Cypher 25 checks over Bolt...")
+	VerifyNeo4jBolt(t, kubectlOptionsNs, boltService, app.Neo4jPassword)
+
+	// Workload Identity is off: the backup KSA must not point at a GSA.
+	annotations, err := k8s.RunKubectlAndGetOutputE(t, kubectlOptionsNs, "get", "serviceaccount",
+		terraform.Output(t, appTf, "backup_ksa_name"), "-o", "jsonpath={.metadata.annotations}")
+	require.NoError(t, err)
+	require.NotContains(t, annotations, "iam.gke.io/gcp-service-account")
+
+	policies, err := k8s.RunKubectlAndGetOutputE(t, kubectlOptionsNs, "get", "networkpolicy",
+		"-o", "jsonpath={.items[*].metadata.name}")
+	require.NoError(t, err)
+	require.Contains(t, policies, "default-deny-all")
+	require.Contains(t, policies, "allow-neo4j")
+
+	if !calico {
+		t.Logf("Skipping the NetworkPolicy matrix: set %s=true to install Calico and run it", KindCalicoEnv)
+		return
+	}
+	t.Log("Probing the NetworkPolicy connectivity matrix...")
+	cfg, err := PolicyConfigFromVars(appTf.Vars, DefaultPolicyConfig)
+	require.NoError(t, err)
+	cfg.InstanceName = instance
+	RunNetworkPolicyMatrix(t, cluster.Kubeconfig, cfg, ProbeAllowedNamespace, ProbeUnlistedNamespace)
+}
+
+// TestNeo4jApp_ChartUpgrade deploys neo4j_app at the chart version in
+// NEO4J_GKE_UPGRADE_FROM_VERSION, writes an UpgradeFixture, re-applies at
+// NEO4J_GKE_UPGRADE_TO_VERSION (default: the module default) and checks the
+// data, the vector index and the Cypher 25 default survived the rollout:
+//
+//	NEO4J_GKE_UPGRADE_FROM_VERSION=2025.10.1 NEO4J_GKE_UPGRADE_TO_VERSION=2025.11.2 \
+//	    go test -tags=local -timeout 30m -v ./test/e2e/... -run TestNeo4jApp_ChartUpgrade
+func TestNeo4jApp_ChartUpgrade(t *testing.T) {
+	from := os.Getenv(ChartUpgradeFromEnv)
+	if from == "" {
+		t.Skipf("set %s (and optionally %s) to run the chart upgrade test", ChartUpgradeFromEnv, ChartUpgradeToEnv)
+	}
+	to := os.Getenv(ChartUpgradeToEnv)
+
+	id := strings.ToLower(random.UniqueId())
+	app := &testhelpers.NeoAppLocalOptions{
+		Neo4jPassword:     fmt.Sprintf("local-pwd-%s", random.UniqueId()),
+		Neo4jNamespace:    testhelpers.Ptr("neo4j-" + id),
+		Neo4jInstanceName: testhelpers.Ptr("neo4j-local-" + id),
+		Neo4jChartVersion: testhelpers.Ptr(from),
+	}
+	budget := testhelpers.NewBudget(t, app.Module(), app.Module())
+
+	cluster := NewKindCluster(t, "neo4j-upgrade-"+id)
+	app.KubeconfigPath = cluster.Kubeconfig
+	app.KubeContext = cluster.Context()
+	appTf := app.TerraformOptions(t, testhelpers.CopyModuleToTemp(t, app.Module()))
+
+	budget.Cleanup(app.Module(), appTf)
+	budget.Apply(app.Module(), appTf)
+
+	namespace := terraform.Output(t, appTf, "namespace")
+	instance := terraform.Output(t, appTf, "neo4j_instance_name")
+	boltService := terraform.Output(t, appTf, "neo4j_bolt_service")
+	kubectlOptionsNs := k8s.NewKubectlOptions(cluster.Context(), cluster.Kubeconfig, namespace)
+	waiter := NewWaiter(t, cluster.Kubeconfig, namespace)
+	WaitForNeo4jReady(t, waiter, instance, 10*time.Minute)
+
+	fixture := UpgradeFixture{
+		Run:         strings.ToLower(random.UniqueId()),
+		Markers:     100,
+		VectorIndex: "upgrade_embedding",
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Minute)
+	defer cancel()
+
+	before := OpenBolt(t, kubectlOptionsNs, boltService, app.Neo4jPassword)
+	fromVersion, err := KernelVersion(ctx, before)
+	require.NoError(t, err)
+	t.Logf("Chart %s runs Neo4j %s; seeding fixture (run %s)...", from, fromVersion, fixture.Run)
+	require.NoError(t, fixture.Seed(ctx, before))
+	require.NoError(t, RunCypherChecks(ctx, before, fixture.Checks()), "fixture does not hold before the upgrade")
+
+	// Upgrade in place: same state, new chart version.
+	if to == "" {
+		delete(appTf.Vars, "neo4j_chart_version")
+	} else {
+		appTf.Vars["neo4j_chart_version"] = to
+	}
+	budget.Apply(app.Module(), appTf)
+	toChart := terraform.Output(t, appTf, "neo4j_chart_version")
+	t.Logf("Re-applied with chart %s; waiting for the rollout...", toChart)
+
+	rolloutCtx, rolloutCancel := context.WithTimeout(t.Context(), 10*time.Minute)
+	defer rolloutCancel()
+	_, err = waiter.ForStatefulSet(rolloutCtx, instance, StatefulSetRolledOut)
+	require.NoError(t, err, "StatefulSet did not roll out chart %s", toChart)
+	WaitForNeo4jReady(t, waiter, instance, 10*time.Minute)
+
+	// The old port-forward pointed at the replaced pod. The seeding context
+	// may have run out during the rollout, so the checks get their own.
+	verifyCtx, verifyCancel := context.WithTimeout(t.Context(), 10*time.Minute)
+	defer verifyCancel()
+	after := OpenBolt(t, kubectlOptionsNs, boltService, app.Neo4jPassword)
+	toVersion, err := KernelVersion(verifyCtx, after)
+	require.NoError(t, err)
+	t.Logf("Chart %s runs Neo4j %s", toChart, toVersion)
+	if toChart != from {
+		require.NotEqual(t, fromVersion, toVersion, "Neo4j version did not change with the chart")
+	}
+	require.NoError(t, RunCypherChecks(verifyCtx, after, fixture.Checks()), "fixture did not survive %s -> %s", from, toChart)
+	require.NoError(t, RunCypherChecks(verifyCtx, after, Cypher25Checks))
+}
+
+// TestNeo4jApp_PodLoss deletes the Neo4j pod and checks it comes back on the
+// same PVC with its data within NEO4J_GKE_RECOVERY_LIMIT. The kind cluster
+// has a single node, so the node is never cordoned here; the e2e resilience
+// stage covers rescheduling onto another node.
+func TestNeo4jApp_PodLoss(t *testing.T) {
+	_, limit, err := RecoverySettingsFromEnv()
+	require.NoError(t, err)
+
+	id := strings.ToLower(random.UniqueId())
+	app := &testhelpers.NeoAppLocalOptions{
+		Neo4jPassword:     fmt.Sprintf("local-pwd-%s", random.UniqueId()),
+		Neo4jNamespace:    testhelpers.Ptr("neo4j-" + id),
+		Neo4jInstanceName: testhelpers.Ptr("neo4j-local-" + id),
+	}
+	budget := testhelpers.NewBudget(t, app.Module())
+
+	cluster := NewKindCluster(t, "neo4j-podloss-"+id)
+	app.KubeconfigPath = cluster.Kubeconfig
+	app.KubeContext = cluster.Context()
+	appTf := app.TerraformOptions(t, testhelpers.CopyModuleToTemp(t, app.Module()))
+
+	budget.Cleanup(app.Module(), appTf)
+	budget.Apply(app.Module(), appTf)
+
+	namespace := terraform.Output(t, appTf, "namespace")
+	instance := terraform.Output(t, appTf, "neo4j_instance_name")
+	waiter := NewWaiter(t, cluster.Kubeconfig, namespace)
+	WaitForNeo4jReady(t, waiter, instance, 10*time.Minute)
+
+	budget.Check("pod loss", 2*limit)
+	RequirePodLossRecovery(t, k8s.NewKubectlOptions(cluster.Context(), cluster.Kubeconfig, namespace), waiter,
+		instance, terraform.Output(t, appTf, "neo4j_bolt_service"), app.Neo4jPassword, NodeKeep, limit)
+}
+
+// memorySecrets stands in for Secret Manager in the local tier.
+type memorySecrets map[string][][]byte
+
+func (m memorySecrets) Latest(_ context.Context, secret string) ([]byte, error) {
+	v := m[secret]
+	if len(v) == 0 {
+		return nil, fmt.Errorf("secret %s has no versions", secret)
+	}
+	return v[len(v)-1], nil
+}
+
+func (m memorySecrets) AddVersion(_ context.Context, secret string, data []byte) (string, error) {
+	m[secret] = append(m[secret], data)
+	return fmt.Sprint(len(m[secret])), nil
+}
+
+// TestNeo4jApp_PasswordRotation rotates the admin password with the rotation
+// package, moves the Helm value to the new password with an in-place apply
+// and checks the old password is refused, the new one accepted and no data
+// was lost.
+func TestNeo4jApp_PasswordRotation(t *testing.T) {
+	oldPassword := fmt.Sprintf("local-pwd-%s", random.UniqueId())
+	newPassword := fmt.Sprintf("rotated-pwd-%s", random.UniqueId())
+
+	id := strings.ToLower(random.UniqueId())
+	app := &testhelpers.NeoAppLocalOptions{
+		Neo4jPassword:     oldPassword,
+		Neo4jNamespace:    testhelpers.Ptr("neo4j-" + id),
+		Neo4jInstanceName: testhelpers.Ptr("neo4j-local-" + id),
+	}
+	budget := testhelpers.NewBudget(t, app.Module(), app.Module())
+
+	cluster := NewKindCluster(t, "neo4j-rotate-"+id)
+	app.KubeconfigPath = cluster.Kubeconfig
+	app.KubeContext = cluster.Context()
+	appTf := app.TerraformOptions(t, testhelpers.CopyModuleToTemp(t, app.Module()))
+
+	budget.Cleanup(app.Module(), appTf)
+	budget.Apply(app.Module(), appTf)
+
+	namespace := terraform.Output(t, appTf, "namespace")
+	instance := terraform.Output(t, appTf, "neo4j_instance_name")
+	boltService := terraform.Output(t, appTf, "neo4j_bolt_service")
+	kubectlOptionsNs := k8s.NewKubectlOptions(cluster.Context(), cluster.Kubeconfig, namespace)
+	waiter := NewWaiter(t, cluster.Kubeconfig, namespace)
+	WaitForNeo4jReady(t, waiter, instance, 10*time.Minute)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Minute)
+	defer cancel()
+	run := strings.ToLower(random.UniqueId())
+	driver := OpenBolt(t, kubectlOptionsNs, boltService, oldPassword)
+	require.NoError(t, SeedMarkers(ctx, driver, run, 10))
+
+	t.Log("Rotating the admin password...")
+	secrets := memorySecrets{"neo4j-admin-password-local": {[]byte(oldPassword)}}
+	res, err := rotation.Rotator{
+		Secrets:    secrets,
+		SecretID:   "neo4j-admin-password-local",
+		Database:   rotation.Neo4j{Driver: driver},
+		User:       Neo4jUser,
+		KubeSecret: &rotation.KubeSecret{Client: waiter.Client, Namespace: namespace, Name: instance + "-auth"},
+	}.Rotate(ctx, oldPassword, newPassword)
+	require.NoError(t, err)
+	t.Logf("Rotated: secret version %s, Kubernetes secret %s", res.SecretVersion, res.KubeSecret)
+
+	// Move the Helm value too. This must upgrade the release in place: a
+	// reinstall would recreate the StatefulSet and its data.
+	appTf.Vars["neo4j_password"] = newPassword
+	plan := testhelpers.PlanJSON(t, appTf)
+	testhelpers.RequireNoResourceDestroyed(t, plan)
+	testhelpers.RequireResourceActions(t, plan, "module.neo4j_app.helm_release.neo4j", tfjson.Actions{tfjson.ActionUpdate})
+	budget.Apply(app.Module(), appTf)
+	WaitForNeo4jReady(t, waiter, instance, 10*time.Minute)
+
+	_, err = OpenBoltE(t, kubectlOptionsNs, boltService, oldPassword)
+	var neoErr *neo4j.Neo4jError
+	require.ErrorAs(t, err, &neoErr, "the old password still works")
+	require.Equal(t, "Neo.ClientError.Security.Unauthorized", neoErr.Code)
+
+	// The rotation context may have run out during the upgrade.
+	verifyCtx, verifyCancel := context.WithTimeout(t.Context(), 2*time.Minute)
+	defer verifyCancel()
+	rotated := OpenBolt(t, kubectlOptionsNs, boltService, newPassword)
+	n, err := CountMarkers(verifyCtx, rotated, "neo4j", run)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), n, "data lost during rotation")
+}
@@ -5,14 +5,16 @@
 //
 // Run e2e tests with:
 //
-//	go test -tags=e2e -timeout 45m -v ./test/e2e/...
+//	go test -tags=e2e -timeout 60m -v ./test/e2e/...
 //
 // Required environment variables:
 //   - NEO4J_GKE_GCP_PROJECT_ID: GCP project ID
 package e2e
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -22,237 +24,252 @@ import (
 	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
+	testStructure "github.com/gruntwork-io/terratest/modules/test-structure"
 	"github.com/stretchr/testify/require"
 
 	testhelpers "github.com/simon-lentz/neo4j_gke/test"
 )
 
-// Neo4jTestTimeout is the minimum timeout for tests that deploy Neo4j.
-// This includes: VPC (~5 min) + GKE (~15 min) + Neo4j (~10 min) + cleanup (~10 min)
-const Neo4jTestTimeout = 45 * time.Minute
+// verifyEstimate is the time the verify stage needs (pod readiness, queries
+// and the NetworkPolicy matrix).
+const verifyEstimate = 15 * time.Minute
+
+// backupEstimate is the time the backup stage needs (seeding, the backup pod
+// and the artifact checks).
+const backupEstimate = 8 * time.Minute
+
+// Backup/restore test data. The restore stage deploys a second instance into
+// restoreNamespace and restores the artifact as restoreDatabase.
+const (
+	backupMarkerCount = 100
+	restoreNamespace  = "neo4j-restore"
+	restoreDatabase   = "restored"
+)
+
+// BackupKMSKeyEnv optionally names a Cloud KMS key (full resource name) for
+// the e2e backup bucket. The GCS service agent must hold
+// roles/cloudkms.cryptoKeyEncrypterDecrypter on it.
+const BackupKMSKeyEnv = "NEO4J_GKE_E2E_BACKUP_KMS_KEY"
 
 // TestNeo4j_FullDeployment performs a full integration test of the Neo4j deployment.
 // This test is SLOW (30-40 minutes) and is only run when the e2e build tag is enabled.
 // It deploys VPC + GKE (platform layer) + Neo4j and verifies Neo4j is running.
 //
+// The test is split into named stages (vpc, gke, sa, bucket, app, verify,
+// resilience, backup, restore, teardown). Each stage saves its terraform.Options and outputs to the work dir
+// named by NEO4J_GKE_E2E_WORK_DIR and can be skipped with SKIP_<stage>=true.
+// To keep a cluster up and iterate on the app layer only:
+//
+//	export NEO4J_GKE_E2E_WORK_DIR=$HOME/.cache/neo4j-gke-e2e
+//	SKIP_teardown=true go test -tags=e2e -timeout 60m -v ./test/e2e/... -run TestNeo4j_FullDeployment
+//	SKIP_vpc=true SKIP_gke=true SKIP_sa=true SKIP_bucket=true SKIP_teardown=true \
+//	    go test -tags=e2e -timeout 30m -v ./test/e2e/... -run TestNeo4j_FullDeployment
+//
 // IMPORTANT: Run with sufficient timeout:
 //
-//	go test -tags=e2e -timeout 45m -v ./test/e2e/... -run TestNeo4j_FullDeployment
+//	go test -tags=e2e -timeout 60m -v ./test/e2e/... -run TestNeo4j_FullDeployment
 //
 // Required environment variables:
 //   - NEO4J_GKE_GCP_PROJECT_ID: GCP project ID
 func TestNeo4j_FullDeployment(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
-
-	// CRITICAL: Validate timeout BEFORE creating any resources.
-	testhelpers.RequireMinimumTimeout(t, Neo4jTestTimeout)
+	// Not parallel: the stages build on each other. Concurrent runs are
+	// isolated by the workspace's name suffix and work dir, and the shared
+	// Container API enablement is taken under a lease.
 
 	projectID := testhelpers.MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
 	region := testhelpers.GetTestRegion(t)
-	suffix := strings.ToLower(random.UniqueId())
+
+	ws := NewWorkspace(t)
+
+	// CRITICAL: Check the time budget BEFORE creating any resources. Each
+	// stage re-checks before applying and fails straight to teardown if the
+	// time reserved for destroying what exists would be eaten into.
+	budget := ws.StartBudget()
+	suffix := ws.Value("suffix", func() string { return strings.ToLower(random.UniqueId()) })
 
 	t.Logf("Starting Neo4j full deployment test with suffix: %s", suffix)
 
-	// -------------------------------------------------------------------------
-	// Step 1: Create VPC
-	// -------------------------------------------------------------------------
-	t.Log("Step 1: Creating VPC...")
-	vpcDir := testhelpers.CopyModuleToTemp(t, "vpc")
+	// Register teardown BEFORE creating anything. It destroys every stage with
+	// saved options in reverse order: restore -> app -> bucket -> SA -> GKE -> VPC.
+	t.Cleanup(func() {
+		testStructure.RunTestStage(t, StageTeardown, ws.Teardown)
+	})
+
+	testStructure.RunTestStage(t, StageVPC, func() {
+		stageVPC(t, ws, projectID, region, suffix)
+	})
+	testStructure.RunTestStage(t, StageGKE, func() {
+		stageGKE(t, ws, projectID, region, suffix)
+	})
+	testStructure.RunTestStage(t, StageSA, func() {
+		stageServiceAccount(t, ws, projectID, suffix)
+	})
+	testStructure.RunTestStage(t, StageBucket, func() {
+		stageBackupBucket(t, ws, projectID, region, suffix)
+	})
+	testStructure.RunTestStage(t, StageApp, func() {
+		stageNeo4jApp(t, ws, projectID, region, suffix)
+	})
+	testStructure.RunTestStage(t, StageVerify, func() {
+		budget.Check(StageVerify, verifyEstimate)
+		stageVerify(t, ws, projectID, region)
+	})
+	testStructure.RunTestStage(t, StageResilience, func() {
+		stageResilience(t, ws, projectID, region)
+	})
+	testStructure.RunTestStage(t, StageBackup, func() {
+		budget.Check(StageBackup, backupEstimate)
+		stageBackup(t, ws, projectID, region)
+	})
+	testStructure.RunTestStage(t, StageRestore, func() {
+		stageRestore(t, ws, projectID, region, suffix)
+	})
+
+	t.Log("Neo4j full deployment test PASSED!")
+}
+
+// stageVPC creates the VPC and saves the network outputs GKE needs.
+func stageVPC(t *testing.T, ws *Workspace, projectID, region, suffix string) {
+	t.Log("Stage vpc: Creating VPC...")
 	vpcName := fmt.Sprintf("neo4j-test-vpc-%s", suffix)
 
-	vpcTf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    vpcDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":       projectID,
-			"region":           region,
-			"vpc_name":         vpcName,
-			"enable_cloud_nat": true,
-		},
-		NoColor: true,
+	vpcTf := ws.OptionsOrCreate(StageVPC, func() *terraform.Options {
+		vpc := &testhelpers.VPCOptions{
+			ProjectID:      projectID,
+			Region:         region,
+			VPCName:        testhelpers.Ptr(vpcName),
+			EnableCloudNAT: testhelpers.Ptr(true),
+		}
+		return vpc.TerraformOptions(t, ws.CopyModule(vpc.Module()))
 	})
 
-	// -------------------------------------------------------------------------
-	// Step 2: Set up GKE options
-	// -------------------------------------------------------------------------
-	gkeDir := testhelpers.CopyModuleToTemp(t, "gke")
+	ws.Apply(StageVPC, vpcTf)
+	ws.SaveOutputs(StageVPC, vpcTf, "network_id", "subnet_id", "pods_range_name", "services_range_name")
+
+	t.Logf("VPC created: %s (network_id: %s)", vpcName, ws.Output(StageVPC, "network_id"))
+}
+
+// stageGKE creates the Autopilot cluster on the saved VPC outputs.
+func stageGKE(t *testing.T, ws *Workspace, projectID, region, suffix string) {
+	t.Log("Stage gke: Creating GKE Autopilot cluster (this takes 15-20 minutes)...")
 	clusterName := fmt.Sprintf("neo4j-test-%s", suffix)
 
-	gkeTf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    gkeDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":           projectID,
-			"region":               region,
-			"cluster_name":         clusterName,
-			"network_id":           "", // Set after VPC creation
-			"subnet_id":            "", // Set after VPC creation
-			"pods_range_name":      "", // Set after VPC creation
-			"services_range_name":  "", // Set after VPC creation
-			"deletion_protection":  false,
-			"enable_container_api": true,
-		},
-		NoColor: true,
+	gkeTf := ws.OptionsOrCreate(StageGKE, func() *terraform.Options {
+		gke := &testhelpers.GKEOptions{
+			ProjectID:          projectID,
+			Region:             region,
+			ClusterName:        testhelpers.Ptr(clusterName),
+			NetworkID:          ws.Output(StageVPC, "network_id"),
+			SubnetID:           ws.Output(StageVPC, "subnet_id"),
+			PodsRangeName:      ws.Output(StageVPC, "pods_range_name"),
+			ServicesRangeName:  ws.Output(StageVPC, "services_range_name"),
+			DeletionProtection: testhelpers.Ptr(false),
+			EnableContainerAPI: testhelpers.Ptr(true),
+		}
+		return gke.TerraformOptions(t, ws.CopyModule(gke.Module()))
 	})
 
-	// -------------------------------------------------------------------------
-	// Step 3: Set up service account options
-	// -------------------------------------------------------------------------
-	saDir := testhelpers.CopyModuleToTemp(t, "service_accounts")
-	backupSAName := fmt.Sprintf("neo4j-bk-%s", suffix)
+	// The Container API enablement is shared with other tests; its destroy is
+	// a no-op (disable_on_destroy = false), so the lease only covers the apply.
+	apiLease := testhelpers.AcquireLease(t, testhelpers.ProjectServiceLeaseKey(projectID, "container.googleapis.com"))
+	ws.Apply(StageGKE, gkeTf)
+	apiLease.Release(t)
+	ws.SaveOutputs(StageGKE, gkeTf, "cluster_name", "cluster_endpoint", "workload_identity_pool")
 
-	saTf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    saDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id": projectID,
-			"service_accounts": map[string]any{
-				backupSAName: map[string]any{
-					"description": "Neo4j backup SA for integration test",
-				},
-			},
-			"prevent_destroy_service_accounts": false,
-		},
-		NoColor: true,
-	})
+	require.NotEmpty(t, ws.Output(StageGKE, "cluster_endpoint"))
+	require.Equal(t, fmt.Sprintf("%s.svc.id.goog", projectID), ws.Output(StageGKE, "workload_identity_pool"))
 
-	// -------------------------------------------------------------------------
-	// Step 4: Set up backup bucket options
-	// -------------------------------------------------------------------------
-	bucketDir := testhelpers.CopyModuleToTemp(t, "backup_bucket")
-	backupBucketName := fmt.Sprintf("%s-neo4j-bkp-%s", projectID, suffix)
+	t.Logf("GKE cluster created: %s", clusterName)
+}
 
-	bucketTf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    bucketDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":        projectID,
-			"bucket_name":       backupBucketName,
-			"location":          region,
-			"backup_sa_email":   fmt.Sprintf("%s@%s.iam.gserviceaccount.com", backupSAName, projectID),
-			"enable_versioning": false,
-			"force_destroy":     true,
-		},
-		NoColor: true,
-	})
+// stageServiceAccount creates the backup GSA.
+func stageServiceAccount(t *testing.T, ws *Workspace, projectID, suffix string) {
+	t.Log("Stage sa: Creating service account...")
+	backupSAName := fmt.Sprintf("neo4j-bk-%s", suffix)
 
-	// Register cleanup in reverse dependency order (LIFO).
-	// Order: bucket -> SA -> GKE -> VPC
-	testhelpers.DeferredTerraformCleanup(t, vpcTf)
-	testhelpers.DeferredTerraformCleanup(t, gkeTf)
-	testhelpers.DeferredTerraformCleanup(t, saTf)
-	testhelpers.DeferredTerraformCleanup(t, bucketTf)
-
-	// -------------------------------------------------------------------------
-	// Apply VPC
-	// -------------------------------------------------------------------------
-	terraform.InitAndApply(t, vpcTf)
-
-	networkID, err := terraform.OutputE(t, vpcTf, "network_id")
-	require.NoError(t, err, "failed to get network_id output")
-	subnetID, err := terraform.OutputE(t, vpcTf, "subnet_id")
-	require.NoError(t, err, "failed to get subnet_id output")
-	podsRangeName, err := terraform.OutputE(t, vpcTf, "pods_range_name")
-	require.NoError(t, err, "failed to get pods_range_name output")
-	servicesRangeName, err := terraform.OutputE(t, vpcTf, "services_range_name")
-	require.NoError(t, err, "failed to get services_range_name output")
-
-	t.Logf("VPC created: %s (network_id: %s)", vpcName, networkID)
-
-	// -------------------------------------------------------------------------
-	// Apply GKE with VPC outputs
-	// -------------------------------------------------------------------------
-	t.Log("Step 2: Creating GKE Autopilot cluster (this takes 15-20 minutes)...")
-
-	// Create new GKE options with actual VPC values (avoid mutating original)
-	gkeTfApply := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    gkeDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":           projectID,
-			"region":               region,
-			"cluster_name":         clusterName,
-			"network_id":           networkID,
-			"subnet_id":            subnetID,
-			"pods_range_name":      podsRangeName,
-			"services_range_name":  servicesRangeName,
-			"deletion_protection":  false,
-			"enable_container_api": true,
-		},
-		NoColor: true,
+	saTf := ws.OptionsOrCreate(StageSA, func() *terraform.Options {
+		sa := &testhelpers.ServiceAccountsOptions{
+			ProjectID: projectID,
+			ServiceAccounts: map[string]testhelpers.ServiceAccountsServiceAccount{
+				backupSAName: {Description: "Neo4j backup SA for integration test"},
+			},
+			PreventDestroyServiceAccounts: testhelpers.Ptr(false),
+		}
+		return sa.TerraformOptions(t, ws.CopyModule(sa.Module()))
 	})
 
-	terraform.InitAndApply(t, gkeTfApply)
+	ws.Apply(StageSA, saTf)
 
-	clusterEndpoint, err := terraform.OutputE(t, gkeTfApply, "cluster_endpoint")
-	require.NoError(t, err, "failed to get cluster_endpoint output")
-	workloadIdentityPool, err := terraform.OutputE(t, gkeTfApply, "workload_identity_pool")
-	require.NoError(t, err, "failed to get workload_identity_pool output")
-	require.NotEmpty(t, clusterEndpoint)
-	require.Equal(t, fmt.Sprintf("%s.svc.id.goog", projectID), workloadIdentityPool)
-
-	t.Logf("GKE cluster created: %s", clusterName)
+	backupGSAEmail := fmt.Sprintf("%s@%s.iam.gserviceaccount.com", backupSAName, projectID)
+	ws.SaveOutput(StageSA, "backup_gsa_email", backupGSAEmail)
+	ws.SaveOutput(StageSA, "backup_gsa_name", fmt.Sprintf("projects/%s/serviceAccounts/%s", projectID, backupGSAEmail))
 
-	// -------------------------------------------------------------------------
-	// Apply service account
-	// -------------------------------------------------------------------------
-	t.Log("Step 3: Creating service account...")
-	terraform.InitAndApply(t, saTf)
 	t.Logf("Service account created: %s", backupSAName)
+}
 
-	// -------------------------------------------------------------------------
-	// Apply backup bucket
-	// -------------------------------------------------------------------------
-	t.Log("Step 4: Creating backup bucket...")
-	terraform.InitAndApply(t, bucketTf)
+// stageBackupBucket creates the backup bucket granted to the backup GSA,
+// encrypted with the key named by BackupKMSKeyEnv if set.
+func stageBackupBucket(t *testing.T, ws *Workspace, projectID, region, suffix string) {
+	t.Log("Stage bucket: Creating backup bucket...")
+	backupBucketName := fmt.Sprintf("%s-neo4j-bkp-%s", projectID, suffix)
 
-	backupBucketURL, err := terraform.OutputE(t, bucketTf, "bucket_url")
-	require.NoError(t, err, "failed to get bucket_url output")
-	require.Equal(t, fmt.Sprintf("gs://%s", backupBucketName), backupBucketURL)
-	t.Logf("Backup bucket created: %s", backupBucketName)
+	bucketTf := ws.OptionsOrCreate(StageBucket, func() *terraform.Options {
+		bucket := &testhelpers.BackupBucketOptions{
+			ProjectID:        projectID,
+			BucketName:       backupBucketName,
+			Location:         region,
+			BackupSAEmail:    ws.Output(StageSA, "backup_gsa_email"),
+			EnableVersioning: testhelpers.Ptr(false),
+			ForceDestroy:     testhelpers.Ptr(true),
+		}
+		if key := os.Getenv(BackupKMSKeyEnv); key != "" {
+			bucket.KMSKeyName = testhelpers.Ptr(key)
+		}
+		return bucket.TerraformOptions(t, ws.CopyModule(bucket.Module()))
+	})
 
-	// -------------------------------------------------------------------------
-	// Step 5: Deploy Neo4j via neo4j_app module
-	// -------------------------------------------------------------------------
-	t.Log("Step 5: Deploying Neo4j via neo4j_app module...")
+	ws.Apply(StageBucket, bucketTf)
+	ws.SaveOutputs(StageBucket, bucketTf, "bucket_url")
+	kmsKey, _ := bucketTf.Vars["kms_key_name"].(string)
+	ws.SaveOutput(StageBucket, "kms_key_name", kmsKey)
 
-	// Get service account resource name for WIF binding
-	backupGSAEmail := fmt.Sprintf("%s@%s.iam.gserviceaccount.com", backupSAName, projectID)
-	backupGSAName := fmt.Sprintf("projects/%s/serviceAccounts/%s", projectID, backupGSAEmail)
+	require.Equal(t, fmt.Sprintf("gs://%s", backupBucketName), ws.Output(StageBucket, "bucket_url"))
+	t.Logf("Backup bucket created: %s", backupBucketName)
+}
+
+// stageNeo4jApp deploys Neo4j via the neo4j_app e2e wrapper.
+func stageNeo4jApp(t *testing.T, ws *Workspace, projectID, region, suffix string) {
+	t.Log("Stage app: Deploying Neo4j via neo4j_app module...")
 
 	neo4jInstanceName := fmt.Sprintf("neo4j-%s", suffix)
-	testPassword := fmt.Sprintf("test-pwd-%s", random.UniqueId())
-
-	appDir := testhelpers.CopyModuleToTemp(t, "neo4j_app/tests/e2e")
-
-	appTf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    appDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":             projectID,
-			"region":                 region,
-			"cluster_name":           clusterName,
-			"cluster_location":       region,
-			"workload_identity_pool": workloadIdentityPool,
-			"backup_gsa_email":       backupGSAEmail,
-			"backup_gsa_name":        backupGSAName,
-			"backup_bucket_url":      backupBucketURL,
-			"neo4j_password":         testPassword,
-			"neo4j_instance_name":    neo4jInstanceName,
-			"neo4j_namespace":        "neo4j",
-		},
-		NoColor: true,
+	testPassword := ws.Value("neo4j_password", func() string {
+		return fmt.Sprintf("test-pwd-%s", random.UniqueId())
 	})
 
-	// Register cleanup for app layer (runs before bucket/SA/GKE/VPC due to LIFO)
-	testhelpers.DeferredTerraformCleanup(t, appTf)
+	appTf := ws.OptionsOrCreate(StageApp, func() *terraform.Options {
+		app := &testhelpers.NeoAppE2EOptions{
+			ProjectID:            projectID,
+			Region:               region,
+			ClusterName:          ws.Output(StageGKE, "cluster_name"),
+			ClusterLocation:      region,
+			WorkloadIdentityPool: ws.Output(StageGKE, "workload_identity_pool"),
+			BackupGSAEmail:       ws.Output(StageSA, "backup_gsa_email"),
+			BackupGSAName:        ws.Output(StageSA, "backup_gsa_name"),
+			BackupBucketURL:      ws.Output(StageBucket, "bucket_url"),
+			Neo4jPassword:        testPassword,
+			Neo4jInstanceName:    testhelpers.Ptr(neo4jInstanceName),
+			Neo4jNamespace:       testhelpers.Ptr("neo4j"),
+			// The connectivity matrix probes from this namespace (see netpol.go).
+			AllowedIngressNamespaces: []string{ProbeAllowedNamespace},
+		}
+		return app.TerraformOptions(t, ws.CopyModule(app.Module()))
+	})
 
-	terraform.InitAndApply(t, appTf)
+	ws.Apply(StageApp, appTf)
+	ws.SaveOutputs(StageApp, appTf, "namespace", "neo4j_instance_name", "neo4j_bolt_service", "backup_ksa_name")
 
 	// Verify app layer outputs
-	namespace, err := terraform.OutputE(t, appTf, "namespace")
-	require.NoError(t, err, "failed to get namespace output")
+	namespace := ws.Output(StageApp, "namespace")
 	require.Equal(t, "neo4j", namespace)
 
 	wiBindingMember, err := terraform.OutputE(t, appTf, "wi_binding_member")
@@ -268,24 +285,31 @@ func TestNeo4j_FullDeployment(t *testing.T) {
 	require.Equal(t, "allow-neo4j", allowNeo4jPolicy)
 
 	t.Logf("Neo4j app layer deployed: instance=%s, namespace=%s", neo4jInstanceName, namespace)
+}
+
+// stageVerify waits for Neo4j and checks it is serving, using only saved outputs
+// so it can run against a cluster kept up by an earlier run.
+func stageVerify(t *testing.T, ws *Workspace, projectID, region string) {
+	clusterName := ws.Output(StageGKE, "cluster_name")
+	neo4jInstanceName := ws.Output(StageApp, "neo4j_instance_name")
+	namespace := ws.Output(StageApp, "namespace")
 
-	// -------------------------------------------------------------------------
-	// Step 6: Set up kubeconfig and wait for Neo4j
-	// -------------------------------------------------------------------------
-	t.Log("Step 6: Waiting for Neo4j pod to be ready...")
+	t.Log("Stage verify: Waiting for Neo4j pod to be ready...")
 	kubeconfigPath := setupKubeconfig(t, projectID, region, clusterName)
-	kubectlOptionsNs := k8s.NewKubectlOptions("", kubeconfigPath, "neo4j")
-	waitForNeo4jReady(t, kubectlOptionsNs, neo4jInstanceName, 10*time.Minute)
+	kubectlOptionsNs := k8s.NewKubectlOptions("", kubeconfigPath, namespace)
+	WaitForNeo4jReady(t, NewWaiter(t, kubeconfigPath, namespace), neo4jInstanceName, 10*time.Minute)
 	t.Log("Neo4j pod is ready")
 
-	// -------------------------------------------------------------------------
-	// Step 7: Verify Neo4j is running
-	// -------------------------------------------------------------------------
-	t.Log("Step 7: Verifying Neo4j is running...")
-	verifyNeo4jRunning(t, kubectlOptionsNs, neo4jInstanceName)
+	t.Log("Stage verify: Running Cypher 25 checks over Bolt...")
+	boltService := ws.Output(StageApp, "neo4j_bolt_service")
+	password := ws.Value("neo4j_password", func() string {
+		require.FailNow(t, "neo4j_password was not saved by the app stage")
+		return ""
+	})
+	VerifyNeo4jBolt(t, kubectlOptionsNs, boltService, password)
 
 	// Additional verification: check NetworkPolicies exist via kubectl
-	t.Log("Step 7b: Verifying NetworkPolicies via kubectl...")
+	t.Log("Stage verify: Verifying NetworkPolicies via kubectl...")
 	policies, err := k8s.RunKubectlAndGetOutputE(t, kubectlOptionsNs, "get", "networkpolicy",
 		"-o", "jsonpath={.items[*].metadata.name}")
 	require.NoError(t, err)
@@ -293,7 +317,136 @@ func TestNeo4j_FullDeployment(t *testing.T) {
 	require.Contains(t, policies, "allow-neo4j")
 	t.Logf("NetworkPolicies verified: %s", policies)
 
-	t.Log("Neo4j full deployment test PASSED!")
+	t.Log("Stage verify: Probing the NetworkPolicy connectivity matrix...")
+	cfg, err := PolicyConfigFromVars(ws.LoadOptions(StageApp).Vars, DefaultPolicyConfig)
+	require.NoError(t, err)
+	RunNetworkPolicyMatrix(t, kubeconfigPath, cfg, ProbeAllowedNamespace, ProbeUnlistedNamespace)
+}
+
+// stageResilience loses the Neo4j pod (and, with NEO4J_GKE_NODE_ACTION, its
+// node) and checks it comes back on the same volume with its data within
+// NEO4J_GKE_RECOVERY_LIMIT. The measured recovery duration is saved.
+func stageResilience(t *testing.T, ws *Workspace, projectID, region string) {
+	action, limit, err := RecoverySettingsFromEnv()
+	require.NoError(t, err)
+	ws.budget.Check(StageResilience, 2*limit)
+
+	namespace := ws.Output(StageApp, "namespace")
+	password := ws.Value("neo4j_password", func() string {
+		require.FailNow(t, "neo4j_password was not saved by the app stage")
+		return ""
+	})
+	kubeconfigPath := setupKubeconfig(t, projectID, region, ws.Output(StageGKE, "cluster_name"))
+	kubectlOptionsNs := k8s.NewKubectlOptions("", kubeconfigPath, namespace)
+
+	t.Log("Stage resilience: Losing the Neo4j pod...")
+	r := RequirePodLossRecovery(t, kubectlOptionsNs, NewWaiter(t, kubeconfigPath, namespace),
+		ws.Output(StageApp, "neo4j_instance_name"), ws.Output(StageApp, "neo4j_bolt_service"), password, action, limit)
+	ws.SaveOutput(StageResilience, "recovery_duration", r.Duration.String())
+}
+
+// stageBackup seeds marker nodes and backs the database up to the bucket from
+// a pod running as the backup KSA, so the write goes through Workload
+// Identity. It checks the artifact landed encrypted with the bucket's key.
+func stageBackup(t *testing.T, ws *Workspace, projectID, region string) {
+	namespace := ws.Output(StageApp, "namespace")
+	instance := ws.Output(StageApp, "neo4j_instance_name")
+	password := ws.Value("neo4j_password", func() string {
+		require.FailNow(t, "neo4j_password was not saved by the app stage")
+		return ""
+	})
+
+	kubeconfigPath := setupKubeconfig(t, projectID, region, ws.Output(StageGKE, "cluster_name"))
+	kubectlOptionsNs := k8s.NewKubectlOptions("", kubeconfigPath, namespace)
+
+	run := strings.ToLower(random.UniqueId())
+	t.Logf("Stage backup: Seeding %d marker nodes (run %s)...", backupMarkerCount, run)
+	driver := OpenBolt(t, kubectlOptionsNs, ws.Output(StageApp, "neo4j_bolt_service"), password)
+	ctx, cancel := context.WithTimeout(t.Context(), 2*time.Minute)
+	defer cancel()
+	require.NoError(t, SeedMarkers(ctx, driver, run, backupMarkerCount))
+	ws.SaveOutput(StageBackup, "marker_run", run)
+	version, err := KernelVersion(ctx, driver)
+	require.NoError(t, err, "failed to read the server version")
+
+	cfg, err := PolicyConfigFromVars(ws.LoadOptions(StageApp).Vars, DefaultPolicyConfig)
+	require.NoError(t, err)
+	toPath := fmt.Sprintf("%s/e2e/%s", ws.Output(StageBucket, "bucket_url"), run)
+
+	t.Logf("Stage backup: Running neo4j-admin database backup to %s...", toPath)
+	RunBackup(t, NewWaiter(t, kubeconfigPath, namespace), BackupSpec{
+		Namespace:      namespace,
+		InstanceName:   instance,
+		ServiceAccount: ws.Output(StageApp, "backup_ksa_name"),
+		PodLabel:       cfg.BackupPodLabel,
+		Database:       "neo4j",
+		ToPath:         toPath,
+		Image:          Neo4jImage(version),
+	}, 5*time.Minute)
+
+	artifacts := BackupArtifacts(testhelpers.ListObjects(t, projectID, toPath))
+	require.Len(t, artifacts, 1, "expected exactly one backup artifact under %s", toPath)
+	ws.SaveOutput(StageBackup, "artifact_url", artifacts[0])
+
+	wantKey := ws.Output(StageBucket, "kms_key_name")
+	obj := testhelpers.DescribeObject(t, projectID, artifacts[0])
+	if wantKey == "" {
+		require.Empty(t, obj.KMSKey, "artifact is CMEK-encrypted but the bucket has no key")
+		t.Logf("Backup artifact %s written (Google-managed encryption)", artifacts[0])
+		return
+	}
+	require.Equal(t, wantKey, testhelpers.DescribeBucket(t, projectID, strings.TrimPrefix(ws.Output(StageBucket, "bucket_url"), "gs://")).DefaultKMSKey)
+	require.Equal(t, wantKey, obj.CryptoKey(), "artifact is not encrypted with the bucket key")
+	t.Logf("Backup artifact %s written, encrypted with %s", artifacts[0], wantKey)
+}
+
+// stageRestore deploys a fresh instance next to the first, restores the backup
+// artifact into it and checks the marker nodes survived the round trip.
+func stageRestore(t *testing.T, ws *Workspace, projectID, region, suffix string) {
+	t.Log("Stage restore: Deploying a fresh Neo4j instance...")
+	password := ws.Value("neo4j_password", func() string {
+		require.FailNow(t, "neo4j_password was not saved by the app stage")
+		return ""
+	})
+
+	restoreTf := ws.OptionsOrCreate(StageRestore, func() *terraform.Options {
+		app := &testhelpers.NeoAppE2EOptions{
+			ProjectID:            projectID,
+			Region:               region,
+			ClusterName:          ws.Output(StageGKE, "cluster_name"),
+			ClusterLocation:      region,
+			WorkloadIdentityPool: ws.Output(StageGKE, "workload_identity_pool"),
+			BackupGSAEmail:       ws.Output(StageSA, "backup_gsa_email"),
+			BackupGSAName:        ws.Output(StageSA, "backup_gsa_name"),
+			BackupBucketURL:      ws.Output(StageBucket, "bucket_url"),
+			Neo4jPassword:        password,
+			Neo4jInstanceName:    testhelpers.Ptr(fmt.Sprintf("neo4j-r-%s", suffix)),
+			Neo4jNamespace:       testhelpers.Ptr(restoreNamespace),
+		}
+		return app.TerraformOptions(t, ws.CopyModule(app.Module()))
+	})
+
+	ws.Apply(StageRestore, restoreTf)
+	ws.SaveOutputs(StageRestore, restoreTf, "namespace", "neo4j_instance_name", "neo4j_bolt_service")
+
+	namespace := ws.Output(StageRestore, "namespace")
+	instance := ws.Output(StageRestore, "neo4j_instance_name")
+	kubeconfigPath := setupKubeconfig(t, projectID, region, ws.Output(StageGKE, "cluster_name"))
+	kubectlOptionsNs := k8s.NewKubectlOptions("", kubeconfigPath, namespace)
+	WaitForNeo4jReady(t, NewWaiter(t, kubeconfigPath, namespace), instance, 10*time.Minute)
+
+	artifact := ws.Output(StageBackup, "artifact_url")
+	t.Logf("Stage restore: Restoring %s as database %q...", artifact, restoreDatabase)
+	driver := OpenBolt(t, kubectlOptionsNs, ws.Output(StageRestore, "neo4j_bolt_service"), password)
+	RestoreFromBucket(t, projectID, kubectlOptionsNs, fmt.Sprintf("%s-0", instance), artifact, restoreDatabase, driver)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 2*time.Minute)
+	defer cancel()
+	run := ws.Output(StageBackup, "marker_run")
+	n, err := CountMarkers(ctx, driver, restoreDatabase, run)
+	require.NoError(t, err)
+	require.Equal(t, int64(backupMarkerCount), n, "restored database lost marker nodes")
+	t.Logf("Restored %d marker nodes of run %s into %s", n, run, instance)
 }
 
 // setupKubeconfig generates a kubeconfig for the GKE cluster.
@@ -317,61 +470,3 @@ func setupKubeconfig(t *testing.T, projectID, region, clusterName string) string
 
 	return kubeconfigPath
 }
-
-// waitForNeo4jReady waits for the Neo4j StatefulSet pod to be ready.
-func waitForNeo4jReady(t *testing.T, options *k8s.KubectlOptions, releaseName string, timeout time.Duration) {
-	t.Helper()
-
-	podName := fmt.Sprintf("%s-0", releaseName)
-	deadline := time.Now().Add(timeout)
-
-	for time.Now().Before(deadline) {
-		// Check if pod exists and is running
-		out, err := k8s.RunKubectlAndGetOutputE(t, options, "get", "pod", podName,
-			"-o", "jsonpath={.status.phase}")
-		if err == nil && strings.TrimSpace(out) == "Running" {
-			// Check container readiness
-			ready, _ := k8s.RunKubectlAndGetOutputE(t, options, "get", "pod", podName,
-				"-o", "jsonpath={.status.conditions[?(@.type=='Ready')].status}")
-			if strings.TrimSpace(ready) == "True" {
-				return
-			}
-		}
-		time.Sleep(30 * time.Second)
-		t.Logf("Waiting for Neo4j pod %s to be ready...", podName)
-	}
-
-	// On timeout, get pod status for debugging
-	status, _ := k8s.RunKubectlAndGetOutputE(t, options, "describe", "pod", podName)
-	t.Logf("Pod status on timeout:\n%s", status)
-
-	require.FailNow(t, "Timeout waiting for Neo4j pod to be ready")
-}
-
-// verifyNeo4jRunning verifies Neo4j is running by checking pod status and logs.
-func verifyNeo4jRunning(t *testing.T, options *k8s.KubectlOptions, releaseName string) {
-	t.Helper()
-
-	podName := fmt.Sprintf("%s-0", releaseName)
-
-	// Check pod is running
-	out, err := k8s.RunKubectlAndGetOutputE(t, options, "get", "pod", podName,
-		"-o", "jsonpath={.status.phase}")
-	require.NoError(t, err)
-	require.Equal(t, "Running", strings.TrimSpace(out))
-
-	// Check logs for successful startup
-	logs, err := k8s.RunKubectlAndGetOutputE(t, options, "logs", podName, "--tail=100")
-	require.NoError(t, err)
-	require.Contains(t, strings.ToLower(logs), "started", "Neo4j logs should indicate successful startup")
-
-	// Verify services exist
-	// Neo4j Helm chart uses "app" label (set to neo4j.name value), not app.kubernetes.io/instance
-	services, err := k8s.RunKubectlAndGetOutputE(t, options, "get", "svc",
-		"-l", fmt.Sprintf("app=%s", releaseName),
-		"-o", "jsonpath={.items[*].metadata.name}")
-	require.NoError(t, err)
-	require.NotEmpty(t, services, "Neo4j services should exist")
-
-	t.Logf("Neo4j services found: %s", services)
-}
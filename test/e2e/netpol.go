@@ -0,0 +1,443 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The connectivity matrix checks that the neo4j_app NetworkPolicies block
+// what they should, not just that they exist. Probe pods in the Neo4j
+// namespace, in an allowed namespace and in an unlisted namespace try TCP
+// connections to the Neo4j pod on MatrixPorts; the results are compared with
+// ExpectedMatrix, which models the module's policies from its variables.
+//
+// The harness only needs a kubeconfig, so it runs on GKE and on kind. kind's
+// default CNI does not enforce NetworkPolicy; install Calico first
+// (make calico-install) or the matrix fails with a hint saying so.
+
+// MatrixPorts are the Neo4j ports probed: Bolt, HTTP and backup.
+var MatrixPorts = []int{7687, 7474, 6362}
+
+// ProbeImage runs the probes; it must provide sh and nc.
+var ProbeImage = "busybox:1.36"
+
+// probeTimeout bounds each TCP connection attempt.
+const probeTimeout = 3 * time.Second
+
+// PolicyConfig is the subset of neo4j_app variables that shape its
+// NetworkPolicies.
+type PolicyConfig struct {
+	Namespace            string
+	InstanceName         string
+	EnableBrowser        bool
+	EnableExternalAccess bool
+	BackupPodLabel       string
+	AllowedNamespaces    []string
+}
+
+// DefaultPolicyConfig holds the neo4j_app/tests/e2e defaults.
+var DefaultPolicyConfig = PolicyConfig{
+	Namespace:      "neo4j",
+	InstanceName:   "neo4j-test",
+	EnableBrowser:  true,
+	BackupPodLabel: "neo4j-backup",
+}
+
+// PolicyConfigFromVars reads a PolicyConfig from module variables (e.g.
+// terraform.Options.Vars), falling back to base for unset variables.
+func PolicyConfigFromVars(vars map[string]any, base PolicyConfig) (PolicyConfig, error) {
+	cfg := base
+	cfg.AllowedNamespaces = slices.Clone(base.AllowedNamespaces)
+
+	str := func(name string, dst *string) error {
+		if v, ok := vars[name]; ok && v != nil {
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("variable %s is %T, want string", name, v)
+			}
+			*dst = s
+		}
+		return nil
+	}
+	boolean := func(name string, dst *bool) error {
+		if v, ok := vars[name]; ok && v != nil {
+			b, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("variable %s is %T, want bool", name, v)
+			}
+			*dst = b
+		}
+		return nil
+	}
+	for _, err := range []error{
+		str("neo4j_namespace", &cfg.Namespace),
+		str("neo4j_instance_name", &cfg.InstanceName),
+		str("backup_pod_label", &cfg.BackupPodLabel),
+		boolean("enable_neo4j_browser", &cfg.EnableBrowser),
+		boolean("enable_external_access", &cfg.EnableExternalAccess),
+	} {
+		if err != nil {
+			return cfg, err
+		}
+	}
+
+	if v, ok := vars["allowed_ingress_namespaces"]; ok && v != nil {
+		cfg.AllowedNamespaces = nil
+		switch list := v.(type) {
+		case []string:
+			cfg.AllowedNamespaces = slices.Clone(list)
+		case []any: // options loaded back from JSON
+			for _, item := range list {
+				s, ok := item.(string)
+				if !ok {
+					return cfg, fmt.Errorf("allowed_ingress_namespaces item is %T, want string", item)
+				}
+				cfg.AllowedNamespaces = append(cfg.AllowedNamespaces, s)
+			}
+		default:
+			return cfg, fmt.Errorf("variable allowed_ingress_namespaces is %T, want list", v)
+		}
+	}
+	return cfg, nil
+}
+
+// Probe is a pod that connections are attempted from.
+type Probe struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+}
+
+// MatrixProbes returns the probes for cfg: a plain pod and a backup-labelled
+// pod in the Neo4j namespace, and a pod in each of allowedNS (which must be
+// listed in cfg.AllowedNamespaces) and unlistedNS.
+func MatrixProbes(cfg PolicyConfig, allowedNS, unlistedNS string) []Probe {
+	return []Probe{
+		{Name: "same-namespace", Namespace: cfg.Namespace, Labels: map[string]string{"app.kubernetes.io/name": "netpol-probe"}},
+		{Name: "backup-pod", Namespace: cfg.Namespace, Labels: map[string]string{"app.kubernetes.io/name": cfg.BackupPodLabel}},
+		{Name: "allowed-namespace", Namespace: allowedNS, Labels: map[string]string{"app.kubernetes.io/name": "netpol-probe"}},
+		{Name: "unlisted-namespace", Namespace: unlistedNS, Labels: map[string]string{"app.kubernetes.io/name": "netpol-probe"}},
+	}
+}
+
+// Expectation is the expected outcome of one probe/port cell.
+type Expectation int
+
+const (
+	ExpectDeny Expectation = iota
+	ExpectAllow
+	// ExpectEither marks cells whose outcome is left to the CNI: an ipBlock
+	// of 0.0.0.0/0 matches pod IPs on Calico but not on GKE Dataplane V2.
+	ExpectEither
+)
+
+func (e Expectation) String() string {
+	switch e {
+	case ExpectAllow:
+		return "allow"
+	case ExpectEither:
+		return "either"
+	default:
+		return "deny"
+	}
+}
+
+// Cell addresses one probe/port pair of the matrix.
+type Cell struct {
+	Probe string
+	Port  int
+}
+
+// ExpectedMatrix models the neo4j_app NetworkPolicies for connections from
+// each probe to the Neo4j pod. A connection needs both egress from the probe
+// and ingress to Neo4j:
+//
+//   - default-deny-all denies all egress from the Neo4j namespace; the only
+//     egress the module re-allows is DNS, HTTPS, the metadata server, Neo4j
+//     -> backup pods on 6362 and backup pods -> Neo4j on 6362, so of the
+//     in-namespace probes only a pod labelled backup_pod_label reaches
+//     anything, and only the backup port.
+//   - allow-neo4j admits 7687 (and 7474 with enable_neo4j_browser) from the
+//     Neo4j namespace and allowed_ingress_namespaces, and from 0.0.0.0/0
+//     with enable_external_access.
+//   - allow-backup-ingress admits 6362 from backup pods only.
+//
+// Namespaces other than the Neo4j one have no policies, so their egress is
+// open.
+func ExpectedMatrix(cfg PolicyConfig, probes []Probe) map[Cell]Expectation {
+	ingressPorts := map[int]bool{7687: true, 7474: cfg.EnableBrowser}
+
+	m := map[Cell]Expectation{}
+	for _, p := range probes {
+		for _, port := range MatrixPorts {
+			cell := Cell{Probe: p.Name, Port: port}
+			switch {
+			case p.Namespace == cfg.Namespace && port == 6362 && p.Labels["app.kubernetes.io/name"] == cfg.BackupPodLabel:
+				m[cell] = ExpectAllow
+			case p.Namespace == cfg.Namespace:
+				m[cell] = ExpectDeny // no egress from default-deny namespace
+			case !ingressPorts[port]:
+				m[cell] = ExpectDeny
+			case slices.Contains(cfg.AllowedNamespaces, p.Namespace):
+				m[cell] = ExpectAllow
+			case cfg.EnableExternalAccess:
+				m[cell] = ExpectEither
+			default:
+				m[cell] = ExpectDeny
+			}
+		}
+	}
+	return m
+}
+
+// Reachability is the observed outcome of a connection attempt.
+type Reachability int
+
+const (
+	// Blocked: the attempt timed out, i.e. the packets were dropped.
+	Blocked Reachability = iota
+	// Refused: the pod answered with a reset; the policy let it through but
+	// nothing listens on the port.
+	Refused
+	// Open: the connection was established.
+	Open
+)
+
+func (r Reachability) String() string {
+	switch r {
+	case Open:
+		return "open"
+	case Refused:
+		return "refused"
+	default:
+		return "blocked"
+	}
+}
+
+// Satisfies reports whether r matches e. A refused connection counts as
+// allowed: the policy passed it and the pod answered.
+func (r Reachability) Satisfies(e Expectation) bool {
+	switch e {
+	case ExpectAllow:
+		return r != Blocked
+	case ExpectDeny:
+		return r == Blocked
+	default:
+		return true
+	}
+}
+
+// probeScript prints the nc exit status after its output, so the exec itself
+// always succeeds and classifyProbe sees every outcome.
+func probeScript(ip string, port int) string {
+	return fmt.Sprintf("nc -z -w %d %s %d 2>&1; echo \"exit=$?\"", int(probeTimeout.Seconds()), ip, port)
+}
+
+// classifyProbe maps probeScript output to a Reachability.
+func classifyProbe(out string) (Reachability, error) {
+	out = strings.TrimSpace(out)
+	i := strings.LastIndex(out, "exit=")
+	if i < 0 {
+		return Blocked, fmt.Errorf("unexpected probe output %q", out)
+	}
+	switch {
+	case strings.TrimSpace(out[i+len("exit="):]) == "0":
+		return Open, nil
+	case strings.Contains(strings.ToLower(out[:i]), "refused"):
+		return Refused, nil
+	default:
+		return Blocked, nil
+	}
+}
+
+// MatrixResult holds the observed and expected outcome of every cell.
+type MatrixResult struct {
+	Probes   []Probe
+	Expected map[Cell]Expectation
+	Observed map[Cell]Reachability
+}
+
+// Mismatches returns the cells whose observation does not satisfy the
+// expectation, in probe/port order.
+func (r MatrixResult) Mismatches() []Cell {
+	var cells []Cell
+	for _, p := range r.Probes {
+		for _, port := range MatrixPorts {
+			c := Cell{Probe: p.Name, Port: port}
+			if !r.Observed[c].Satisfies(r.Expected[c]) {
+				cells = append(cells, c)
+			}
+		}
+	}
+	return cells
+}
+
+// NotEnforced reports whether every cell expected to be denied was reachable,
+// the signature of a CNI that ignores NetworkPolicy.
+func (r MatrixResult) NotEnforced() bool {
+	denied := 0
+	for c, e := range r.Expected {
+		if e != ExpectDeny {
+			continue
+		}
+		denied++
+		if r.Observed[c] == Blocked {
+			return false
+		}
+	}
+	return denied > 0
+}
+
+// Table renders the matrix as "observed/expected" per cell.
+func (r MatrixResult) Table() string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "probe\tnamespace")
+	for _, port := range MatrixPorts {
+		fmt.Fprintf(tw, "\t%d", port)
+	}
+	fmt.Fprintln(tw)
+	for _, p := range r.Probes {
+		fmt.Fprintf(tw, "%s\t%s", p.Name, p.Namespace)
+		for _, port := range MatrixPorts {
+			c := Cell{Probe: p.Name, Port: port}
+			mark := ""
+			if !r.Observed[c].Satisfies(r.Expected[c]) {
+				mark = " !"
+			}
+			fmt.Fprintf(tw, "\t%s/%s%s", r.Observed[c], r.Expected[c], mark)
+		}
+		fmt.Fprintln(tw)
+	}
+	_ = tw.Flush()
+	return b.String()
+}
+
+// Probe namespaces the deployment tests use for the matrix; the first is
+// passed to allowed_ingress_namespaces, the second is not.
+const (
+	ProbeAllowedNamespace  = "neo4j-probe-allowed"
+	ProbeUnlistedNamespace = "neo4j-probe-unlisted"
+)
+
+// RunNetworkPolicyMatrix launches the probes, probes the Neo4j pod
+// <instance>-0 on MatrixPorts and fails the test on any cell that does not
+// match ExpectedMatrix. Namespaces it has to create and all probe pods are
+// deleted when the test ends.
+func RunNetworkPolicyMatrix(t *testing.T, kubeconfigPath string, cfg PolicyConfig, allowedNS, unlistedNS string) MatrixResult {
+	t.Helper()
+
+	require.Contains(t, cfg.AllowedNamespaces, allowedNS, "allowed probe namespace must be in allowed_ingress_namespaces")
+	require.NotContains(t, cfg.AllowedNamespaces, unlistedNS, "unlisted probe namespace is in allowed_ingress_namespaces")
+
+	w := NewWaiter(t, kubeconfigPath, cfg.Namespace)
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Minute)
+	defer cancel()
+
+	target, err := w.ForPod(ctx, cfg.InstanceName+"-0", PodReady)
+	require.NoError(t, err, "Neo4j pod is not ready")
+	targetIP := target.Status.PodIP
+
+	probes := MatrixProbes(cfg, allowedNS, unlistedNS)
+	suffix := strings.ToLower(random.UniqueId())
+	podNames := map[string]string{}
+	for _, ns := range []string{allowedNS, unlistedNS} {
+		ensureNamespace(t, w, ns)
+	}
+	for _, p := range probes {
+		podNames[p.Name] = createProbePod(t, w, p, suffix)
+	}
+	for _, p := range probes {
+		pw := *w
+		pw.Namespace = p.Namespace
+		_, err := pw.ForPod(ctx, podNames[p.Name], PodReady)
+		require.NoError(t, err, "probe pod %s is not ready", p.Name)
+	}
+
+	result := MatrixResult{
+		Probes:   probes,
+		Expected: ExpectedMatrix(cfg, probes),
+		Observed: map[Cell]Reachability{},
+	}
+	for _, p := range probes {
+		options := k8s.NewKubectlOptions("", kubeconfigPath, p.Namespace)
+		for _, port := range MatrixPorts {
+			out, err := k8s.RunKubectlAndGetOutputE(t, options, "exec", podNames[p.Name], "--",
+				"sh", "-c", probeScript(targetIP, port))
+			require.NoError(t, err, "probe %s -> %d could not run: %s", p.Name, port, out)
+			reach, err := classifyProbe(out)
+			require.NoError(t, err)
+			result.Observed[Cell{Probe: p.Name, Port: port}] = reach
+		}
+	}
+
+	t.Logf("NetworkPolicy matrix for %s/%s (%s), observed/expected:\n%s",
+		cfg.Namespace, target.Name, targetIP, result.Table())
+	if result.NotEnforced() {
+		require.FailNow(t, "every connection expected to be denied got through; the cluster CNI does not "+
+			"enforce NetworkPolicy (on kind, install Calico with make calico-install)")
+	}
+	require.Empty(t, result.Mismatches(), "NetworkPolicy matrix mismatches (marked !)")
+	return result
+}
+
+// ensureNamespace creates ns unless it exists, deleting it at cleanup only
+// if it was created here.
+func ensureNamespace(t *testing.T, w *Waiter, ns string) {
+	t.Helper()
+
+	namespaces := w.Client.CoreV1().Namespaces()
+	_, err := namespaces.Create(t.Context(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}}, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return
+	}
+	require.NoError(t, err, "failed to create probe namespace %s", ns)
+	t.Cleanup(func() {
+		if err := namespaces.Delete(context.Background(), ns, metav1.DeleteOptions{}); err != nil {
+			t.Logf("WARNING: failed to delete probe namespace %s: %v", ns, err)
+		}
+	})
+}
+
+// createProbePod starts a sleeping probe pod and deletes it at cleanup.
+func createProbePod(t *testing.T, w *Waiter, p Probe, suffix string) string {
+	t.Helper()
+
+	pods := w.Client.CoreV1().Pods(p.Namespace)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("netpol-%s-%s", p.Name, suffix),
+			Namespace: p.Namespace,
+			Labels:    p.Labels,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			TerminationGracePeriodSeconds: new(int64),
+			Containers: []corev1.Container{{
+				Name:    "probe",
+				Image:   ProbeImage,
+				Command: []string{"sleep", "3600"},
+			}},
+		},
+	}
+	_, err := pods.Create(t.Context(), pod, metav1.CreateOptions{})
+	require.NoError(t, err, "failed to create probe pod %s/%s", p.Namespace, pod.Name)
+	t.Cleanup(func() {
+		if err := pods.Delete(context.Background(), pod.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			t.Logf("WARNING: failed to delete probe pod %s/%s: %v", p.Namespace, pod.Name, err)
+		}
+	})
+	return pod.Name
+}
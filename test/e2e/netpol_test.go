@@ -0,0 +1,114 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectedMatrix(t *testing.T) {
+	base := DefaultPolicyConfig
+	base.AllowedNamespaces = []string{"apps"}
+
+	noBrowser := base
+	noBrowser.EnableBrowser = false
+
+	external := base
+	external.EnableExternalAccess = true
+
+	cases := []struct {
+		name string
+		cfg  PolicyConfig
+		want map[Cell]Expectation
+	}{
+		{"defaults", base, map[Cell]Expectation{
+			{"same-namespace", 7687}: ExpectDeny, {"same-namespace", 7474}: ExpectDeny, {"same-namespace", 6362}: ExpectDeny,
+			{"backup-pod", 7687}: ExpectDeny, {"backup-pod", 7474}: ExpectDeny, {"backup-pod", 6362}: ExpectAllow,
+			{"allowed-namespace", 7687}: ExpectAllow, {"allowed-namespace", 7474}: ExpectAllow, {"allowed-namespace", 6362}: ExpectDeny,
+			{"unlisted-namespace", 7687}: ExpectDeny, {"unlisted-namespace", 7474}: ExpectDeny, {"unlisted-namespace", 6362}: ExpectDeny,
+		}},
+		{"browser disabled", noBrowser, map[Cell]Expectation{
+			{"allowed-namespace", 7687}: ExpectAllow, {"allowed-namespace", 7474}: ExpectDeny,
+			{"unlisted-namespace", 7474}: ExpectDeny,
+		}},
+		{"external access", external, map[Cell]Expectation{
+			{"allowed-namespace", 7687}:  ExpectAllow,
+			{"unlisted-namespace", 7687}: ExpectEither, {"unlisted-namespace", 7474}: ExpectEither,
+			{"unlisted-namespace", 6362}: ExpectDeny, {"same-namespace", 7687}: ExpectDeny,
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ExpectedMatrix(tc.cfg, MatrixProbes(tc.cfg, "apps", "elsewhere"))
+			require.Len(t, got, 4*len(MatrixPorts))
+			for cell, want := range tc.want {
+				require.Equal(t, want, got[cell], "cell %v", cell)
+			}
+		})
+	}
+}
+
+func TestMatrixProbes_BackupLabel(t *testing.T) {
+	cfg := DefaultPolicyConfig
+	cfg.BackupPodLabel = "custom-backup"
+	probes := MatrixProbes(cfg, "apps", "elsewhere")
+	require.Equal(t, "custom-backup", probes[1].Labels["app.kubernetes.io/name"])
+	require.Equal(t, cfg.Namespace, probes[1].Namespace)
+}
+
+func TestPolicyConfigFromVars(t *testing.T) {
+	cfg, err := PolicyConfigFromVars(map[string]any{
+		"neo4j_instance_name":        "neo4j-abc",
+		"enable_neo4j_browser":       false,
+		"allowed_ingress_namespaces": []any{"apps", "monitoring"},
+	}, DefaultPolicyConfig)
+	require.NoError(t, err)
+	require.Equal(t, "neo4j-abc", cfg.InstanceName)
+	require.Equal(t, "neo4j", cfg.Namespace)
+	require.False(t, cfg.EnableBrowser)
+	require.Equal(t, "neo4j-backup", cfg.BackupPodLabel)
+	require.Equal(t, []string{"apps", "monitoring"}, cfg.AllowedNamespaces)
+
+	_, err = PolicyConfigFromVars(map[string]any{"enable_external_access": "yes"}, DefaultPolicyConfig)
+	require.ErrorContains(t, err, "want bool")
+}
+
+func TestClassifyProbe(t *testing.T) {
+	cases := map[string]Reachability{
+		"exit=0": Open,
+		"nc: 10.0.0.5 (10.0.0.5:7474): Connection refused\nexit=1":   Refused,
+		"nc: 10.0.0.5 (10.0.0.5:6362): Connection timed out\nexit=1": Blocked,
+		"nc: timed out\nexit=1": Blocked,
+	}
+	for out, want := range cases {
+		got, err := classifyProbe(out)
+		require.NoError(t, err)
+		require.Equal(t, want, got, out)
+	}
+	_, err := classifyProbe("error: unable to upgrade connection")
+	require.Error(t, err)
+}
+
+func TestMatrixResult(t *testing.T) {
+	cfg := DefaultPolicyConfig
+	cfg.AllowedNamespaces = []string{"apps"}
+	probes := MatrixProbes(cfg, "apps", "elsewhere")
+	expected := ExpectedMatrix(cfg, probes)
+
+	enforced := MatrixResult{Probes: probes, Expected: expected, Observed: map[Cell]Reachability{}}
+	for cell, e := range expected {
+		if e == ExpectAllow {
+			enforced.Observed[cell] = Open
+		}
+	}
+	require.Empty(t, enforced.Mismatches())
+	require.False(t, enforced.NotEnforced())
+
+	leaky := MatrixResult{Probes: probes, Expected: expected, Observed: map[Cell]Reachability{}}
+	for cell := range expected {
+		leaky.Observed[cell] = Open
+	}
+	require.True(t, leaky.NotEnforced())
+	require.Contains(t, leaky.Mismatches(), Cell{"unlisted-namespace", 7687})
+	require.Contains(t, leaky.Table(), "open/deny !")
+}
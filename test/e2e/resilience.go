@@ -0,0 +1,221 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/k8s"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Environment variables for the pod-loss test.
+const (
+	NodeActionEnv    = "NEO4J_GKE_NODE_ACTION"
+	RecoveryLimitEnv = "NEO4J_GKE_RECOVERY_LIMIT"
+)
+
+// DefaultRecoveryLimit is how long the Neo4j pod may take to come back when
+// RecoveryLimitEnv is unset. It covers rescheduling, reattaching the data
+// volume and Neo4j's own startup.
+const DefaultRecoveryLimit = 10 * time.Minute
+
+// NodeAction is what happens to the Neo4j pod's node when the pod is lost.
+type NodeAction string
+
+// Node actions. With NodeCordon and NodeDrain the pod has to be rescheduled
+// onto another node, so its volume must detach and reattach there.
+const (
+	// NodeKeep deletes the pod and leaves the node schedulable.
+	NodeKeep NodeAction = "none"
+	// NodeCordon cordons the node, then deletes the pod.
+	NodeCordon NodeAction = "cordon"
+	// NodeDrain cordons the node, then evicts every pod of the namespace on
+	// it (through the Eviction API, so PodDisruptionBudgets apply).
+	NodeDrain NodeAction = "drain"
+)
+
+// ParseNodeAction parses a NodeAction; the empty string is NodeKeep.
+func ParseNodeAction(s string) (NodeAction, error) {
+	switch a := NodeAction(strings.ToLower(s)); a {
+	case "", NodeKeep:
+		return NodeKeep, nil
+	case NodeCordon, NodeDrain:
+		return a, nil
+	}
+	return "", fmt.Errorf("unknown node action %q (want %s, %s or %s)", s, NodeKeep, NodeCordon, NodeDrain)
+}
+
+// RecoverySettingsFromEnv reads NodeActionEnv and RecoveryLimitEnv.
+func RecoverySettingsFromEnv() (NodeAction, time.Duration, error) {
+	action, err := ParseNodeAction(os.Getenv(NodeActionEnv))
+	if err != nil {
+		return "", 0, fmt.Errorf("%s: %w", NodeActionEnv, err)
+	}
+	limit := DefaultRecoveryLimit
+	if v := os.Getenv(RecoveryLimitEnv); v != "" {
+		if limit, err = time.ParseDuration(v); err != nil {
+			return "", 0, fmt.Errorf("%s: %w", RecoveryLimitEnv, err)
+		}
+	}
+	return action, limit, nil
+}
+
+// Recovery is the measured outcome of a pod loss.
+type Recovery struct {
+	Pod     string
+	Action  NodeAction
+	OldNode string
+	NewNode string
+	// Volumes maps each of the pod's PVCs to its persistent volume, which
+	// must be the same before and after.
+	Volumes map[string]string
+	// Duration runs from the pod loss until the replacement pod is ready.
+	Duration time.Duration
+}
+
+func (r Recovery) String() string {
+	return fmt.Sprintf("pod %s recovered in %s (node action %s, node %s -> %s, volumes %v)",
+		r.Pod, r.Duration.Round(time.Second), r.Action, r.OldNode, r.NewNode, r.Volumes)
+}
+
+// Within fails when recovery took longer than limit.
+func (r Recovery) Within(limit time.Duration) error {
+	if r.Duration > limit {
+		return fmt.Errorf("pod %s took %s to recover, over the %s limit", r.Pod, r.Duration.Round(time.Second), limit)
+	}
+	return nil
+}
+
+// LosePod removes the StatefulSet pod name according to action, waits for its
+// replacement to be ready and checks the replacement reattached the same
+// volumes. A cordoned node is uncordoned before LosePod returns.
+func (w *Waiter) LosePod(ctx context.Context, name string, action NodeAction) (Recovery, error) {
+	pods := w.Client.CoreV1().Pods(w.Namespace)
+	pod, err := pods.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return Recovery{}, err
+	}
+	r := Recovery{Pod: name, Action: action, OldNode: pod.Spec.NodeName, Volumes: map[string]string{}}
+	for _, v := range pod.Spec.Volumes {
+		if v.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := w.Client.CoreV1().PersistentVolumeClaims(w.Namespace).Get(ctx, v.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+		if err != nil {
+			return r, err
+		}
+		r.Volumes[pvc.Name] = pvc.Spec.VolumeName
+	}
+
+	start := time.Now()
+	if action == NodeCordon || action == NodeDrain {
+		if err := w.setUnschedulable(ctx, r.OldNode, true); err != nil {
+			return r, fmt.Errorf("cordon %s: %w", r.OldNode, err)
+		}
+		defer func() {
+			if err := w.setUnschedulable(context.Background(), r.OldNode, false); err != nil {
+				w.emit(EventFailure, "Node", r.OldNode, fmt.Sprintf("uncordon failed: %v", err))
+			}
+		}()
+	}
+	if action == NodeDrain {
+		err = w.evictNamespacePods(ctx, r.OldNode)
+	} else {
+		err = pods.Delete(ctx, name, metav1.DeleteOptions{})
+	}
+	if err != nil {
+		return r, fmt.Errorf("remove pod %s: %w", name, err)
+	}
+	w.emit(EventStatus, "Pod", name, fmt.Sprintf("lost (node action %s)", action))
+
+	replacement, err := w.ForPod(ctx, name, podReplaced(pod.UID))
+	if err != nil {
+		return r, err
+	}
+	r.Duration = time.Since(start)
+	r.NewNode = replacement.Spec.NodeName
+
+	for claim, volume := range r.Volumes {
+		pvc, err := w.Client.CoreV1().PersistentVolumeClaims(w.Namespace).Get(ctx, claim, metav1.GetOptions{})
+		if err != nil {
+			return r, err
+		}
+		if pvc.Status.Phase != corev1.ClaimBound || pvc.Spec.VolumeName != volume {
+			return r, fmt.Errorf("PVC %s is %s on volume %q, was bound to %q", claim, pvc.Status.Phase, pvc.Spec.VolumeName, volume)
+		}
+	}
+	return r, nil
+}
+
+// podReplaced is PodReady for a pod other than the one with uid.
+func podReplaced(uid types.UID) func(*corev1.Pod) (bool, error) {
+	return func(pod *corev1.Pod) (bool, error) {
+		if pod.UID == uid {
+			return false, nil
+		}
+		return PodReady(pod)
+	}
+}
+
+func (w *Waiter) setUnschedulable(ctx context.Context, node string, unschedulable bool) error {
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	_, err := w.Client.CoreV1().Nodes().Patch(ctx, node, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// evictNamespacePods evicts the waiter namespace's pods on node.
+func (w *Waiter) evictNamespacePods(ctx context.Context, node string) error {
+	pods := w.Client.CoreV1().Pods(w.Namespace)
+	list, err := pods.List(ctx, metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("spec.nodeName", node).String()})
+	if err != nil {
+		return err
+	}
+	for _, p := range list.Items {
+		err := pods.EvictV1(ctx, &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: p.Name, Namespace: p.Namespace}})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("evict %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// RequirePodLossRecovery seeds marker nodes, loses <instance>-0 with action
+// and fails the test unless the replacement is ready within limit on the
+// same volumes with every marker intact. The measured recovery is logged
+// and returned.
+func RequirePodLossRecovery(t *testing.T, options *k8s.KubectlOptions, w *Waiter, instance, boltService, password string, action NodeAction, limit time.Duration) Recovery {
+	t.Helper()
+
+	run := strings.ToLower(random.UniqueId())
+	const markers = 100
+	before := OpenBolt(t, options, boltService, password)
+	require.NoError(t, SeedMarkers(t.Context(), before, run, markers))
+
+	// Leave room past the limit so an over-limit recovery is measured and
+	// reported rather than cut off.
+	ctx, cancel := context.WithTimeout(t.Context(), 2*limit)
+	defer cancel()
+	t.Logf("Losing pod %s-0 (node action %s, limit %s)...", instance, action, limit)
+	r, err := w.LosePod(ctx, instance+"-0", action)
+	require.NoError(t, err, "pod %s-0 did not recover", instance)
+	t.Logf("Recovery: %s", r)
+	require.NoError(t, r.Within(limit))
+
+	// The old port-forward pointed at the lost pod.
+	after := OpenBolt(t, options, boltService, password)
+	n, err := CountMarkers(t.Context(), after, "neo4j", run)
+	require.NoError(t, err)
+	require.Equal(t, int64(markers), n, "marker nodes lost with the pod")
+	return r
+}
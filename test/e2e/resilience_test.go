@@ -0,0 +1,134 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func statefulPod(uid types.UID, node string, ready bool) *corev1.Pod {
+	p := pod("neo4j-0", corev1.PodRunning, ready)
+	p.UID = uid
+	p.Spec.NodeName = node
+	p.Spec.Volumes = []corev1.Volume{{
+		Name:         "data",
+		VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-neo4j-0"}},
+	}}
+	return p
+}
+
+func TestWaiter_LosePodWithCordon(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data-neo4j-0", Namespace: waitNS},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}}
+	w, client, rec := newTestWaiter(statefulPod("old", "node-a", true), pvc, node)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go func() {
+		<-rec.watching
+		cordoned, err := client.CoreV1().Nodes().Get(ctx, "node-a", metav1.GetOptions{})
+		if err != nil || !cordoned.Spec.Unschedulable {
+			t.Errorf("node-a not cordoned while the pod is lost: %v", err)
+		}
+		if _, err := client.CoreV1().Pods(waitNS).Create(ctx, statefulPod("new", "node-b", true), metav1.CreateOptions{}); err != nil {
+			t.Errorf("create replacement pod: %v", err)
+		}
+	}()
+
+	r, err := w.LosePod(ctx, "neo4j-0", NodeCordon)
+	require.NoError(t, err)
+	require.Equal(t, "node-a", r.OldNode)
+	require.Equal(t, "node-b", r.NewNode)
+	require.Equal(t, map[string]string{"data-neo4j-0": "pv-1"}, r.Volumes)
+	require.Positive(t, r.Duration)
+	require.NoError(t, r.Within(time.Minute))
+
+	uncordoned, err := client.CoreV1().Nodes().Get(ctx, "node-a", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.False(t, uncordoned.Spec.Unschedulable, "node must be uncordoned after recovery")
+
+	var deleted bool
+	for _, a := range client.Actions() {
+		if d, ok := a.(clienttesting.DeleteAction); ok && d.GetName() == "neo4j-0" {
+			deleted = true
+		}
+	}
+	require.True(t, deleted, "pod was not deleted")
+}
+
+func TestWaiter_LosePodDetectsVolumeSwap(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data-neo4j-0", Namespace: waitNS},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+	w, client, rec := newTestWaiter(statefulPod("old", "node-a", true), pvc)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go func() {
+		<-rec.watching
+		swapped := pvc.DeepCopy()
+		swapped.Spec.VolumeName = "pv-2"
+		if _, err := client.CoreV1().PersistentVolumeClaims(waitNS).Update(ctx, swapped, metav1.UpdateOptions{}); err != nil {
+			t.Errorf("update pvc: %v", err)
+		}
+		if _, err := client.CoreV1().Pods(waitNS).Create(ctx, statefulPod("new", "node-a", true), metav1.CreateOptions{}); err != nil {
+			t.Errorf("create replacement pod: %v", err)
+		}
+	}()
+
+	_, err := w.LosePod(ctx, "neo4j-0", NodeKeep)
+	require.ErrorContains(t, err, `was bound to "pv-1"`)
+}
+
+func TestPodReplaced(t *testing.T) {
+	pred := podReplaced("old")
+	done, err := pred(statefulPod("old", "n", true))
+	require.NoError(t, err)
+	require.False(t, done, "the original pod is not a replacement")
+
+	done, err = pred(statefulPod("new", "n", false))
+	require.NoError(t, err)
+	require.False(t, done)
+
+	done, err = pred(statefulPod("new", "n", true))
+	require.NoError(t, err)
+	require.True(t, done)
+}
+
+func TestRecoverySettingsFromEnv(t *testing.T) {
+	t.Setenv(NodeActionEnv, "")
+	t.Setenv(RecoveryLimitEnv, "")
+	action, limit, err := RecoverySettingsFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, NodeKeep, action)
+	require.Equal(t, DefaultRecoveryLimit, limit)
+
+	t.Setenv(NodeActionEnv, "Drain")
+	t.Setenv(RecoveryLimitEnv, "4m")
+	action, limit, err = RecoverySettingsFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, NodeDrain, action)
+	require.Equal(t, 4*time.Minute, limit)
+
+	t.Setenv(NodeActionEnv, "reboot")
+	_, _, err = RecoverySettingsFromEnv()
+	require.ErrorContains(t, err, "unknown node action")
+}
+
+func TestRecovery_Within(t *testing.T) {
+	r := Recovery{Pod: "neo4j-0", Duration: 3 * time.Minute}
+	require.NoError(t, r.Within(5*time.Minute))
+	require.ErrorContains(t, r.Within(2*time.Minute), "over the 2m0s limit")
+}
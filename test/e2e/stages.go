@@ -0,0 +1,241 @@
+package e2e
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	testStructure "github.com/gruntwork-io/terratest/modules/test-structure"
+	"github.com/stretchr/testify/require"
+
+	testhelpers "github.com/simon-lentz/neo4j_gke/test"
+)
+
+// Stage names used by the staged e2e tests. Any stage can be skipped by
+// setting SKIP_<stage> (terratest convention), e.g.:
+//
+//	SKIP_teardown=true                      # keep everything up after the run
+//	SKIP_vpc=true SKIP_gke=true SKIP_sa=true SKIP_bucket=true SKIP_teardown=true
+//	                                        # re-run only app + verify
+const (
+	StageVPC        = "vpc"
+	StageGKE        = "gke"
+	StageSA         = "sa"
+	StageBucket     = "bucket"
+	StageApp        = "app"
+	StageVerify     = "verify"
+	StageResilience = "resilience"
+	StageBackup     = "backup"
+	StageRestore    = "restore"
+	StageTeardown   = "teardown"
+)
+
+// provisionStages are the stages that own terraform state, in apply order.
+// Teardown destroys them in reverse.
+var provisionStages = []string{StageVPC, StageGKE, StageSA, StageBucket, StageApp, StageRestore}
+
+// dataStages save outputs but own no state; teardown only removes their data.
+var dataStages = []string{StageResilience, StageBackup}
+
+// stageModules maps each provisioning stage to the module it applies, for
+// the time budget (see testhelpers.ModuleEstimates).
+var stageModules = map[string]string{
+	StageVPC:     "vpc",
+	StageGKE:     "gke",
+	StageSA:      "service_accounts",
+	StageBucket:  "backup_bucket",
+	StageApp:     "neo4j_app/tests/e2e",
+	StageRestore: "neo4j_app/tests/e2e",
+}
+
+// WorkDirEnv names the environment variable pointing at a persistent work
+// directory. Saved terraform.Options, module copies (and therefore state) and
+// stage outputs live there, so a later run can resume from any stage.
+const WorkDirEnv = "NEO4J_GKE_E2E_WORK_DIR"
+
+// Workspace persists per-stage terraform options and outputs between runs.
+type Workspace struct {
+	t      *testing.T
+	Dir    string
+	budget *testhelpers.Budget
+}
+
+// NewWorkspace opens the e2e work directory. Without NEO4J_GKE_E2E_WORK_DIR a
+// per-test temp dir is used, which means the run cannot be resumed later.
+func NewWorkspace(t *testing.T) *Workspace {
+	t.Helper()
+
+	dir := strings.TrimSpace(os.Getenv(WorkDirEnv))
+	if dir == "" {
+		dir = t.TempDir()
+		if testStructure.SkipStageEnvVarSet() {
+			t.Logf("WARNING: SKIP_* is set but %s is not; stage data will not survive this run", WorkDirEnv)
+		}
+	} else {
+		require.NoError(t, os.MkdirAll(dir, 0o755), "failed to create work dir %s", dir)
+	}
+	t.Logf("e2e work dir: %s", dir)
+
+	return &Workspace{t: t, Dir: dir}
+}
+
+// stageDir returns the directory holding a stage's saved data.
+func (w *Workspace) stageDir(stage string) string {
+	return filepath.Join(w.Dir, stage)
+}
+
+// HasOptions reports whether a stage has saved terraform options.
+func (w *Workspace) HasOptions(stage string) bool {
+	return testStructure.IsTestDataPresent(w.t, testStructure.FormatTestDataPath(w.stageDir(stage), "TerraformOptions.json"))
+}
+
+// SaveOptions persists a stage's terraform options.
+func (w *Workspace) SaveOptions(stage string, options *terraform.Options) {
+	w.t.Helper()
+	testStructure.SaveTerraformOptions(w.t, w.stageDir(stage), options)
+}
+
+// LoadOptions loads a stage's terraform options, failing with a hint if the
+// stage has never run in this work dir.
+func (w *Workspace) LoadOptions(stage string) *terraform.Options {
+	w.t.Helper()
+	require.Truef(w.t, w.HasOptions(stage),
+		"stage %q has no saved data in %s; unset SKIP_%s or point %s at the run that created it",
+		stage, w.Dir, stage, WorkDirEnv)
+	return testStructure.LoadTerraformOptions(w.t, w.stageDir(stage))
+}
+
+// OptionsOrCreate returns the saved options for a stage, or builds them with
+// create and saves them before anything is applied. Saving first means
+// teardown can still destroy a stage whose apply failed half-way.
+func (w *Workspace) OptionsOrCreate(stage string, create func() *terraform.Options) *terraform.Options {
+	w.t.Helper()
+	if w.HasOptions(stage) {
+		w.t.Logf("Reusing saved terraform options for stage %q", stage)
+		return w.LoadOptions(stage)
+	}
+	options := create()
+	w.SaveOptions(stage, options)
+	return options
+}
+
+// StartBudget starts the run's time budget. The test is skipped unless every
+// provisioning stage that is not skipped fits before the deadline; stages
+// provisioned by an earlier run count as applied and have their teardown time
+// reserved now (unless teardown itself is skipped).
+func (w *Workspace) StartBudget() *testhelpers.Budget {
+	w.t.Helper()
+
+	var modules []string
+	for _, stage := range provisionStages {
+		if !stageSkipped(stage) {
+			modules = append(modules, stageModules[stage])
+		}
+	}
+	w.budget = testhelpers.NewBudget(w.t, modules...)
+	for _, stage := range provisionStages {
+		if stageSkipped(stage) && w.HasOptions(stage) {
+			w.budget.MarkApplied()
+			if !stageSkipped(StageTeardown) {
+				w.budget.Reserve(stageModules[stage])
+			}
+		}
+	}
+	return w.budget
+}
+
+// Apply reserves teardown time for a stage and applies it if the budget
+// allows; otherwise the test ends and goes straight to teardown.
+func (w *Workspace) Apply(stage string, options *terraform.Options) {
+	w.t.Helper()
+	require.NotNil(w.t, w.budget, "call StartBudget before Apply")
+
+	w.budget.Reserve(stageModules[stage])
+	w.budget.Apply(stageModules[stage], options)
+}
+
+// stageSkipped reports whether SKIP_<stage> is set (terratest convention).
+func stageSkipped(stage string) bool {
+	return os.Getenv("SKIP_"+stage) != ""
+}
+
+// modulesDir holds the module copies (and therefore their local state).
+func (w *Workspace) modulesDir() string {
+	return filepath.Join(w.Dir, "modules")
+}
+
+// CopyModule copies a module into the work dir so its state persists with it.
+func (w *Workspace) CopyModule(moduleRelativePath string) string {
+	w.t.Helper()
+	require.NoError(w.t, os.MkdirAll(w.modulesDir(), 0o755))
+	return testhelpers.CopyModuleToDir(w.t, moduleRelativePath, w.modulesDir())
+}
+
+// SaveOutput persists a stage output value.
+func (w *Workspace) SaveOutput(stage, name, value string) {
+	w.t.Helper()
+	testStructure.SaveString(w.t, w.stageDir(stage), name, value)
+}
+
+// SaveOutputs reads the named terraform outputs of a stage and persists them.
+func (w *Workspace) SaveOutputs(stage string, options *terraform.Options, names ...string) {
+	w.t.Helper()
+	for _, name := range names {
+		value, err := terraform.OutputE(w.t, options, name)
+		require.NoErrorf(w.t, err, "failed to get %s output", name)
+		w.SaveOutput(stage, name, value)
+	}
+}
+
+// Output loads a persisted stage output value.
+func (w *Workspace) Output(stage, name string) string {
+	w.t.Helper()
+	return testStructure.LoadString(w.t, w.stageDir(stage), name)
+}
+
+// Value returns a run-wide value (e.g. the resource name suffix), generating and
+// persisting it on first use so resumed runs address the same resources.
+func (w *Workspace) Value(name string, generate func() string) string {
+	w.t.Helper()
+	path := testStructure.FormatTestDataPath(w.Dir, name+".json")
+	if testStructure.IsTestDataPresent(w.t, path) {
+		return testStructure.LoadString(w.t, w.Dir, name)
+	}
+	value := generate()
+	testStructure.SaveString(w.t, w.Dir, name, value)
+	return value
+}
+
+// Teardown destroys every stage that has saved options, newest first. When all
+// destroys succeed the saved stage data is removed so the next run starts
+// fresh; otherwise it is kept so the destroy can be retried by re-running with
+// every stage but teardown skipped.
+func (w *Workspace) Teardown() {
+	w.t.Helper()
+
+	clean := true
+	for i := len(provisionStages) - 1; i >= 0; i-- {
+		stage := provisionStages[i]
+		if !w.HasOptions(stage) {
+			continue
+		}
+		w.t.Logf("Tearing down stage %q...", stage)
+		if err := testhelpers.RunTerraformCleanup(w.t, w.LoadOptions(stage)); err != nil {
+			clean = false
+		}
+	}
+
+	if !clean {
+		w.t.Logf("Teardown incomplete; keeping work dir %s so it can be retried", w.Dir)
+		return
+	}
+
+	for _, stage := range slices.Concat(provisionStages, dataStages) {
+		require.NoError(w.t, os.RemoveAll(w.stageDir(stage)))
+	}
+	require.NoError(w.t, os.RemoveAll(w.modulesDir()))
+	testStructure.CleanupTestDataFolder(w.t, w.Dir)
+}
@@ -0,0 +1,118 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v6/neo4j"
+)
+
+// Chart versions for the upgrade test. The "to" version defaults to the
+// neo4j_app module default, so a chart bump is tested by setting only the
+// "from" version to the one currently in dev.
+const (
+	ChartUpgradeFromEnv = "NEO4J_GKE_UPGRADE_FROM_VERSION"
+	ChartUpgradeToEnv   = "NEO4J_GKE_UPGRADE_TO_VERSION"
+)
+
+// UpgradeFixture is the data written before a chart upgrade and checked
+// after it: marker nodes plus documents behind a vector index.
+type UpgradeFixture struct {
+	Run         string
+	Markers     int
+	VectorIndex string
+}
+
+// upgradeDocs are embedded into the vector index; each is nearest to itself.
+var upgradeDocs = []map[string]any{
+	{"id": "x", "embedding": []float64{1, 0, 0}},
+	{"id": "y", "embedding": []float64{0, 1, 0}},
+	{"id": "z", "embedding": []float64{0, 0, 1}},
+}
+
+// Seed writes the fixture and waits for the vector index to come online.
+func (f UpgradeFixture) Seed(ctx context.Context, driver neo4j.Driver) error {
+	if err := SeedMarkers(ctx, driver, f.Run, f.Markers); err != nil {
+		return fmt.Errorf("seed markers: %w", err)
+	}
+	steps := []struct {
+		query  string
+		params map[string]any
+	}{
+		{fmt.Sprintf("CREATE VECTOR INDEX `%s` IF NOT EXISTS FOR (d:UpgradeDoc) ON d.embedding "+
+			"OPTIONS {indexConfig: {`vector.dimensions`: 3, `vector.similarity_function`: 'cosine'}}", f.VectorIndex), nil},
+		{"UNWIND $docs AS doc CREATE (:UpgradeDoc {run: $run, id: doc.id, embedding: vector(doc.embedding, 3, FLOAT32)})",
+			map[string]any{"run": f.Run, "docs": upgradeDocs}},
+		{"CALL db.awaitIndexes(300)", nil},
+	}
+	for _, s := range steps {
+		if _, err := neo4j.ExecuteQuery(ctx, driver, s.query, s.params, neo4j.EagerResultTransformer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Checks are the assertions that the fixture, its index and the Cypher 25
+// default survived an upgrade.
+func (f UpgradeFixture) Checks() []CypherCheck {
+	return []CypherCheck{
+		{
+			Name:   "marker nodes",
+			Query:  "MATCH (m:BackupMarker {run: $run}) RETURN count(m) AS n",
+			Params: map[string]any{"run": f.Run},
+			Verify: expectValue("n", int64(f.Markers)),
+		},
+		{
+			Name:   "vector index state",
+			Query:  "SHOW VECTOR INDEXES YIELD name, state WHERE name = $name RETURN state",
+			Params: map[string]any{"name": f.VectorIndex},
+			Verify: expectValue("state", "ONLINE"),
+		},
+		{
+			Name: "vector index query",
+			Query: "CALL db.index.vector.queryNodes($name, 10, [0.9, 0.1, 0.0]) YIELD node, score " +
+				"WHERE node.run = $run RETURN node.id AS id ORDER BY score DESC LIMIT 1",
+			Params: map[string]any{"name": f.VectorIndex, "run": f.Run},
+			Verify: expectValue("id", "x"),
+		},
+		{
+			Name:   "default language",
+			Query:  "SHOW SETTINGS YIELD name, value WHERE name = 'db.query.default_language' RETURN value",
+			Verify: expectValue("value", "CYPHER_25"),
+		},
+	}
+}
+
+// KernelVersion returns the version the Neo4j Kernel component reports.
+func KernelVersion(ctx context.Context, driver neo4j.Driver) (string, error) {
+	result, err := neo4j.ExecuteQuery(ctx, driver,
+		"CALL dbms.components() YIELD name, versions WHERE name = 'Neo4j Kernel' RETURN versions[0] AS version",
+		nil, neo4j.EagerResultTransformer)
+	if err != nil {
+		return "", err
+	}
+	v, err := singleValue(result, "version")
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("version is %T, want string", v)
+	}
+	return s, nil
+}
+
+// expectValue verifies a single-record result whose key column equals want.
+func expectValue(key string, want any) func(*neo4j.EagerResult) error {
+	return func(result *neo4j.EagerResult) error {
+		got, err := singleValue(result, key)
+		if err != nil {
+			return err
+		}
+		if got != want {
+			return fmt.Errorf("%s = %v (%T), want %v", key, got, got, want)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,33 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeFixture_Checks(t *testing.T) {
+	f := UpgradeFixture{Run: "r1", Markers: 50, VectorIndex: "upgrade_embedding"}
+	checks := map[string]CypherCheck{}
+	for _, c := range f.Checks() {
+		checks[c.Name] = c
+	}
+
+	markers := checks["marker nodes"]
+	require.Equal(t, map[string]any{"run": "r1"}, markers.Params)
+	require.NoError(t, markers.Verify(eager([]string{"n"}, []any{int64(50)})))
+	require.ErrorContains(t, markers.Verify(eager([]string{"n"}, []any{int64(49)})), "n = 49")
+
+	index := checks["vector index state"]
+	require.NoError(t, index.Verify(eager([]string{"state"}, []any{"ONLINE"})))
+	require.ErrorContains(t, index.Verify(eager([]string{"state"}, []any{"POPULATING"})), "want ONLINE")
+	require.ErrorContains(t, index.Verify(eager([]string{"state"})), "expected 1 record")
+
+	query := checks["vector index query"]
+	require.Equal(t, map[string]any{"name": "upgrade_embedding", "run": "r1"}, query.Params)
+	require.NoError(t, query.Verify(eager([]string{"id"}, []any{"x"})))
+
+	language := checks["default language"]
+	require.NoError(t, language.Verify(eager([]string{"value"}, []any{"CYPHER_25"})))
+	require.ErrorContains(t, language.Verify(eager([]string{"value"}, []any{"CYPHER_5"})), "want CYPHER_25")
+}
@@ -0,0 +1,509 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Waiter blocks until Kubernetes objects satisfy a predicate, using watches
+// rather than polling. Every wait re-reads the object on start, whenever the
+// watch closes and every Resync, so missed events only cost latency. Pods
+// (and the pods of a StatefulSet) stuck in a fatal waiting state such as
+// CrashLoopBackOff fail the wait immediately with their last logs attached.
+//
+// Usage:
+//
+//	w := NewWaiter(t, kubeconfigPath, "neo4j")
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+//	defer cancel()
+//	_, err := w.ForStatefulSet(ctx, "neo4j-abc123", StatefulSetReady)
+type Waiter struct {
+	Client    kubernetes.Interface
+	Namespace string
+	// Progress receives an event whenever a watch starts, an object's status
+	// changes, or a wait fails. Nil discards events.
+	Progress func(Event)
+	// Resync is how often the object is re-read while watching (default 30s).
+	Resync time.Duration
+	// LogTailLines is how many log lines a ContainerFailureError carries
+	// (default 50).
+	LogTailLines int64
+}
+
+// EventType classifies progress events.
+type EventType string
+
+// Progress event types.
+const (
+	EventWatch   EventType = "watch"
+	EventStatus  EventType = "status"
+	EventFailure EventType = "failure"
+)
+
+// Event is a progress report from a wait.
+type Event struct {
+	Time    time.Time
+	Type    EventType
+	Kind    string
+	Name    string
+	Message string
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%s %s/%s: %s", e.Type, e.Kind, e.Name, e.Message)
+}
+
+// FatalWaitingReasons are container waiting reasons that will not resolve
+// without a change to the deployment.
+var FatalWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+}
+
+// ContainerFailureError reports a container stuck in a fatal waiting state.
+type ContainerFailureError struct {
+	Pod       string
+	Container string
+	Reason    string
+	Message   string
+	// Logs are the last lines of the container's previous (for crash loops)
+	// or current log, or a note on why they could not be fetched.
+	Logs string
+}
+
+func (e *ContainerFailureError) Error() string {
+	msg := fmt.Sprintf("pod %s container %s is in %s", e.Pod, e.Container, e.Reason)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Logs != "" {
+		msg += "\n--- last container logs ---\n" + strings.TrimRight(e.Logs, "\n")
+	}
+	return msg
+}
+
+// NewWaiter builds a Waiter for namespace from a kubeconfig file and logs
+// progress events to t.
+func NewWaiter(t *testing.T, kubeconfigPath, namespace string) *Waiter {
+	t.Helper()
+
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	require.NoError(t, err, "failed to load kubeconfig %s", kubeconfigPath)
+	client, err := kubernetes.NewForConfig(config)
+	require.NoError(t, err, "failed to create Kubernetes client")
+
+	return &Waiter{
+		Client:    client,
+		Namespace: namespace,
+		Progress:  func(e Event) { t.Logf("[wait] %s", e) },
+	}
+}
+
+// --- predicates ---
+
+// PodReady is true once the pod is Running with the Ready condition True.
+func PodReady(pod *corev1.Pod) (bool, error) {
+	if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
+		return false, fmt.Errorf("pod %s terminated with phase %s", pod.Name, pod.Status.Phase)
+	}
+	return pod.Status.Phase == corev1.PodRunning && podConditionTrue(pod, corev1.PodReady), nil
+}
+
+// PodSucceeded is true once a run-to-completion pod has exited 0; a failed
+// pod fails the wait.
+func PodSucceeded(pod *corev1.Pod) (bool, error) {
+	if pod.Status.Phase == corev1.PodFailed {
+		return false, fmt.Errorf("pod %s failed: %s", pod.Name, pod.Status.Message)
+	}
+	return pod.Status.Phase == corev1.PodSucceeded, nil
+}
+
+// StatefulSetReady is true once the controller has observed the latest spec
+// and every desired replica is updated and ready.
+func StatefulSetReady(sts *appsv1.StatefulSet) (bool, error) {
+	want := int32(1)
+	if sts.Spec.Replicas != nil {
+		want = *sts.Spec.Replicas
+	}
+	return sts.Status.ObservedGeneration >= sts.Generation &&
+		sts.Status.ReadyReplicas == want &&
+		sts.Status.UpdatedReplicas == want, nil
+}
+
+// StatefulSetRolledOut is StatefulSetReady plus a finished rolling update:
+// every pod runs the current revision, so pods started from the previous
+// spec cannot satisfy it.
+func StatefulSetRolledOut(sts *appsv1.StatefulSet) (bool, error) {
+	ready, err := StatefulSetReady(sts)
+	return ready && sts.Status.UpdateRevision != "" && sts.Status.CurrentRevision == sts.Status.UpdateRevision, err
+}
+
+// PVCBound is true once the claim is bound; a lost claim fails the wait.
+func PVCBound(pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Status.Phase == corev1.ClaimLost {
+		return false, fmt.Errorf("PVC %s lost its volume %s", pvc.Name, pvc.Spec.VolumeName)
+	}
+	return pvc.Status.Phase == corev1.ClaimBound, nil
+}
+
+// ServiceHasClusterIP is true once a ClusterIP has been allocated.
+func ServiceHasClusterIP(svc *corev1.Service) (bool, error) {
+	return svc.Spec.ClusterIP != "" && svc.Spec.ClusterIP != corev1.ClusterIPNone, nil
+}
+
+// ServiceLoadBalancerReady is true once a LoadBalancer service has an ingress
+// IP or hostname.
+func ServiceLoadBalancerReady(svc *corev1.Service) (bool, error) {
+	for _, ing := range svc.Status.LoadBalancer.Ingress {
+		if ing.IP != "" || ing.Hostname != "" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// --- waits ---
+
+// ForPod waits for the named pod to satisfy pred.
+func (w *Waiter) ForPod(ctx context.Context, name string, pred func(*corev1.Pod) (bool, error)) (*corev1.Pod, error) {
+	pods := w.Client.CoreV1().Pods(w.Namespace)
+	return waitFor(ctx, w, resource[*corev1.Pod]{
+		kind:    "Pod",
+		get:     func(ctx context.Context) (*corev1.Pod, error) { return pods.Get(ctx, name, metav1.GetOptions{}) },
+		watch:   pods.Watch,
+		summary: podSummary,
+		check:   w.podFailure,
+	}, name, pred)
+}
+
+// ForStatefulSet waits for the named StatefulSet to satisfy pred, failing
+// early if one of its pods is in a fatal waiting state.
+func (w *Waiter) ForStatefulSet(ctx context.Context, name string, pred func(*appsv1.StatefulSet) (bool, error)) (*appsv1.StatefulSet, error) {
+	sets := w.Client.AppsV1().StatefulSets(w.Namespace)
+	return waitFor(ctx, w, resource[*appsv1.StatefulSet]{
+		kind: "StatefulSet",
+		get: func(ctx context.Context) (*appsv1.StatefulSet, error) {
+			return sets.Get(ctx, name, metav1.GetOptions{})
+		},
+		watch:   sets.Watch,
+		summary: statefulSetSummary,
+		check:   w.statefulSetPodFailure,
+	}, name, pred)
+}
+
+// ForPVC waits for the named PersistentVolumeClaim to satisfy pred.
+func (w *Waiter) ForPVC(ctx context.Context, name string, pred func(*corev1.PersistentVolumeClaim) (bool, error)) (*corev1.PersistentVolumeClaim, error) {
+	claims := w.Client.CoreV1().PersistentVolumeClaims(w.Namespace)
+	return waitFor(ctx, w, resource[*corev1.PersistentVolumeClaim]{
+		kind: "PersistentVolumeClaim",
+		get: func(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+			return claims.Get(ctx, name, metav1.GetOptions{})
+		},
+		watch: claims.Watch,
+		summary: func(pvc *corev1.PersistentVolumeClaim) string {
+			return "phase=" + string(pvc.Status.Phase)
+		},
+	}, name, pred)
+}
+
+// ForService waits for the named Service to satisfy pred.
+func (w *Waiter) ForService(ctx context.Context, name string, pred func(*corev1.Service) (bool, error)) (*corev1.Service, error) {
+	services := w.Client.CoreV1().Services(w.Namespace)
+	return waitFor(ctx, w, resource[*corev1.Service]{
+		kind: "Service",
+		get: func(ctx context.Context) (*corev1.Service, error) {
+			return services.Get(ctx, name, metav1.GetOptions{})
+		},
+		watch:   services.Watch,
+		summary: serviceSummary,
+	}, name, pred)
+}
+
+// --- machinery ---
+
+type object interface {
+	metav1.Object
+	runtime.Object
+}
+
+// resource adapts one object kind to waitFor.
+type resource[T object] struct {
+	kind    string
+	get     func(ctx context.Context) (T, error)
+	watch   func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	summary func(T) string
+	// check returns a non-nil error when the object can no longer become ready.
+	check func(ctx context.Context, obj T) error
+}
+
+func waitFor[T object](ctx context.Context, w *Waiter, r resource[T], name string, pred func(T) (bool, error)) (T, error) {
+	var (
+		zero    T
+		last    string
+		lastErr error
+	)
+
+	// evaluate reports progress and returns done or a terminal error.
+	evaluate := func(obj T) (bool, error) {
+		if s := r.summary(obj); s != last {
+			last = s
+			w.emit(EventStatus, r.kind, name, s)
+		}
+		if r.check != nil {
+			if err := r.check(ctx, obj); err != nil {
+				w.emit(EventFailure, r.kind, name, err.Error())
+				return false, err
+			}
+		}
+		done, err := pred(obj)
+		if err != nil {
+			w.emit(EventFailure, r.kind, name, err.Error())
+		}
+		return done, err
+	}
+
+	timeout := func() (T, error) {
+		status := last
+		if status == "" {
+			status = "never observed"
+		}
+		err := fmt.Errorf("waiting for %s %s/%s: %w (last status: %s)", r.kind, w.Namespace, name, ctx.Err(), status)
+		if lastErr != nil {
+			err = fmt.Errorf("%w; last API error: %v", err, lastErr)
+		}
+		return zero, err
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return timeout()
+		}
+
+		rv := ""
+		obj, err := r.get(ctx)
+		switch {
+		case apierrors.IsNotFound(err):
+			if last != "not found" {
+				last = "not found"
+				w.emit(EventStatus, r.kind, name, last)
+			}
+		case err != nil:
+			lastErr = err
+		default:
+			rv = obj.GetResourceVersion()
+			done, err := evaluate(obj)
+			if err != nil {
+				return obj, err
+			}
+			if done {
+				return obj, nil
+			}
+		}
+
+		watcher, err := r.watch(ctx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+			ResourceVersion: rv,
+		})
+		if err != nil {
+			lastErr = err
+			select {
+			case <-ctx.Done():
+				return timeout()
+			case <-time.After(w.resync()):
+			}
+			continue
+		}
+		w.emit(EventWatch, r.kind, name, "watching")
+
+		obj, done, err := drain(ctx, w, watcher, name, evaluate)
+		watcher.Stop()
+		if err != nil || done {
+			return obj, err
+		}
+	}
+}
+
+// drain consumes watch events until the object is done, fails, the watch
+// closes or the resync interval passes.
+func drain[T object](ctx context.Context, w *Waiter, watcher watch.Interface, name string, evaluate func(T) (bool, error)) (T, bool, error) {
+	var zero T
+	resync := time.NewTimer(w.resync())
+	defer resync.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return zero, false, nil
+		case <-resync.C:
+			return zero, false, nil
+		case ev, ok := <-watcher.ResultChan():
+			if !ok || ev.Type == watch.Error {
+				return zero, false, nil
+			}
+			obj, ok := ev.Object.(T)
+			if !ok || obj.GetName() != name || ev.Type == watch.Deleted {
+				continue
+			}
+			done, err := evaluate(obj)
+			if err != nil || done {
+				return obj, done, err
+			}
+		}
+	}
+}
+
+func (w *Waiter) emit(typ EventType, kind, name, msg string) {
+	if w.Progress != nil {
+		w.Progress(Event{Time: time.Now(), Type: typ, Kind: kind, Name: name, Message: msg})
+	}
+}
+
+func (w *Waiter) resync() time.Duration {
+	if w.Resync > 0 {
+		return w.Resync
+	}
+	return 30 * time.Second
+}
+
+// podFailure returns a ContainerFailureError for the first container of pod
+// in a fatal waiting state.
+func (w *Waiter) podFailure(ctx context.Context, pod *corev1.Pod) error {
+	statuses := append(append([]corev1.ContainerStatus(nil), pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if cs.State.Waiting == nil || !FatalWaitingReasons[cs.State.Waiting.Reason] {
+			continue
+		}
+		return &ContainerFailureError{
+			Pod:       pod.Name,
+			Container: cs.Name,
+			Reason:    cs.State.Waiting.Reason,
+			Message:   cs.State.Waiting.Message,
+			Logs:      w.containerLogs(ctx, pod.Name, cs.Name, cs.State.Waiting.Reason == "CrashLoopBackOff"),
+		}
+	}
+	return nil
+}
+
+// statefulSetPodFailure checks the StatefulSet's pods for fatal states.
+func (w *Waiter) statefulSetPodFailure(ctx context.Context, sts *appsv1.StatefulSet) error {
+	if sts.Spec.Selector == nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(sts.Spec.Selector)
+	if err != nil {
+		return nil
+	}
+	pods, err := w.Client.CoreV1().Pods(w.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil // the StatefulSet wait itself keeps going
+	}
+	for i := range pods.Items {
+		if err := w.podFailure(ctx, &pods.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containerLogs fetches the tail of a container's log, preferring the
+// previous instance for crash loops.
+func (w *Waiter) containerLogs(ctx context.Context, pod, container string, previous bool) string {
+	tail := w.LogTailLines
+	if tail <= 0 {
+		tail = 50
+	}
+	fetch := func(previous bool) (string, error) {
+		stream, err := w.Client.CoreV1().Pods(w.Namespace).GetLogs(pod, &corev1.PodLogOptions{
+			Container: container,
+			Previous:  previous,
+			TailLines: &tail,
+		}).Stream(ctx)
+		if err != nil {
+			return "", err
+		}
+		defer stream.Close()
+		data, err := io.ReadAll(stream)
+		return string(data), err
+	}
+
+	logs, err := fetch(previous)
+	if err != nil && previous {
+		logs, err = fetch(false)
+	}
+	if err != nil {
+		return fmt.Sprintf("(logs unavailable: %v)", err)
+	}
+	return logs
+}
+
+// --- summaries ---
+
+func podConditionTrue(pod *corev1.Pod, typ corev1.PodConditionType) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == typ {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func podSummary(pod *corev1.Pod) string {
+	parts := []string{"phase=" + string(pod.Status.Phase), fmt.Sprintf("ready=%t", podConditionTrue(pod, corev1.PodReady))}
+	for _, cs := range pod.Status.ContainerStatuses {
+		switch {
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason != "":
+			parts = append(parts, fmt.Sprintf("%s=%s", cs.Name, cs.State.Waiting.Reason))
+		case cs.RestartCount > 0:
+			parts = append(parts, fmt.Sprintf("%s restarts=%d", cs.Name, cs.RestartCount))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func statefulSetSummary(sts *appsv1.StatefulSet) string {
+	want := int32(1)
+	if sts.Spec.Replicas != nil {
+		want = *sts.Spec.Replicas
+	}
+	return fmt.Sprintf("ready=%d/%d updated=%d", sts.Status.ReadyReplicas, want, sts.Status.UpdatedReplicas)
+}
+
+func serviceSummary(svc *corev1.Service) string {
+	s := fmt.Sprintf("type=%s clusterIP=%s", svc.Spec.Type, svc.Spec.ClusterIP)
+	for _, ing := range svc.Status.LoadBalancer.Ingress {
+		s += " ingress=" + ing.IP + ing.Hostname
+	}
+	return s
+}
+
+// WaitForNeo4jReady waits for the Neo4j StatefulSet and its pod to be ready,
+// failing early with container logs if the pod cannot start.
+func WaitForNeo4jReady(t *testing.T, w *Waiter, releaseName string, timeout time.Duration) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := w.ForStatefulSet(ctx, releaseName, StatefulSetReady)
+	require.NoError(t, err, "Neo4j StatefulSet did not become ready")
+	_, err = w.ForPod(ctx, fmt.Sprintf("%s-0", releaseName), PodReady)
+	require.NoError(t, err, "Neo4j pod did not become ready")
+}
@@ -0,0 +1,222 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const waitNS = "neo4j"
+
+// recorder collects progress events and signals when a watch starts.
+type recorder struct {
+	mu       sync.Mutex
+	events   []Event
+	watching chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{watching: make(chan struct{}, 16)}
+}
+
+func (r *recorder) record(e Event) {
+	r.mu.Lock()
+	r.events = append(r.events, e)
+	r.mu.Unlock()
+	if e.Type == EventWatch {
+		r.watching <- struct{}{}
+	}
+}
+
+func (r *recorder) messages(typ EventType) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []string
+	for _, e := range r.events {
+		if e.Type == typ {
+			out = append(out, e.Message)
+		}
+	}
+	return out
+}
+
+func newTestWaiter(objects ...runtime.Object) (*Waiter, *fake.Clientset, *recorder) {
+	rec := newRecorder()
+	client := fake.NewClientset(objects...)
+	return &Waiter{Client: client, Namespace: waitNS, Progress: rec.record, Resync: time.Minute}, client, rec
+}
+
+func pod(name string, phase corev1.PodPhase, ready bool, statuses ...corev1.ContainerStatus) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: waitNS, Labels: map[string]string{"app": "neo4j"}},
+		Status: corev1.PodStatus{
+			Phase:             phase,
+			Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			ContainerStatuses: statuses,
+		},
+	}
+}
+
+func waiting(container, reason string) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name:  container,
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: "back-off"}},
+	}
+}
+
+func TestWaiter_PodBecomesReady(t *testing.T) {
+	w, client, rec := newTestWaiter(pod("neo4j-0", corev1.PodPending, false))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go func() {
+		<-rec.watching
+		_, err := client.CoreV1().Pods(waitNS).UpdateStatus(ctx, pod("neo4j-0", corev1.PodRunning, true), metav1.UpdateOptions{})
+		if err != nil {
+			t.Errorf("update pod: %v", err)
+		}
+	}()
+
+	got, err := w.ForPod(ctx, "neo4j-0", PodReady)
+	require.NoError(t, err)
+	require.Equal(t, corev1.PodRunning, got.Status.Phase)
+	require.Equal(t, []string{"phase=Pending ready=false", "phase=Running ready=true"}, rec.messages(EventStatus))
+}
+
+func TestWaiter_CrashLoopFailsEarlyWithLogs(t *testing.T) {
+	w, _, rec := newTestWaiter(pod("neo4j-0", corev1.PodRunning, false, waiting("neo4j", "CrashLoopBackOff")))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := w.ForPod(ctx, "neo4j-0", PodReady)
+	var failure *ContainerFailureError
+	require.ErrorAs(t, err, &failure)
+	require.Equal(t, "neo4j", failure.Container)
+	require.Equal(t, "CrashLoopBackOff", failure.Reason)
+	require.Equal(t, "fake logs", failure.Logs, "logs come from the clientset")
+	require.Contains(t, err.Error(), "last container logs")
+	require.Len(t, rec.messages(EventFailure), 1)
+}
+
+func TestWaiter_StatefulSetFailsOnPodImagePull(t *testing.T) {
+	replicas := int32(1)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "neo4j", Namespace: waitNS, Generation: 1},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "neo4j"}},
+		},
+	}
+	w, _, _ := newTestWaiter(sts, pod("neo4j-0", corev1.PodPending, false, waiting("neo4j", "ImagePullBackOff")))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := w.ForStatefulSet(ctx, "neo4j", StatefulSetReady)
+	var failure *ContainerFailureError
+	require.ErrorAs(t, err, &failure)
+	require.Equal(t, "ImagePullBackOff", failure.Reason)
+}
+
+func TestWaiter_StatefulSetBecomesReady(t *testing.T) {
+	replicas := int32(1)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "neo4j", Namespace: waitNS, Generation: 1},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+	}
+	w, client, rec := newTestWaiter(sts)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go func() {
+		<-rec.watching
+		ready := sts.DeepCopy()
+		ready.Status = appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 1, UpdatedReplicas: 1}
+		if _, err := client.AppsV1().StatefulSets(waitNS).UpdateStatus(ctx, ready, metav1.UpdateOptions{}); err != nil {
+			t.Errorf("update statefulset: %v", err)
+		}
+	}()
+
+	_, err := w.ForStatefulSet(ctx, "neo4j", StatefulSetReady)
+	require.NoError(t, err)
+	require.Contains(t, rec.messages(EventStatus), "ready=1/1 updated=1")
+}
+
+func TestWaiter_PVCAppearsAndBinds(t *testing.T) {
+	w, client, rec := newTestWaiter()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go func() {
+		<-rec.watching
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data-neo4j-0", Namespace: waitNS},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		}
+		if _, err := client.CoreV1().PersistentVolumeClaims(waitNS).Create(ctx, pvc, metav1.CreateOptions{}); err != nil {
+			t.Errorf("create pvc: %v", err)
+		}
+	}()
+
+	_, err := w.ForPVC(ctx, "data-neo4j-0", PVCBound)
+	require.NoError(t, err)
+	require.Equal(t, []string{"not found", "phase=Bound"}, rec.messages(EventStatus))
+}
+
+func TestWaiter_DeadlineReportsLastStatus(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "neo4j-lb", Namespace: waitNS},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer, ClusterIP: "10.0.0.1"},
+	}
+	w, _, _ := newTestWaiter(svc)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := w.ForService(ctx, "neo4j-lb", ServiceLoadBalancerReady)
+	require.True(t, errors.Is(err, context.DeadlineExceeded), "got %v", err)
+	require.Contains(t, err.Error(), "type=LoadBalancer clusterIP=10.0.0.1")
+}
+
+func TestPredicates(t *testing.T) {
+	done, err := PodReady(pod("p", corev1.PodFailed, false))
+	require.Error(t, err)
+	require.False(t, done)
+
+	done, err = PodSucceeded(pod("job", corev1.PodSucceeded, false))
+	require.NoError(t, err)
+	require.True(t, done)
+
+	_, err = PodSucceeded(pod("job", corev1.PodFailed, false))
+	require.Error(t, err)
+
+	rolling := &appsv1.StatefulSet{Status: appsv1.StatefulSetStatus{
+		ReadyReplicas: 1, UpdatedReplicas: 1, CurrentRevision: "neo4j-1", UpdateRevision: "neo4j-2",
+	}}
+	done, err = StatefulSetRolledOut(rolling)
+	require.NoError(t, err)
+	require.False(t, done, "a rollout in progress is not done")
+	rolling.Status.CurrentRevision = "neo4j-2"
+	done, err = StatefulSetRolledOut(rolling)
+	require.NoError(t, err)
+	require.True(t, done)
+
+	done, err = PVCBound(&corev1.PersistentVolumeClaim{Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimLost}})
+	require.Error(t, err)
+	require.False(t, done)
+
+	done, err = ServiceHasClusterIP(&corev1.Service{Spec: corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone}})
+	require.NoError(t, err)
+	require.False(t, done)
+}
@@ -0,0 +1,71 @@
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// --- environment roots ---
+
+// BootstrapStateFixture is a bootstrap state holding only outputs, for
+// planning an environment root without the state bucket.
+const BootstrapStateFixture = "testdata/bootstrap/terraform.tfstate"
+
+// localBootstrapOverride replaces an environment root's gcs backend and its
+// bootstrap remote state with local files. Override files merge by argument,
+// so config is replaced as a whole.
+const localBootstrapOverride = `terraform {
+  backend "local" {}
+}
+
+data "terraform_remote_state" "bootstrap" {
+  backend = "local"
+  config = {
+    path = "bootstrap.tfstate"
+  }
+}
+`
+
+// UseLocalBootstrapState points the environment root in envDir (a copy, e.g.
+// from CopyEnvToTemp) at a local copy of the fixture state instead of the
+// bootstrap prefix of var.state_bucket, and uses the local backend for the
+// root's own state. state_bucket must still be set but is not read.
+//
+// It returns the fixture's "state" output, so tests can compare the wiring
+// against e.g. its kms_key_name.
+//
+// Usage:
+//
+//	dir := CopyEnvToTemp(t, "dev")
+//	bootstrap := UseLocalBootstrapState(t, dir, BootstrapStateFixture)
+//	plan := PlanJSON(t, tf)
+//	RequirePlannedAttribute(t, plan, "google_kms_crypto_key_iam_member.backup_sa_kms", "crypto_key_id", bootstrap["kms_key_name"])
+func UseLocalBootstrapState(t *testing.T, envDir, fixture string) map[string]any {
+	t.Helper()
+
+	raw, err := os.ReadFile(fixture)
+	require.NoError(t, err)
+	out := bootstrapStateOutput(t, fixture, raw)
+
+	require.NoError(t, os.WriteFile(filepath.Join(envDir, "bootstrap.tfstate"), raw, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(envDir, "bootstrap_override.tf"), []byte(localBootstrapOverride), 0600))
+	return out
+}
+
+// bootstrapStateOutput decodes the "state" output of a bootstrap state file.
+func bootstrapStateOutput(t *testing.T, fixture string, raw []byte) map[string]any {
+	t.Helper()
+	var state struct {
+		Outputs map[string]struct {
+			Value any `json:"value"`
+		} `json:"outputs"`
+	}
+	require.NoErrorf(t, json.Unmarshal(raw, &state), "decode state fixture %s", fixture)
+	out, ok := state.Outputs["state"].Value.(map[string]any)
+	require.Truef(t, ok, "state fixture %s has no object output \"state\"", fixture)
+	return out
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// FakeGcloud is the offline gcloud stand-in installed by UseFakeGcloud.
+type FakeGcloud struct {
+	// BinDir is the directory prepended to PATH; it contains `gcloud`.
+	BinDir  string
+	logPath string
+}
+
+// UseFakeGcloud builds test/fakegcloud and puts it first on PATH as `gcloud`
+// for the rest of the test, answering from the *.json fixtures in fixtureDir
+// (see the fakegcloud command for the fixture format). runGCLOUD, the
+// existence probes and any other exec of `gcloud` then run without GCP.
+//
+// Uses t.Setenv, so callers cannot be parallel tests.
+func UseFakeGcloud(t *testing.T, fixtureDir string) *FakeGcloud {
+	t.Helper()
+
+	absFixtures, err := filepath.Abs(fixtureDir)
+	require.NoError(t, err)
+
+	binDir := t.TempDir()
+	cmd := exec.Command("go", "build", "-o", filepath.Join(binDir, "gcloud"), "./test/fakegcloud")
+	cmd.Dir = RepoRoot(t)
+	out, err := cmd.CombinedOutput()
+	require.NoErrorf(t, err, "failed to build fake gcloud: %s", out)
+
+	logPath := filepath.Join(binDir, "calls.jsonl")
+	t.Setenv("FAKE_GCLOUD_FIXTURES", absFixtures)
+	t.Setenv("FAKE_GCLOUD_LOG", logPath)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return &FakeGcloud{BinDir: binDir, logPath: logPath}
+}
+
+// Calls returns the argv of every gcloud invocation so far, in order.
+func (f *FakeGcloud) Calls(t *testing.T) [][]string {
+	t.Helper()
+
+	file, err := os.Open(f.logPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	defer file.Close()
+
+	var calls [][]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var args []string
+		require.NoError(t, json.Unmarshal([]byte(line), &args))
+		calls = append(calls, args)
+	}
+	require.NoError(t, scanner.Err())
+	return calls
+}
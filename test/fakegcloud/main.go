@@ -0,0 +1,245 @@
+// Command fakegcloud is an offline stand-in for the gcloud CLI used by the test
+// harness (see UseFakeGcloud in package test). It answers requests from JSON
+// fixture files keyed by argv, so gcloud-based assertions can run without GCP.
+//
+// Every *.json file in $FAKE_GCLOUD_FIXTURES holds a list of entries:
+//
+//	[
+//	  {"args": ["--project", "p", "kms", "keyrings", "describe", "ring", "--location", "us"],
+//	   "stdout": "name: ring\n"},
+//	  {"args": ["--project", "p", "container", "clusters", "describe", "c", "--region", "us-central1"],
+//	   "json": {"name": "c", "autopilot": {"enabled": true}}},
+//	  {"args": ["--project", "p", "secrets", "describe", "missing"],
+//	   "stderr": "NOT_FOUND", "exit_code": 1}
+//	]
+//
+// An exact argv match wins. Otherwise, for --format=json or --format=value(...)
+// the request is answered from the entry whose args equal argv without the
+// --format flag and which carries a "json" body; value() projections are
+// rendered like gcloud does, including printing an empty string for fields the
+// API omits (which is how false booleans usually come back). Unmatched requests
+// exit 1 without NOT_FOUND, so existence probes report them as errors; give a
+// missing resource an entry with a NOT_FOUND stderr like the one above.
+//
+// Each call is appended as a JSON array to $FAKE_GCLOUD_LOG when it is set.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// entry is one fixture response.
+type entry struct {
+	Args     []string        `json:"args"`
+	Stdout   string          `json:"stdout"`
+	Stderr   string          `json:"stderr"`
+	ExitCode int             `json:"exit_code"`
+	JSON     json.RawMessage `json:"json"`
+}
+
+func main() {
+	args := os.Args[1:]
+	logCall(args)
+
+	entries, err := loadFixtures(os.Getenv("FAKE_GCLOUD_FIXTURES"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fake gcloud: %v\n", err)
+		os.Exit(2)
+	}
+
+	stdout, stderr, code := respond(entries, args)
+	fmt.Fprint(os.Stdout, stdout)
+	fmt.Fprint(os.Stderr, stderr)
+	os.Exit(code)
+}
+
+// logCall appends argv to $FAKE_GCLOUD_LOG for later assertions.
+func logCall(args []string) {
+	path := os.Getenv("FAKE_GCLOUD_LOG")
+	if path == "" {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	line, _ := json.Marshal(args)
+	_, _ = f.Write(append(line, '\n'))
+}
+
+// loadFixtures reads every *.json fixture file in dir, in name order.
+func loadFixtures(dir string) ([]entry, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("FAKE_GCLOUD_FIXTURES is not set")
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var all []entry
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var entries []entry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		all = append(all, entries...)
+	}
+	return all, nil
+}
+
+// respond picks the fixture for args and renders stdout, stderr and exit code.
+func respond(entries []entry, args []string) (string, string, int) {
+	for _, e := range entries {
+		if equalArgs(e.Args, args) {
+			if e.JSON != nil && e.Stdout == "" {
+				return string(e.JSON) + "\n", e.Stderr, e.ExitCode
+			}
+			return e.Stdout, e.Stderr, e.ExitCode
+		}
+	}
+
+	base, format := splitFormat(args)
+	if format != "" {
+		for _, e := range entries {
+			if e.JSON == nil || !equalArgs(e.Args, base) {
+				continue
+			}
+			if e.ExitCode != 0 {
+				return e.Stdout, e.Stderr, e.ExitCode
+			}
+			out, err := renderFormat(e.JSON, format)
+			if err != nil {
+				return "", fmt.Sprintf("fake gcloud: %v\n", err), 2
+			}
+			return out, "", 0
+		}
+	}
+
+	return "", fmt.Sprintf("fake gcloud: no fixture for: %s\n", strings.Join(args, " ")), 1
+}
+
+// splitFormat removes a --format flag from args, returning the remaining args
+// and the format expression.
+func splitFormat(args []string) ([]string, string) {
+	var base []string
+	format := ""
+	for i := 0; i < len(args); i++ {
+		switch {
+		case strings.HasPrefix(args[i], "--format="):
+			format = strings.TrimPrefix(args[i], "--format=")
+		case args[i] == "--format" && i+1 < len(args):
+			format = args[i+1]
+			i++
+		default:
+			base = append(base, args[i])
+		}
+	}
+	return base, format
+}
+
+func equalArgs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+var valueFormat = regexp.MustCompile(`^value\((.*)\)$`)
+
+// renderFormat renders a JSON document for --format=json or --format=value(...).
+func renderFormat(doc json.RawMessage, format string) (string, error) {
+	if format == "json" {
+		return string(doc) + "\n", nil
+	}
+
+	m := valueFormat.FindStringSubmatch(format)
+	if m == nil {
+		return "", fmt.Errorf("unsupported --format %q (only json and value(...) are emulated)", format)
+	}
+
+	var v any
+	if err := json.Unmarshal(doc, &v); err != nil {
+		return "", err
+	}
+
+	var fields []string
+	for _, key := range strings.Split(m[1], ",") {
+		fields = append(fields, renderValue(lookup(v, strings.TrimSpace(key))))
+	}
+	return strings.Join(fields, "\t") + "\n", nil
+}
+
+var indexSegment = regexp.MustCompile(`^([^\[]*)\[(\d+)\]$`)
+
+// lookup resolves a gcloud projection key such as
+// "privateClusterConfig.enablePrivateNodes" or "secondaryIpRanges[0].rangeName".
+func lookup(v any, key string) any {
+	cur := v
+	for _, seg := range strings.Split(key, ".") {
+		name, index := seg, -1
+		if m := indexSegment.FindStringSubmatch(seg); m != nil {
+			name = m[1]
+			index, _ = strconv.Atoi(m[2])
+		}
+		if name != "" {
+			obj, ok := cur.(map[string]any)
+			if !ok {
+				return nil
+			}
+			cur = obj[name]
+		}
+		if index >= 0 {
+			list, ok := cur.([]any)
+			if !ok || index >= len(list) {
+				return nil
+			}
+			cur = list[index]
+		}
+	}
+	return cur
+}
+
+// renderValue prints a value the way gcloud's value() projection does.
+func renderValue(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case bool:
+		if x {
+			return "True"
+		}
+		return "False"
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case []any:
+		parts := make([]string, 0, len(x))
+		for _, item := range x {
+			parts = append(parts, renderValue(item))
+		}
+		return strings.Join(parts, ";")
+	default:
+		data, _ := json.Marshal(x)
+		return string(data)
+	}
+}
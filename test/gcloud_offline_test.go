@@ -0,0 +1,103 @@
+package test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Offline tier: these tests run the gcloud-based helpers against the fake
+// gcloud (test/fakegcloud) and the fixtures in testdata/gcloud. They need no
+// GCP project and run in -short mode.
+
+const offlineProject = "offline-project"
+
+func TestOfflineGcloud_ClusterDescribeValueProjections(t *testing.T) {
+	UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+
+	describe := func(format string) string {
+		return runGCLOUD(t, offlineProject, "container", "clusters", "describe", "gke-test-offline",
+			"--region", "us-central1", "--format="+format)
+	}
+
+	require.Equal(t, "gke-test-offline", strings.TrimSpace(describe("value(name)")))
+	requireGcloudBoolTrue(t, describe("value(autopilot.enabled)"))
+	requireGcloudBoolTrue(t, describe("value(privateClusterConfig.enablePrivateNodes)"))
+	require.Equal(t, "REGULAR", strings.TrimSpace(describe("value(releaseChannel.channel)")))
+
+	// The API omits false booleans, so gcloud prints an empty string.
+	out := describe("value(privateClusterConfig.enablePrivateEndpoint)")
+	require.Empty(t, strings.TrimSpace(out))
+	requireGcloudBoolEquals(t, out, false)
+}
+
+func TestOfflineGcloud_NetworkAndSubnet(t *testing.T) {
+	UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+
+	// An explicit false is printed as "False".
+	out := runGCLOUD(t, offlineProject, "compute", "networks", "describe", "test-vpc-offline",
+		"--format=value(autoCreateSubnetworks)")
+	require.Equal(t, "false", strings.ToLower(strings.TrimSpace(out)))
+	requireGcloudBoolEquals(t, out, false)
+
+	out = runGCLOUD(t, offlineProject, "compute", "networks", "subnets", "describe", "test-vpc-offline-subnet",
+		"--region", "us-central1", "--format=value(secondaryIpRanges[0].rangeName)")
+	require.Equal(t, "pods", strings.TrimSpace(out))
+
+	out = runGCLOUD(t, offlineProject, "compute", "networks", "subnets", "describe", "test-vpc-offline-subnet",
+		"--region", "us-central1", "--format=value(privateIpGoogleAccess)")
+	requireGcloudBoolTrue(t, out)
+}
+
+func TestOfflineGcloud_GetIAMPolicy(t *testing.T) {
+	UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+
+	out := runGCLOUD(t, offlineProject, "storage", "buckets", "get-iam-policy",
+		"gs://offline-project-backup-test-offline", "--format=json")
+	require.Contains(t, out, "backup-offline@offline-project.iam.gserviceaccount.com")
+	require.Contains(t, out, "roles/storage.objectCreator")
+	require.Contains(t, out, "roles/storage.objectViewer")
+}
+
+func TestOfflineGcloud_KMSExistenceProbes(t *testing.T) {
+	fake := UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+	ring, key := BootstrapKMSAdoption[0], BootstrapKMSAdoption[1]
+	vars := map[string]string{"location": "us-central1", "ring": "offline-project-tfstate-ring", "key": "tfstate-key"}
+
+	requireAdoptState(t, ring, vars, AdoptPresent)
+	requireAdoptState(t, key, vars, AdoptAbsent)
+
+	// Only NOT_FOUND means absent; other failures must not skip the import.
+	_, err := ring.State(t, offlineProject, map[string]string{"location": "europe-west1", "ring": "denied-ring"})
+	require.ErrorContains(t, err, "PERMISSION_DENIED")
+	_, err = ring.State(t, offlineProject, map[string]string{"location": "europe-west1", "ring": "other-ring"})
+	require.ErrorContains(t, err, "no fixture")
+	require.Error(t, runGCLOUDNoOutE(t, offlineProject, "secrets", "describe", "missing"))
+
+	calls := fake.Calls(t)
+	require.Len(t, calls, 5)
+	require.Equal(t, []string{"--project", offlineProject, "kms", "keyrings", "describe",
+		"offline-project-tfstate-ring", "--location", "us-central1"}, calls[0])
+}
+
+func TestOfflineGcloud_WIFSoftDeleteProbes(t *testing.T) {
+	fake := UseFakeGcloud(t, filepath.Join("testdata", "gcloud"))
+	vars := map[string]string{"pool": "gha-offline", "provider": "github"}
+
+	requireAdoptState(t, WIFAdoption[0], vars, AdoptSoftDeleted)
+	requireAdoptState(t, WIFAdoption[1], vars, AdoptPresent)
+	requireAdoptState(t, WIFAdoption[0], map[string]string{"pool": "gha-unused", "provider": "github"}, AdoptAbsent)
+
+	calls := fake.Calls(t)
+	require.Equal(t, []string{"--project", offlineProject, "iam", "workload-identity-pools", "describe",
+		"gha-offline", "--location", "global", "--format=value(state)"}, calls[0])
+}
+
+func requireAdoptState(t *testing.T, spec AdoptSpec, vars map[string]string, want AdoptState) {
+	t.Helper()
+	got, err := spec.State(t, offlineProject, vars)
+	require.NoError(t, err)
+	require.Equalf(t, want, got, "%s: want %s, got %s", spec.Address, want, got)
+}
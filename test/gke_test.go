@@ -2,10 +2,8 @@ package test
 
 import (
 	"fmt"
-	"strings"
 	"testing"
 
-	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/require"
 )
@@ -17,170 +15,127 @@ import (
 //
 //	go test -timeout 30m -v ./test/... -run TestGKE_CreateDescribeDestroy
 //
-// The test will fail fast if the timeout is insufficient, preventing orphaned resources.
+// Each region's subtest skips before creating anything if the timeout leaves
+// too little time to create and destroy the cluster, so nothing is orphaned.
 func TestGKE_CreateDescribeDestroy(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
+	t.Parallel()
 
 	if testing.Short() {
 		t.Skip("Skipping GKE integration test in short mode (takes 15-20 minutes)")
 	}
 
-	// CRITICAL: Validate timeout BEFORE creating any resources.
-	// GKE cluster creation takes 15-20 minutes, and cleanup takes 5-10 minutes.
-	// If we don't have enough time, fail fast rather than orphan resources.
-	RequireMinimumTimeout(t, GKETestTimeout)
-
-	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
-	region := GetTestRegion(t)
-
-	suffix := strings.ToLower(random.UniqueId())
-
-	// Step 1: Create VPC first (GKE depends on it)
-	vpcDir := CopyModuleToTemp(t, "vpc")
-	vpcName := fmt.Sprintf("gke-test-vpc-%s", suffix)
-
-	vpcTf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    vpcDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":       projectID,
-			"region":           region,
-			"vpc_name":         vpcName,
-			"enable_cloud_nat": true,
-		},
-		NoColor: true,
-	})
-
-	// Step 2: Set up GKE terraform options (before VPC apply, for cleanup registration)
-	gkeDir := CopyModuleToTemp(t, "gke")
-	clusterName := fmt.Sprintf("gke-test-%s", suffix)
-
-	// We'll set the actual network values after VPC is created
-	gkeTf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    gkeDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":           projectID,
-			"region":               region,
-			"cluster_name":         clusterName,
-			"network_id":           "", // Will be set after VPC creation
-			"subnet_id":            "", // Will be set after VPC creation
-			"pods_range_name":      "", // Will be set after VPC creation
-			"services_range_name":  "", // Will be set after VPC creation
-			"deletion_protection":  false,
-			"enable_container_api": true,
-		},
-		NoColor: true,
-	})
-
-	// Register cleanup for both resources BEFORE creating anything.
-	// Order matters: GKE cleanup runs first (registered second), then VPC (registered first).
-	// t.Cleanup() runs in LIFO order.
-	DeferredTerraformCleanup(t, vpcTf)
-	DeferredTerraformCleanup(t, gkeTf)
-
-	// Now create the VPC
-	terraform.InitAndApply(t, vpcTf)
-
-	// Get VPC outputs
-	networkID, err := terraform.OutputE(t, vpcTf, "network_id")
-	require.NoError(t, err, "failed to get network_id output")
-	subnetID, err := terraform.OutputE(t, vpcTf, "subnet_id")
-	require.NoError(t, err, "failed to get subnet_id output")
-	podsRangeName, err := terraform.OutputE(t, vpcTf, "pods_range_name")
-	require.NoError(t, err, "failed to get pods_range_name output")
-	servicesRangeName, err := terraform.OutputE(t, vpcTf, "services_range_name")
-	require.NoError(t, err, "failed to get services_range_name output")
-
-	// Create new GKE options with actual VPC values (avoid mutating original)
-	gkeTfApply := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    gkeDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":           projectID,
-			"region":               region,
-			"cluster_name":         clusterName,
-			"network_id":           networkID,
-			"subnet_id":            subnetID,
-			"pods_range_name":      podsRangeName,
-			"services_range_name":  servicesRangeName,
-			"deletion_protection":  false,
-			"enable_container_api": true,
-		},
-		NoColor: true,
+	// CRITICAL: Check the budget BEFORE creating any resources.
+	// GKE cluster creation takes 10-15 minutes, and cleanup takes 5-10 minutes.
+	// If we don't have enough time, skip rather than orphan resources.
+	ForEachRegion(t, func(t *testing.T, region TestRegion) {
+		budget := NewBudget(t, "vpc", "gke")
+
+		projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
+
+		// Step 1: Create VPC first (GKE depends on it)
+		vpc := &VPCOptions{
+			ProjectID:      projectID,
+			VPCName:        Ptr(region.UniqueName(t, NameVPC, "gke-test-vpc")),
+			EnableCloudNAT: Ptr(true),
+		}
+		region.ConfigureVPC(vpc)
+		vpcTf := vpc.TerraformOptions(t, CopyModuleToTemp(t, vpc.Module()))
+
+		// Register VPC cleanup BEFORE creating anything. The GKE cleanup is
+		// registered after it (below), so t.Cleanup's LIFO order destroys the
+		// cluster first.
+		budget.Cleanup(vpc.Module(), vpcTf)
+		budget.Apply(vpc.Module(), vpcTf)
+
+		// Step 2: Build GKE options from the VPC outputs
+		clusterName := region.UniqueName(t, NameCluster, "gke-test")
+		gke := &GKEOptions{
+			ProjectID:          projectID,
+			Region:             region.Name,
+			ClusterName:        Ptr(clusterName),
+			NetworkID:          terraform.Output(t, vpcTf, "network_id"),
+			SubnetID:           terraform.Output(t, vpcTf, "subnet_id"),
+			PodsRangeName:      terraform.Output(t, vpcTf, "pods_range_name"),
+			ServicesRangeName:  terraform.Output(t, vpcTf, "services_range_name"),
+			DeletionProtection: Ptr(false),
+			EnableContainerAPI: Ptr(true),
+		}
+		gkeTf := gke.TerraformOptions(t, CopyModuleToTemp(t, gke.Module()))
+		budget.Cleanup(gke.Module(), gkeTf)
+
+		// Create the GKE cluster. The Container API enablement is shared with
+		// other tests; its destroy is a no-op (disable_on_destroy = false), so the
+		// lease only needs to cover the apply.
+		apiLease := AcquireLease(t, ProjectServiceLeaseKey(projectID, "container.googleapis.com"))
+		budget.Apply(gke.Module(), gkeTf)
+		apiLease.Release(t)
+
+		// Verify cluster was created
+		outputClusterName, err := terraform.OutputE(t, gkeTf, "cluster_name")
+		require.NoError(t, err, "failed to get cluster_name output")
+		require.Equal(t, clusterName, outputClusterName)
+
+		// Verify Workload Identity pool
+		wiPool, err := terraform.OutputE(t, gkeTf, "workload_identity_pool")
+		require.NoError(t, err, "failed to get workload_identity_pool output")
+		require.Equal(t, fmt.Sprintf("%s.svc.id.goog", projectID), wiPool)
+
+		// Verify the cluster via gcloud
+		cluster := DescribeCluster(t, projectID, region.Name, clusterName)
+		require.Equal(t, clusterName, cluster.Name)
+		require.True(t, cluster.Autopilot.Enabled, "expected an Autopilot cluster")
+		require.True(t, cluster.PrivateClusterConfig.EnablePrivateNodes, "expected private nodes")
+		// Master endpoint is public (private endpoint disabled by default)
+		require.False(t, cluster.PrivateClusterConfig.EnablePrivateEndpoint, "expected a public master endpoint")
+		require.Equal(t, "REGULAR", cluster.ReleaseChannel.Channel)
+		require.Equal(t, wiPool, cluster.WorkloadIdentityConfig.WorkloadPool)
 	})
-
-	// Create the GKE cluster
-	terraform.InitAndApply(t, gkeTfApply)
-
-	// Verify cluster was created
-	outputClusterName, err := terraform.OutputE(t, gkeTfApply, "cluster_name")
-	require.NoError(t, err, "failed to get cluster_name output")
-	require.Equal(t, clusterName, outputClusterName)
-
-	// Verify Workload Identity pool
-	wiPool, err := terraform.OutputE(t, gkeTfApply, "workload_identity_pool")
-	require.NoError(t, err, "failed to get workload_identity_pool output")
-	require.Equal(t, fmt.Sprintf("%s.svc.id.goog", projectID), wiPool)
-
-	// Verify cluster exists via gcloud
-	out := runGCLOUD(t, projectID, "container", "clusters", "describe", clusterName,
-		"--region", region, "--format=value(name)")
-	require.Equal(t, clusterName, strings.TrimSpace(out))
-
-	// Verify it's an Autopilot cluster
-	out = runGCLOUD(t, projectID, "container", "clusters", "describe", clusterName,
-		"--region", region, "--format=value(autopilot.enabled)")
-	requireGcloudBoolTrue(t, out)
-
-	// Verify private nodes are enabled
-	out = runGCLOUD(t, projectID, "container", "clusters", "describe", clusterName,
-		"--region", region, "--format=value(privateClusterConfig.enablePrivateNodes)")
-	requireGcloudBoolTrue(t, out)
-
-	// Verify master endpoint is public (private endpoint disabled by default)
-	out = runGCLOUD(t, projectID, "container", "clusters", "describe", clusterName,
-		"--region", region, "--format=value(privateClusterConfig.enablePrivateEndpoint)")
-	requireGcloudBoolEquals(t, out, false)
-
-	// Verify release channel
-	out = runGCLOUD(t, projectID, "container", "clusters", "describe", clusterName,
-		"--region", region, "--format=value(releaseChannel.channel)")
-	require.Equal(t, "REGULAR", strings.TrimSpace(out))
 }
 
 // TestGKE_PlanOnly validates the GKE module configuration without creating resources.
 // Use this for quick validation during development.
 func TestGKE_PlanOnly(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
+	t.Parallel()
 
 	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
 
-	gkeDir := CopyModuleToTemp(t, "gke")
-
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    gkeDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":           projectID,
-			"region":               "us-central1",
-			"cluster_name":         "plan-test-cluster",
-			"network_id":           "projects/test/global/networks/test-vpc",
-			"subnet_id":            "projects/test/regions/us-central1/subnetworks/test-subnet",
-			"pods_range_name":      "pods",
-			"services_range_name":  "services",
-			"deletion_protection":  false,
-			"enable_container_api": false,
-		},
-		NoColor: true,
-	})
-
-	// Only run init and plan (no apply)
-	terraform.Init(t, tf)
-	planOutput := terraform.Plan(t, tf)
-
-	// Verify plan contains expected resources
-	require.Contains(t, planOutput, "google_container_cluster.autopilot")
+	gke := &GKEOptions{
+		ProjectID:          projectID,
+		Region:             "us-central1",
+		ClusterName:        Ptr("plan-test-cluster"),
+		NetworkID:          "projects/test/global/networks/test-vpc",
+		SubnetID:           "projects/test/regions/us-central1/subnetworks/test-subnet",
+		PodsRangeName:      "pods",
+		ServicesRangeName:  "services",
+		DeletionProtection: Ptr(false),
+		EnableContainerAPI: Ptr(false),
+	}
+	tf := gke.TerraformOptions(t, CopyModuleToTemp(t, gke.Module()))
+
+	// Only run init and plan (no apply), then inspect the JSON plan
+	plan := PlanJSON(t, tf)
+
+	const cluster = "google_container_cluster.autopilot"
+	RequireResourceCreated(t, plan, cluster)
+	RequireNoResourceDestroyed(t, plan)
+
+	// Container API enablement is skipped when enable_container_api=false
+	RequireResourceAbsent(t, plan, "google_project_service.container[0]")
+
+	// Verify the cluster configuration that will be sent to GCP
+	RequirePlannedAttribute(t, plan, cluster, "name", "plan-test-cluster")
+	RequirePlannedAttribute(t, plan, cluster, "location", "us-central1")
+	RequirePlannedAttribute(t, plan, cluster, "enable_autopilot", true)
+	RequirePlannedAttribute(t, plan, cluster, "deletion_protection", false)
+	RequirePlannedAttribute(t, plan, cluster, "network", "projects/test/global/networks/test-vpc")
+	RequirePlannedAttribute(t, plan, cluster, "subnetwork", "projects/test/regions/us-central1/subnetworks/test-subnet")
+	RequirePlannedAttribute(t, plan, cluster, "ip_allocation_policy.0.cluster_secondary_range_name", "pods")
+	RequirePlannedAttribute(t, plan, cluster, "ip_allocation_policy.0.services_secondary_range_name", "services")
+	RequirePlannedAttribute(t, plan, cluster, "private_cluster_config.0.enable_private_nodes", true)
+	RequirePlannedAttribute(t, plan, cluster, "private_cluster_config.0.enable_private_endpoint", false)
+	RequirePlannedAttribute(t, plan, cluster, "private_cluster_config.0.master_ipv4_cidr_block", "172.16.0.0/28")
+	RequirePlannedAttribute(t, plan, cluster, "release_channel.0.channel", "REGULAR")
+
+	// Harness-injected labels reach the cluster
+	RequirePlannedAttribute(t, plan, cluster, "resource_labels."+LabelRunID, TestRunID())
 }
@@ -0,0 +1,152 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+// Labels injected into every module that exposes a `labels` variable, so
+// cleanup tooling and cost reports can attribute resources to a test run.
+const (
+	LabelRunID     = "test-run-id"
+	LabelTestName  = "test-name"
+	LabelCreatedAt = "created-at"
+	LabelExpiresAt = "expires-at"
+)
+
+// DefaultResourceTTL is how long test resources are expected to live before
+// cleanup tooling may treat them as orphaned. Override via
+// NEO4J_GKE_TEST_RESOURCE_TTL (a Go duration, e.g. "6h").
+const DefaultResourceTTL = 24 * time.Hour
+
+var (
+	runIDOnce sync.Once
+	runID     string
+
+	labelsVariablePattern = regexp.MustCompile(`(?m)^\s*variable\s+"labels"\s*\{`)
+	invalidLabelChars     = regexp.MustCompile(`[^a-z0-9_-]+`)
+)
+
+// TestRunID identifies this `go test` invocation. It is generated once per
+// process unless NEO4J_GKE_TEST_RUN_ID is set (e.g. to the CI run ID).
+func TestRunID() string {
+	runIDOnce.Do(func() {
+		runID = strings.TrimSpace(os.Getenv("NEO4J_GKE_TEST_RUN_ID"))
+		if runID == "" {
+			runID = fmt.Sprintf("%s-%s", time.Now().UTC().Format("20060102t150405"), random.UniqueId())
+		}
+		runID = sanitizeLabelValue(runID)
+	})
+	return runID
+}
+
+// NewTerraformOptions is the harness constructor for terraform.Options. It
+// applies terratest's default retryable errors and injects the test-run labels
+// (see ApplyTestLabels). Use it everywhere instead of calling
+// terraform.WithDefaultRetryableErrors directly.
+func NewTerraformOptions(t *testing.T, options *terraform.Options) *terraform.Options {
+	t.Helper()
+
+	options = terraform.WithDefaultRetryableErrors(t, options)
+	ApplyTestLabels(t, options)
+	return options
+}
+
+// ApplyTestLabels merges the test-run labels into options.Vars["labels"] when the
+// module in options.TerraformDir declares a `labels` variable, and logs a
+// warning otherwise. Explicit labels already present in Vars are kept.
+func ApplyTestLabels(t *testing.T, options *terraform.Options) {
+	t.Helper()
+
+	if !moduleHasLabelsVariable(t, options.TerraformDir) {
+		t.Logf("WARNING: module %s has no `labels` variable; its resources will not carry test-run labels",
+			filepath.Base(options.TerraformDir))
+		return
+	}
+
+	merged := map[string]string{}
+	switch existing := options.Vars["labels"].(type) {
+	case nil:
+	case map[string]string:
+		for k, v := range existing {
+			merged[k] = v
+		}
+	case map[string]any:
+		for k, v := range existing {
+			merged[k] = fmt.Sprint(v)
+		}
+	default:
+		require.FailNowf(t, "unsupported labels var", "labels must be a map, got %T", existing)
+	}
+
+	for k, v := range TestLabels(t, time.Now()) {
+		merged[k] = v
+	}
+
+	if options.Vars == nil {
+		options.Vars = map[string]any{}
+	}
+	options.Vars["labels"] = merged
+}
+
+// TestLabels returns the run-attribution labels for t, stamped at now.
+// Timestamps are Unix seconds because GCP label values only allow lowercase
+// letters, digits, '-' and '_'.
+func TestLabels(t *testing.T, now time.Time) map[string]string {
+	t.Helper()
+	return map[string]string{
+		LabelRunID:     TestRunID(),
+		LabelTestName:  sanitizeLabelValue(t.Name()),
+		LabelCreatedAt: fmt.Sprintf("%d", now.Unix()),
+		LabelExpiresAt: fmt.Sprintf("%d", now.Add(resourceTTL(t)).Unix()),
+	}
+}
+
+// resourceTTL returns the configured resource TTL.
+func resourceTTL(t *testing.T) time.Duration {
+	t.Helper()
+	v := strings.TrimSpace(os.Getenv("NEO4J_GKE_TEST_RESOURCE_TTL"))
+	if v == "" {
+		return DefaultResourceTTL
+	}
+	ttl, err := time.ParseDuration(v)
+	require.NoErrorf(t, err, "invalid NEO4J_GKE_TEST_RESOURCE_TTL %q", v)
+	return ttl
+}
+
+// moduleHasLabelsVariable reports whether any .tf file in dir declares
+// `variable "labels"`.
+func moduleHasLabelsVariable(t *testing.T, dir string) bool {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	require.NoError(t, err)
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		require.NoError(t, err)
+		if labelsVariablePattern.Match(data) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeLabelValue converts s into a valid GCP label value: lowercase
+// letters, digits, '-' and '_', at most 63 characters.
+func sanitizeLabelValue(s string) string {
+	v := invalidLabelChars.ReplaceAllString(strings.ToLower(s), "-")
+	v = strings.Trim(v, "-")
+	if len(v) > 63 {
+		v = strings.TrimRight(v[:63], "-")
+	}
+	return v
+}
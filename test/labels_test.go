@@ -0,0 +1,59 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels_ApplyToModuleWithLabelsVariable(t *testing.T) {
+	t.Setenv("NEO4J_GKE_TEST_RESOURCE_TTL", "2h")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "variables.tf"),
+		[]byte("variable \"labels\" {\n  type = map(string)\n}\n"), 0o600))
+
+	tf := &terraform.Options{
+		TerraformDir: dir,
+		Vars: map[string]any{
+			"labels": map[string]string{"test": "true"},
+		},
+	}
+	ApplyTestLabels(t, tf)
+
+	labels, ok := tf.Vars["labels"].(map[string]string)
+	require.True(t, ok)
+	require.Equal(t, "true", labels["test"], "explicit labels are kept")
+	require.Equal(t, TestRunID(), labels[LabelRunID])
+	require.Equal(t, "testlabels_applytomodulewithlabelsvariable", labels[LabelTestName])
+
+	created, err := strconv.ParseInt(labels[LabelCreatedAt], 10, 64)
+	require.NoError(t, err)
+	expires, err := strconv.ParseInt(labels[LabelExpiresAt], 10, 64)
+	require.NoError(t, err)
+	require.Equal(t, int64((2 * time.Hour).Seconds()), expires-created)
+}
+
+func TestLabels_SkipModuleWithoutLabelsVariable(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "variables.tf"),
+		[]byte("variable \"project_id\" {}\n"), 0o600))
+
+	tf := &terraform.Options{TerraformDir: dir, Vars: map[string]any{"project_id": "p"}}
+	ApplyTestLabels(t, tf)
+
+	require.NotContains(t, tf.Vars, "labels")
+}
+
+func TestLabels_SanitizeLabelValue(t *testing.T) {
+	require.Equal(t, "testfoo-sub_case", sanitizeLabelValue("TestFoo/Sub_Case"))
+	require.Equal(t, "a-b", sanitizeLabelValue("--A  b--"))
+
+	long := sanitizeLabelValue("TestSomethingVeryLongThatKeepsGoing/AndHasASubtestWithAnEvenLongerName")
+	require.LessOrEqual(t, len(long), 63)
+}
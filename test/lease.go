@@ -0,0 +1,322 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/stretchr/testify/require"
+)
+
+// Leases serialize access to project-wide singletons (the bootstrap KMS ring,
+// API enablement, project audit config) so that tests touching them can still
+// run with t.Parallel(). The backend is chosen by NEO4J_GKE_LEASE_BACKEND:
+//
+//	file (default)  flock in NEO4J_GKE_LEASE_DIR; one machine
+//	gcs             object in gs://$NEO4J_GKE_LEASE_BUCKET/leases/; CI
+//
+// Usage:
+//
+//	AcquireLease(t, KMSKeyRingLeaseKey(projectID, location, ringName))
+//	DeferredTerraformCleanup(t, tf) // destroy runs before the lease is released
+
+// LeaseRecord identifies the holder of a lease.
+type LeaseRecord struct {
+	Holder   string    `json:"holder"`
+	Host     string    `json:"host"`
+	PID      int       `json:"pid"`
+	Acquired time.Time `json:"acquired"`
+	Expires  time.Time `json:"expires"`
+}
+
+// LeaseBackend grants exclusive leases on named keys.
+type LeaseBackend interface {
+	// TryAcquire takes key for rec without blocking. It returns false (and no
+	// error) while another holder has a live lease.
+	TryAcquire(t *testing.T, key string, rec LeaseRecord) (bool, error)
+	// Release gives up a lease taken with rec.
+	Release(t *testing.T, key string, rec LeaseRecord) error
+}
+
+// Lease is a held lease; see AcquireLease.
+type Lease struct {
+	key     string
+	backend LeaseBackend
+	rec     LeaseRecord
+	once    sync.Once
+}
+
+// DefaultLeaseTTL bounds a lease when the test has no deadline.
+const DefaultLeaseTTL = 2 * time.Hour
+
+var (
+	// leasePollInterval is how often a blocked AcquireLease retries.
+	leasePollInterval = 5 * time.Second
+
+	invalidLeaseKeyChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// --- keys ---
+
+// KMSKeyRingLeaseKey guards a KMS key ring adopted by several tests.
+func KMSKeyRingLeaseKey(project, location, ring string) string {
+	return fmt.Sprintf("kms-key-ring/%s/%s/%s", project, location, ring)
+}
+
+// ProjectServiceLeaseKey guards enabling an API on the project.
+func ProjectServiceLeaseKey(project, service string) string {
+	return fmt.Sprintf("project-service/%s/%s", project, service)
+}
+
+// WIFPoolLeaseKey guards a workload identity pool reused, and adopted, across
+// runs.
+func WIFPoolLeaseKey(project, pool string) string {
+	return fmt.Sprintf("wif-pool/%s/%s", project, pool)
+}
+
+// AuditConfigLeaseKey guards the project's audit configs.
+func AuditConfigLeaseKey(project string) string {
+	return fmt.Sprintf("audit-config/%s", project)
+}
+
+// --- acquire/release ---
+
+// AcquireLease blocks until the lease on key is held and releases it in
+// t.Cleanup. Acquire it before registering the cleanups that must finish
+// while the lease is still held. The test fails if the lease cannot be taken
+// before its deadline.
+func AcquireLease(t *testing.T, key string) *Lease {
+	t.Helper()
+
+	backend := DefaultLeaseBackend(t)
+	host, _ := os.Hostname()
+	now := time.Now().UTC()
+	ttl := DefaultLeaseTTL
+	if deadline, ok := t.Deadline(); ok {
+		ttl = time.Until(deadline)
+	}
+	rec := LeaseRecord{
+		Holder:   fmt.Sprintf("%s/%s", TestRunID(), t.Name()),
+		Host:     host,
+		PID:      os.Getpid(),
+		Acquired: now,
+		Expires:  now.Add(ttl),
+	}
+
+	waitUntil := rec.Expires
+	for {
+		ok, err := backend.TryAcquire(t, key, rec)
+		require.NoErrorf(t, err, "failed to acquire lease %s", key)
+		if ok {
+			break
+		}
+		if time.Now().After(waitUntil) {
+			require.FailNowf(t, "lease timeout", "timed out waiting for lease %s", key)
+		}
+		t.Logf("Waiting for lease %s...", key)
+		time.Sleep(leasePollInterval)
+	}
+	t.Logf("Acquired lease %s", key)
+
+	lease := &Lease{key: key, backend: backend, rec: rec}
+	t.Cleanup(func() { lease.Release(t) })
+	return lease
+}
+
+// Release gives up the lease early; later calls (including the automatic one
+// in t.Cleanup) are no-ops. Failures are logged, since an unreleased lease
+// only delays other tests until it expires.
+func (l *Lease) Release(t *testing.T) {
+	t.Helper()
+	l.once.Do(func() {
+		if err := l.backend.Release(t, l.key, l.rec); err != nil {
+			t.Logf("WARNING: failed to release lease %s: %v", l.key, err)
+			return
+		}
+		t.Logf("Released lease %s", l.key)
+	})
+}
+
+// DefaultLeaseBackend returns the backend selected by NEO4J_GKE_LEASE_BACKEND.
+func DefaultLeaseBackend(t *testing.T) LeaseBackend {
+	t.Helper()
+	switch backend := os.Getenv("NEO4J_GKE_LEASE_BACKEND"); backend {
+	case "", "file":
+		dir := os.Getenv("NEO4J_GKE_LEASE_DIR")
+		if dir == "" {
+			dir = filepath.Join(os.TempDir(), "neo4j-gke-leases")
+		}
+		return defaultFileLeases(dir)
+	case "gcs":
+		return &GCSLeaseBackend{
+			Project: MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID"),
+			Bucket:  MustEnv(t, "NEO4J_GKE_LEASE_BUCKET"),
+		}
+	default:
+		require.FailNowf(t, "unknown lease backend", "NEO4J_GKE_LEASE_BACKEND must be file or gcs, got %q", backend)
+		return nil
+	}
+}
+
+var (
+	fileLeasesMu sync.Mutex
+	fileLeases   = map[string]*FileLeaseBackend{}
+)
+
+// defaultFileLeases shares one FileLeaseBackend per directory, so parallel
+// tests in this process contend on the same in-process lock table.
+func defaultFileLeases(dir string) *FileLeaseBackend {
+	fileLeasesMu.Lock()
+	defer fileLeasesMu.Unlock()
+	if b, ok := fileLeases[dir]; ok {
+		return b
+	}
+	b := &FileLeaseBackend{Dir: dir}
+	fileLeases[dir] = b
+	return b
+}
+
+// --- GCS backend ---
+
+// GCSLeaseBackend stores each lease as gs://<Bucket>/leases/<key>.json,
+// created with an if-generation-match=0 precondition so only one writer wins.
+// Expired leases (a crashed holder) are deleted by generation and retaken.
+type GCSLeaseBackend struct {
+	Project string
+	Bucket  string
+}
+
+func (b *GCSLeaseBackend) objectURL(key string) string {
+	return fmt.Sprintf("gs://%s/leases/%s.json", b.Bucket, key)
+}
+
+// TryAcquire implements LeaseBackend.
+func (b *GCSLeaseBackend) TryAcquire(t *testing.T, key string, rec LeaseRecord) (bool, error) {
+	t.Helper()
+
+	ok, err := b.create(t, key, rec)
+	if ok || err != nil {
+		return ok, err
+	}
+
+	current, generation, err := b.read(t, key)
+	if err != nil {
+		// Released between our create and read; try again on the next poll.
+		return false, nil
+	}
+	if time.Now().Before(current.Expires) {
+		return false, nil
+	}
+
+	t.Logf("Breaking expired lease %s held by %s", key, current.Holder)
+	if err := b.gcloud(t, "storage", "rm", b.objectURL(key), fmt.Sprintf("--if-generation-match=%d", generation)); err != nil {
+		return false, nil
+	}
+	return b.create(t, key, rec)
+}
+
+// Release implements LeaseBackend. It only deletes the object if rec still
+// holds it, so a broken lease cannot release its successor.
+func (b *GCSLeaseBackend) Release(t *testing.T, key string, rec LeaseRecord) error {
+	t.Helper()
+
+	current, generation, err := b.read(t, key)
+	if err != nil {
+		return err
+	}
+	if current.Holder != rec.Holder || !current.Acquired.Equal(rec.Acquired) {
+		return fmt.Errorf("lease is now held by %s", current.Holder)
+	}
+	return b.gcloud(t, "storage", "rm", b.objectURL(key), fmt.Sprintf("--if-generation-match=%d", generation))
+}
+
+// create writes the lease object if it does not exist.
+func (b *GCSLeaseBackend) create(t *testing.T, key string, rec LeaseRecord) (bool, error) {
+	t.Helper()
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return false, err
+	}
+	f, err := os.CreateTemp("", "lease-*.json")
+	if err != nil {
+		return false, err
+	}
+	local := f.Name()
+	defer os.Remove(local)
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return false, err
+	}
+
+	cmd := shell.Command{
+		Command: "gcloud",
+		Args: []string{"--project", b.Project, "storage", "cp", local, b.objectURL(key),
+			"--if-generation-match=0", "--quiet"},
+	}
+	out, err := shell.RunCommandAndGetOutputE(t, cmd)
+	if err == nil {
+		return true, nil
+	}
+	lower := strings.ToLower(out)
+	if strings.Contains(lower, "precondition") || strings.Contains(lower, "412") {
+		return false, nil
+	}
+	return false, fmt.Errorf("gcloud storage cp: %w", err)
+}
+
+// read returns the current lease record and object generation.
+func (b *GCSLeaseBackend) read(t *testing.T, key string) (LeaseRecord, int64, error) {
+	t.Helper()
+
+	var meta struct {
+		Generation json.Number `json:"generation"`
+	}
+	out, err := shell.RunCommandAndGetStdOutE(t, shell.Command{
+		Command: "gcloud",
+		Args:    []string{"--project", b.Project, "storage", "objects", "describe", b.objectURL(key), "--format=json"},
+	})
+	if err != nil {
+		return LeaseRecord{}, 0, err
+	}
+	if err := json.Unmarshal([]byte(out), &meta); err != nil {
+		return LeaseRecord{}, 0, err
+	}
+	generation, err := meta.Generation.Int64()
+	if err != nil {
+		return LeaseRecord{}, 0, fmt.Errorf("lease object generation: %w", err)
+	}
+
+	out, err = shell.RunCommandAndGetStdOutE(t, shell.Command{
+		Command: "gcloud",
+		Args:    []string{"--project", b.Project, "storage", "cat", fmt.Sprintf("%s#%d", b.objectURL(key), generation)},
+	})
+	if err != nil {
+		return LeaseRecord{}, 0, err
+	}
+	var rec LeaseRecord
+	if err := json.Unmarshal([]byte(out), &rec); err != nil {
+		return LeaseRecord{}, 0, fmt.Errorf("decode lease %s: %w", key, err)
+	}
+	return rec, generation, nil
+}
+
+func (b *GCSLeaseBackend) gcloud(t *testing.T, args ...string) error {
+	t.Helper()
+	return runGCLOUDNoOutE(t, b.Project, append(args, "--quiet")...)
+}
+
+// leaseFileName maps a lease key to a flat file name.
+func leaseFileName(key string) string {
+	return invalidLeaseKeyChars.ReplaceAllString(key, "_") + ".lock"
+}
@@ -0,0 +1,23 @@
+//go:build !unix
+
+package test
+
+import (
+	"fmt"
+	"testing"
+)
+
+// FileLeaseBackend requires flock(2); on other platforms use the gcs backend.
+type FileLeaseBackend struct {
+	Dir string
+}
+
+// TryAcquire implements LeaseBackend.
+func (b *FileLeaseBackend) TryAcquire(t *testing.T, key string, _ LeaseRecord) (bool, error) {
+	return false, fmt.Errorf("file leases are not supported on this platform; set NEO4J_GKE_LEASE_BACKEND=gcs")
+}
+
+// Release implements LeaseBackend.
+func (b *FileLeaseBackend) Release(t *testing.T, key string, _ LeaseRecord) error {
+	return nil
+}
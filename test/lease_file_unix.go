@@ -0,0 +1,77 @@
+//go:build unix
+
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+// FileLeaseBackend holds leases as flock(2) locks on files in Dir. Locks are
+// dropped by the kernel when the process exits, so a crashed run never leaves
+// a stale lease behind. It only coordinates processes on one machine.
+type FileLeaseBackend struct {
+	Dir string
+
+	mu   sync.Mutex
+	held map[string]*os.File
+}
+
+// TryAcquire implements LeaseBackend.
+func (b *FileLeaseBackend) TryAcquire(t *testing.T, key string, rec LeaseRecord) (bool, error) {
+	t.Helper()
+
+	if err := os.MkdirAll(b.Dir, 0o755); err != nil {
+		return false, err
+	}
+	f, err := os.OpenFile(filepath.Join(b.Dir, leaseFileName(key)), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return false, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return false, nil
+		}
+		return false, fmt.Errorf("flock %s: %w", f.Name(), err)
+	}
+
+	// Record the holder for humans inspecting a stuck run.
+	if data, err := json.Marshal(rec); err == nil {
+		_ = f.Truncate(0)
+		_, _ = f.WriteAt(append(data, '\n'), 0)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.held == nil {
+		b.held = map[string]*os.File{}
+	}
+	b.held[key] = f
+	return true, nil
+}
+
+// Release implements LeaseBackend.
+func (b *FileLeaseBackend) Release(t *testing.T, key string, _ LeaseRecord) error {
+	t.Helper()
+
+	b.mu.Lock()
+	f, ok := b.held[key]
+	delete(b.held, key)
+	b.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("lease %s is not held by this process", key)
+	}
+	_ = f.Truncate(0)
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_UN); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLeaseBackend_Exclusive(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file leases require flock")
+	}
+	backend := &FileLeaseBackend{Dir: t.TempDir()}
+	other := &FileLeaseBackend{Dir: backend.Dir}
+	rec := LeaseRecord{Holder: "run/TestA", Acquired: time.Now()}
+
+	ok, err := backend.TryAcquire(t, "kms-key-ring/p/us/ring", rec)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// A second open file description cannot take it, even in the same process.
+	ok, err = other.TryAcquire(t, "kms-key-ring/p/us/ring", LeaseRecord{Holder: "run/TestB"})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Other keys are independent.
+	ok, err = other.TryAcquire(t, "audit-config/p", LeaseRecord{Holder: "run/TestB"})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	data, err := os.ReadFile(filepath.Join(backend.Dir, "kms-key-ring_p_us_ring.lock"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "run/TestA")
+
+	require.NoError(t, backend.Release(t, "kms-key-ring/p/us/ring", rec))
+	ok, err = other.TryAcquire(t, "kms-key-ring/p/us/ring", LeaseRecord{Holder: "run/TestB"})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.Error(t, backend.Release(t, "kms-key-ring/p/us/ring", rec), "released twice")
+}
+
+func TestAcquireLease_SerializesHolders(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file leases require flock")
+	}
+	t.Setenv("NEO4J_GKE_LEASE_BACKEND", "file")
+	t.Setenv("NEO4J_GKE_LEASE_DIR", t.TempDir())
+
+	interval := leasePollInterval
+	leasePollInterval = 5 * time.Millisecond
+	t.Cleanup(func() { leasePollInterval = interval })
+
+	// Goroutines stand in for parallel tests (t.Setenv forbids t.Parallel).
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		holders int
+		maxSeen int
+	)
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease := AcquireLease(t, ProjectServiceLeaseKey("p", "secretmanager.googleapis.com"))
+			mu.Lock()
+			holders++
+			maxSeen = max(maxSeen, holders)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			holders--
+			mu.Unlock()
+			lease.Release(t)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 1, maxSeen)
+}
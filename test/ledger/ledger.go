@@ -0,0 +1,233 @@
+// Package ledger records Terraform workspaces whose destroy failed, so the
+// resources they still track can be destroyed later (see cmd/orphans).
+//
+// A ledger is a directory holding ledger.jsonl, an append-only list of
+// Records, plus one sub-directory per orphan with a copy of its state file and
+// variables. A successful replay appends a second record with the same ID and
+// Resolved set; Load folds the two.
+package ledger
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// FileName is the ledger index inside the ledger directory.
+	FileName = "ledger.jsonl"
+	// StateFile and VarFile are the copies kept in each entry directory.
+	StateFile = "terraform.tfstate"
+	VarFile   = "terraform.tfvars.json"
+)
+
+// Record describes one orphaned Terraform workspace.
+type Record struct {
+	ID       string    `json:"id"`
+	RunID    string    `json:"run_id,omitempty"`
+	Test     string    `json:"test"`
+	Module   string    `json:"module"` // repo-relative, e.g. infra/modules/vpc
+	Recorded time.Time `json:"recorded"`
+	// Dir is the entry directory relative to the ledger directory.
+	Dir       string   `json:"dir"`
+	Addresses []string `json:"addresses"`
+	// EnvVarNames lists the environment variables the destroy ran with; their
+	// values are in env.json in the entry directory, except for the ones in
+	// RedactedEnvVars.
+	EnvVarNames []string `json:"env_var_names,omitempty"`
+	// SensitiveVars are variables declared sensitive, and RedactedEnvVars
+	// environment variables holding credentials. Their values are not saved;
+	// replay takes them from its own environment (TF_VAR_<name> for variables).
+	SensitiveVars   []string `json:"sensitive_vars,omitempty"`
+	RedactedEnvVars []string `json:"redacted_env_vars,omitempty"`
+	Error           string   `json:"error,omitempty"`
+
+	Resolved *time.Time `json:"resolved,omitempty"`
+}
+
+// Ledger is an orphan ledger rooted at Dir.
+type Ledger struct {
+	Dir string
+}
+
+// appendMu serializes appends from parallel tests in one process; separate
+// processes rely on O_APPEND writes of a single line.
+var appendMu sync.Mutex
+
+// DefaultDir returns $NEO4J_GKE_ORPHAN_LEDGER_DIR, or neo4j-gke/orphans under
+// the user cache directory. It must outlive the test's temp directories.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("NEO4J_GKE_ORPHAN_LEDGER_DIR"); dir != "" {
+		return dir, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate user cache dir (set NEO4J_GKE_ORPHAN_LEDGER_DIR): %w", err)
+	}
+	return filepath.Join(cache, "neo4j-gke", "orphans"), nil
+}
+
+// EntryDir returns the absolute directory of a record's state and vars.
+func (l Ledger) EntryDir(r Record) string {
+	return filepath.Join(l.Dir, r.Dir)
+}
+
+// NewEntry creates a fresh entry directory for id and returns its path.
+func (l Ledger) NewEntry(id string) (string, error) {
+	dir := filepath.Join(l.Dir, id)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Append adds r to the ledger index.
+func (l Ledger) Append(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	appendMu.Lock()
+	defer appendMu.Unlock()
+
+	if err := os.MkdirAll(l.Dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(l.Dir, FileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Resolve records that r has been destroyed.
+func (l Ledger) Resolve(r Record, at time.Time) error {
+	r.Resolved = &at
+	return l.Append(r)
+}
+
+// Load returns every record, oldest first, with resolutions folded in.
+// A missing ledger is empty.
+func (l Ledger) Load() ([]Record, error) {
+	f, err := os.Open(filepath.Join(l.Dir, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return decode(f)
+}
+
+// Outstanding returns the records that have not been resolved.
+func (l Ledger) Outstanding() ([]Record, error) {
+	all, err := l.Load()
+	if err != nil {
+		return nil, err
+	}
+	var out []Record
+	for _, r := range all {
+		if r.Resolved == nil {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func decode(r io.Reader) ([]Record, error) {
+	byID := map[string]*Record{}
+	var order []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", FileName, line, err)
+		}
+		existing, ok := byID[rec.ID]
+		switch {
+		case !ok:
+			byID[rec.ID] = &rec
+			order = append(order, rec.ID)
+		case rec.Resolved != nil:
+			existing.Resolved = rec.Resolved
+		default:
+			*existing = rec
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]Record, 0, len(order))
+	for _, id := range order {
+		out = append(out, *byID[id])
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Recorded.Before(out[j].Recorded) })
+	return out, nil
+}
+
+// --- state ---
+
+// stateV4 is the subset of the Terraform/OpenTofu state format we read.
+type stateV4 struct {
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey any `json:"index_key"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// Addresses returns the managed resource addresses tracked by a state file,
+// e.g. module.gke.google_container_cluster.autopilot or
+// google_secret_manager_secret.this["neo4j"].
+func Addresses(state []byte) ([]string, error) {
+	var s stateV4
+	if err := json.Unmarshal(state, &s); err != nil {
+		return nil, fmt.Errorf("decode state: %w", err)
+	}
+	var out []string
+	for _, r := range s.Resources {
+		if r.Mode != "managed" {
+			continue
+		}
+		base := r.Type + "." + r.Name
+		if r.Module != "" {
+			base = r.Module + "." + base
+		}
+		for _, inst := range r.Instances {
+			switch k := inst.IndexKey.(type) {
+			case nil:
+				out = append(out, base)
+			case string:
+				out = append(out, fmt.Sprintf("%s[%q]", base, k))
+			case float64:
+				out = append(out, fmt.Sprintf("%s[%d]", base, int(k)))
+			default:
+				out = append(out, fmt.Sprintf("%s[%v]", base, k))
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,57 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedger_AppendLoadResolve(t *testing.T) {
+	l := Ledger{Dir: t.TempDir()}
+	t0 := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, l.Append(Record{ID: "a", Module: "infra/modules/vpc", Recorded: t0}))
+	require.NoError(t, l.Append(Record{ID: "b", Module: "infra/modules/gke", Recorded: t0.Add(time.Minute)}))
+	require.NoError(t, l.Resolve(Record{ID: "a", Module: "infra/modules/vpc", Recorded: t0}, t0.Add(time.Hour)))
+
+	all, err := l.Load()
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	require.Equal(t, "a", all[0].ID)
+	require.NotNil(t, all[0].Resolved)
+	require.Nil(t, all[1].Resolved)
+
+	outstanding, err := l.Outstanding()
+	require.NoError(t, err)
+	require.Len(t, outstanding, 1)
+	require.Equal(t, "b", outstanding[0].ID)
+}
+
+func TestLedger_LoadMissing(t *testing.T) {
+	records, err := Ledger{Dir: t.TempDir()}.Load()
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
+
+func TestAddresses(t *testing.T) {
+	state := []byte(`{
+	  "version": 4,
+	  "resources": [
+	    {"mode": "data", "type": "google_project", "name": "this", "instances": [{}]},
+	    {"mode": "managed", "type": "google_compute_network", "name": "vpc", "instances": [{}]},
+	    {"mode": "managed", "type": "google_compute_router", "name": "router", "instances": [{"index_key": 0}]},
+	    {"module": "module.secrets", "mode": "managed", "type": "google_secret_manager_secret", "name": "this",
+	     "instances": [{"index_key": "neo4j"}, {"index_key": "other"}]}
+	  ]
+	}`)
+
+	got, err := Addresses(state)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"google_compute_network.vpc",
+		"google_compute_router.router[0]",
+		`module.secrets.google_secret_manager_secret.this["neo4j"]`,
+		`module.secrets.google_secret_manager_secret.this["other"]`,
+	}, got)
+}
@@ -0,0 +1,621 @@
+// Code generated by cmd/optgen from infra/modules/*/variables.tf; DO NOT EDIT.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+)
+
+// AuditLoggingOptions holds the input variables of infra/modules/audit_logging.
+// Optional variables left nil use the module default.
+type AuditLoggingOptions struct {
+	// GCP project ID. Required.
+	ProjectID string `tf:"project_id,required"`
+
+	// Location for logs bucket. Default: "US".
+	LogsBucketLocation *string `tf:"logs_bucket_location"`
+
+	// Custom name for logs bucket. Default: null.
+	LogsBucketName *string `tf:"logs_bucket_name"`
+
+	// Enable Cloud Audit Logs for Cloud KMS DATA_READ and DATA_WRITE. Default:
+	// true.
+	EnableKMSAuditLogs *bool `tf:"enable_kms_audit_logs"`
+
+	// Enable Cloud Audit Logs for Cloud Storage DATA_READ and DATA_WRITE. Default:
+	// true.
+	EnableGCSAuditLogs *bool `tf:"enable_gcs_audit_logs"`
+
+	// Enable Cloud Audit Logs for GKE Container API DATA_READ and DATA_WRITE.
+	// Default: false.
+	EnableContainerAuditLogs *bool `tf:"enable_container_audit_logs"`
+
+	// List of identities exempt from audit logging (e.g., serviceAccount:...).
+	// Default: [].
+	AuditLogExemptedMembers []string `tf:"audit_log_exempted_members"`
+
+	// Create Cloud Logging sink to route audit logs to GCS bucket. Default: true.
+	EnableLogSink *bool `tf:"enable_log_sink"`
+
+	// Custom name for the log sink. Default: null.
+	LogSinkName *string `tf:"log_sink_name"`
+
+	// Number of days to retain logs in the bucket before deletion. Default: 365.
+	LogRetentionDays *int `tf:"log_retention_days"`
+
+	// Labels to apply to logging resources. Default: {}.
+	Labels map[string]string `tf:"labels"`
+}
+
+// Module returns the module directory relative to infra/modules.
+func (o *AuditLoggingOptions) Module() string { return "audit_logging" }
+
+// Vars returns the non-nil fields keyed by variable name.
+func (o *AuditLoggingOptions) Vars(t *testing.T) map[string]any {
+	t.Helper()
+	return moduleVars(t, o)
+}
+
+// TerraformOptions returns tofu options for the module copy in terraformDir
+// (see NewTerraformOptions).
+func (o *AuditLoggingOptions) TerraformOptions(t *testing.T, terraformDir string) *terraform.Options {
+	t.Helper()
+	return moduleTerraformOptions(t, terraformDir, o.Vars(t))
+}
+
+// BackupBucketOptions holds the input variables of infra/modules/backup_bucket.
+// Optional variables left nil use the module default.
+type BackupBucketOptions struct {
+	// GCP project to create the backup bucket in. Required.
+	ProjectID string `tf:"project_id,required"`
+
+	// Globally unique name for the backup bucket. Required.
+	BucketName string `tf:"bucket_name,required"`
+
+	// GCS location for the bucket (region, dual-region, or multi-region).
+	// Required.
+	Location string `tf:"location,required"`
+
+	// Email of the service account that will write backups. Required.
+	BackupSAEmail string `tf:"backup_sa_email,required"`
+
+	// Storage class for the bucket. Default: "STANDARD".
+	StorageClass *string `tf:"storage_class"`
+
+	// Number of days to retain backup objects before deletion (0 = no
+	// auto-delete). Default: 30.
+	BackupRetentionDays *int `tf:"backup_retention_days"`
+
+	// Number of noncurrent versions to retain (0 = delete immediately). Default:
+	// 5.
+	BackupVersionsToKeep *int `tf:"backup_versions_to_keep"`
+
+	// Allow bucket destruction even with objects (use only in dev). Default:
+	// false.
+	ForceDestroy *bool `tf:"force_destroy"`
+
+	// Enable object versioning for recovery of deleted/overwritten backups.
+	// Default: true.
+	EnableVersioning *bool `tf:"enable_versioning"`
+
+	// Labels to apply to the bucket. Default: {}.
+	Labels map[string]string `tf:"labels"`
+
+	// KMS key for bucket encryption. Default: null.
+	KMSKeyName *string `tf:"kms_key_name"`
+}
+
+// Module returns the module directory relative to infra/modules.
+func (o *BackupBucketOptions) Module() string { return "backup_bucket" }
+
+// Vars returns the non-nil fields keyed by variable name.
+func (o *BackupBucketOptions) Vars(t *testing.T) map[string]any {
+	t.Helper()
+	return moduleVars(t, o)
+}
+
+// TerraformOptions returns tofu options for the module copy in terraformDir
+// (see NewTerraformOptions).
+func (o *BackupBucketOptions) TerraformOptions(t *testing.T, terraformDir string) *terraform.Options {
+	t.Helper()
+	return moduleTerraformOptions(t, terraformDir, o.Vars(t))
+}
+
+// GKEOptions holds the input variables of infra/modules/gke.
+// Optional variables left nil use the module default.
+type GKEOptions struct {
+	// GCP project to create GKE cluster in. Required.
+	ProjectID string `tf:"project_id,required"`
+
+	// GCP region for the GKE cluster. Required.
+	Region string `tf:"region,required"`
+
+	// Name of the GKE Autopilot cluster. Default: "neo4j-cluster".
+	ClusterName *string `tf:"cluster_name"`
+
+	// The VPC network ID (self_link or ID) for the cluster. Required.
+	NetworkID string `tf:"network_id,required"`
+
+	// The subnet ID (self_link or ID) for the cluster. Required.
+	SubnetID string `tf:"subnet_id,required"`
+
+	// Name of the secondary IP range for pods. Required.
+	PodsRangeName string `tf:"pods_range_name,required"`
+
+	// Name of the secondary IP range for services. Required.
+	ServicesRangeName string `tf:"services_range_name,required"`
+
+	// CIDR block for the GKE master network (must be /28). Default:
+	// "172.16.0.0/28".
+	MasterIPv4CIDR *string `tf:"master_ipv4_cidr"`
+
+	// Whether the master's internal IP address is used as the cluster endpoint.
+	// Default: false.
+	EnablePrivateEndpoint *bool `tf:"enable_private_endpoint"`
+
+	// List of CIDR blocks authorized to access the master endpoint. Default: [].
+	MasterAuthorizedNetworks []GKEMasterAuthorizedNetwork `tf:"master_authorized_networks"`
+
+	// GKE release channel: RAPID, REGULAR, or STABLE. Default: "REGULAR".
+	ReleaseChannel *string `tf:"release_channel"`
+
+	// Start time for the maintenance window in RFC3339 format. Default:
+	// "2025-01-01T09:00:00Z".
+	MaintenanceStartTime *string `tf:"maintenance_start_time"`
+
+	// End time for the maintenance window in RFC3339 format. Default:
+	// "2025-01-01T17:00:00Z".
+	MaintenanceEndTime *string `tf:"maintenance_end_time"`
+
+	// RRULE for maintenance window recurrence. Default: "FREQ=WEEKLY;BYDAY=SA,SU".
+	MaintenanceRecurrence *string `tf:"maintenance_recurrence"`
+
+	// Whether to enable deletion protection for the cluster. Default: true.
+	DeletionProtection *bool `tf:"deletion_protection"`
+
+	// Whether to enable the Container API. Default: true.
+	EnableContainerAPI *bool `tf:"enable_container_api"`
+
+	// Labels to apply to the cluster. Default: {}.
+	Labels map[string]string `tf:"labels"`
+}
+
+// Module returns the module directory relative to infra/modules.
+func (o *GKEOptions) Module() string { return "gke" }
+
+// Vars returns the non-nil fields keyed by variable name.
+func (o *GKEOptions) Vars(t *testing.T) map[string]any {
+	t.Helper()
+	return moduleVars(t, o)
+}
+
+// TerraformOptions returns tofu options for the module copy in terraformDir
+// (see NewTerraformOptions).
+func (o *GKEOptions) TerraformOptions(t *testing.T, terraformDir string) *terraform.Options {
+	t.Helper()
+	return moduleTerraformOptions(t, terraformDir, o.Vars(t))
+}
+
+// GKEMasterAuthorizedNetwork is the object type behind the "master_authorized_network" variable.
+type GKEMasterAuthorizedNetwork struct {
+	CIDRBlock   string `tf:"cidr_block,required"`
+	DisplayName string `tf:"display_name,required"`
+}
+
+// NeoAppOptions holds the input variables of infra/modules/neo4j_app.
+// Optional variables left nil use the module default.
+type NeoAppOptions struct {
+	// GCP project ID. Required.
+	ProjectID string `tf:"project_id,required"`
+
+	// Workload Identity pool (format: PROJECT_ID.svc.id.goog). Required.
+	WorkloadIdentityPool string `tf:"workload_identity_pool,required"`
+
+	// Email of the GCP service account for backups. Required.
+	BackupGSAEmail string `tf:"backup_gsa_email,required"`
+
+	// Full resource name of the backup service account. Required.
+	BackupGSAName string `tf:"backup_gsa_name,required"`
+
+	// GCS bucket URL for Neo4j backups. Required.
+	BackupBucketURL string `tf:"backup_bucket_url,required"`
+
+	// Bind the backup KSA to backup_gsa_name through Workload Identity. Default:
+	// true.
+	EnableWorkloadIdentity *bool `tf:"enable_workload_identity"`
+
+	// Secret Manager secret ID for Neo4j password. Default: null.
+	Neo4jPasswordSecretID *string `tf:"neo4j_password_secret_id"`
+
+	// Environment name (e.g., dev, staging, prod). Default: "dev".
+	Environment *string `tf:"environment"`
+
+	// Version of the Neo4j Helm chart to deploy. Default: "2025.10.1".
+	Neo4jChartVersion *string `tf:"neo4j_chart_version"`
+
+	// Kubernetes namespace for Neo4j deployment. Default: "neo4j".
+	Neo4jNamespace *string `tf:"neo4j_namespace"`
+
+	// Name for the Neo4j instance. Default: "neo4j-dev".
+	Neo4jInstanceName *string `tf:"neo4j_instance_name"`
+
+	// Storage size for Neo4j data volume. Default: "10Gi".
+	Neo4jStorageSize *string `tf:"neo4j_storage_size"`
+
+	// Enable HTTP for Neo4j Browser access (port 7474). Default: true.
+	EnableNeo4jBrowser *bool `tf:"enable_neo4j_browser"`
+
+	// Additional namespaces allowed to access Neo4j (e.g., for monitoring,
+	// application pods). Default: [].
+	AllowedIngressNamespaces []string `tf:"allowed_ingress_namespaces"`
+
+	// Helm repository URL for Neo4j chart. Default:
+	// "https://helm.neo4j.com/neo4j".
+	Neo4jHelmRepository *string `tf:"neo4j_helm_repository"`
+
+	// Name of an existing Kubernetes Secret containing the Neo4j password.
+	// Default: null.
+	Neo4jPasswordK8sSecret *string `tf:"neo4j_password_k8s_secret"`
+
+	// Label value to identify backup pods for network policy. Default:
+	// "neo4j-backup".
+	BackupPodLabel *string `tf:"backup_pod_label"`
+
+	// Allow external access to Neo4j via the LoadBalancer. Default: false.
+	EnableExternalAccess *bool `tf:"enable_external_access"`
+
+	// Neo4j admin password (direct input). Default: null.
+	Neo4jPassword *string `tf:"neo4j_password"`
+}
+
+// Module returns the module directory relative to infra/modules.
+func (o *NeoAppOptions) Module() string { return "neo4j_app" }
+
+// Vars returns the non-nil fields keyed by variable name.
+func (o *NeoAppOptions) Vars(t *testing.T) map[string]any {
+	t.Helper()
+	return moduleVars(t, o)
+}
+
+// TerraformOptions returns tofu options for the module copy in terraformDir
+// (see NewTerraformOptions).
+func (o *NeoAppOptions) TerraformOptions(t *testing.T, terraformDir string) *terraform.Options {
+	t.Helper()
+	return moduleTerraformOptions(t, terraformDir, o.Vars(t))
+}
+
+// NeoAppE2EOptions holds the input variables of infra/modules/neo4j_app/tests/e2e.
+// Optional variables left nil use the module default.
+type NeoAppE2EOptions struct {
+	// GCP project ID. Required.
+	ProjectID string `tf:"project_id,required"`
+
+	// GCP region. Required.
+	Region string `tf:"region,required"`
+
+	// GKE cluster name. Required.
+	ClusterName string `tf:"cluster_name,required"`
+
+	// GKE cluster location (region or zone). Required.
+	ClusterLocation string `tf:"cluster_location,required"`
+
+	// Workload Identity pool (format: PROJECT_ID.svc.id.goog). Required.
+	WorkloadIdentityPool string `tf:"workload_identity_pool,required"`
+
+	// Email of the GCP service account for backups. Required.
+	BackupGSAEmail string `tf:"backup_gsa_email,required"`
+
+	// Full resource name of the backup service account. Required.
+	BackupGSAName string `tf:"backup_gsa_name,required"`
+
+	// GCS bucket URL for Neo4j backups. Required.
+	BackupBucketURL string `tf:"backup_bucket_url,required"`
+
+	// Neo4j admin password. Required.
+	Neo4jPassword string `tf:"neo4j_password,required"`
+
+	// Kubernetes namespace for Neo4j deployment. Default: "neo4j".
+	Neo4jNamespace *string `tf:"neo4j_namespace"`
+
+	// Name for the Neo4j instance. Default: "neo4j-test".
+	Neo4jInstanceName *string `tf:"neo4j_instance_name"`
+
+	// Label value to identify backup pods for network policy. Default:
+	// "neo4j-backup".
+	BackupPodLabel *string `tf:"backup_pod_label"`
+
+	// Enable HTTP for Neo4j Browser access (port 7474). Default: true.
+	EnableNeo4jBrowser *bool `tf:"enable_neo4j_browser"`
+
+	// Allow ingress from any IP on the Neo4j ports. Default: false.
+	EnableExternalAccess *bool `tf:"enable_external_access"`
+
+	// Additional namespaces allowed to access Neo4j. Default: [].
+	AllowedIngressNamespaces []string `tf:"allowed_ingress_namespaces"`
+}
+
+// Module returns the module directory relative to infra/modules.
+func (o *NeoAppE2EOptions) Module() string { return "neo4j_app/tests/e2e" }
+
+// Vars returns the non-nil fields keyed by variable name.
+func (o *NeoAppE2EOptions) Vars(t *testing.T) map[string]any {
+	t.Helper()
+	return moduleVars(t, o)
+}
+
+// TerraformOptions returns tofu options for the module copy in terraformDir
+// (see NewTerraformOptions).
+func (o *NeoAppE2EOptions) TerraformOptions(t *testing.T, terraformDir string) *terraform.Options {
+	t.Helper()
+	return moduleTerraformOptions(t, terraformDir, o.Vars(t))
+}
+
+// NeoAppLocalOptions holds the input variables of infra/modules/neo4j_app/tests/local.
+// Optional variables left nil use the module default.
+type NeoAppLocalOptions struct {
+	// Path to the kubeconfig of the kind cluster. Required.
+	KubeconfigPath string `tf:"kubeconfig_path,required"`
+
+	// Kubeconfig context of the kind cluster (kind-<cluster name>). Required.
+	KubeContext string `tf:"kube_context,required"`
+
+	// Neo4j admin password. Required.
+	Neo4jPassword string `tf:"neo4j_password,required"`
+
+	// Kubernetes namespace for Neo4j deployment. Default: "neo4j".
+	Neo4jNamespace *string `tf:"neo4j_namespace"`
+
+	// Name for the Neo4j instance. Default: "neo4j-local".
+	Neo4jInstanceName *string `tf:"neo4j_instance_name"`
+
+	// Version of the Neo4j Helm chart to deploy (null for the module default).
+	// Default: null.
+	Neo4jChartVersion *string `tf:"neo4j_chart_version"`
+
+	// Storage size for Neo4j data volume. Default: "2Gi".
+	Neo4jStorageSize *string `tf:"neo4j_storage_size"`
+
+	// Label value to identify backup pods for network policy. Default:
+	// "neo4j-backup".
+	BackupPodLabel *string `tf:"backup_pod_label"`
+
+	// Enable HTTP for Neo4j Browser access (port 7474). Default: true.
+	EnableNeo4jBrowser *bool `tf:"enable_neo4j_browser"`
+
+	// Additional namespaces allowed to access Neo4j. Default: [].
+	AllowedIngressNamespaces []string `tf:"allowed_ingress_namespaces"`
+}
+
+// Module returns the module directory relative to infra/modules.
+func (o *NeoAppLocalOptions) Module() string { return "neo4j_app/tests/local" }
+
+// Vars returns the non-nil fields keyed by variable name.
+func (o *NeoAppLocalOptions) Vars(t *testing.T) map[string]any {
+	t.Helper()
+	return moduleVars(t, o)
+}
+
+// TerraformOptions returns tofu options for the module copy in terraformDir
+// (see NewTerraformOptions).
+func (o *NeoAppLocalOptions) TerraformOptions(t *testing.T, terraformDir string) *terraform.Options {
+	t.Helper()
+	return moduleTerraformOptions(t, terraformDir, o.Vars(t))
+}
+
+// SecretsOptions holds the input variables of infra/modules/secrets.
+// Optional variables left nil use the module default.
+type SecretsOptions struct {
+	// GCP project to create secrets in. Required.
+	ProjectID string `tf:"project_id,required"`
+
+	// Map of secret-key => object describing each secret. Default: {}.
+	Secrets map[string]SecretsSecret `tf:"secrets"`
+
+	// Map of secret-key => list of IAM members to grant secretAccessor role.
+	// Default: {}.
+	Accessors map[string][]string `tf:"accessors"`
+
+	// List of locations for user_managed replication. Default:
+	// ["us-central1","us-east1"].
+	ReplicationLocations []string `tf:"replication_locations"`
+
+	// Whether to enable the Secret Manager API. Default: true.
+	EnableSecretManagerAPI *bool `tf:"enable_secret_manager_api"`
+}
+
+// Module returns the module directory relative to infra/modules.
+func (o *SecretsOptions) Module() string { return "secrets" }
+
+// Vars returns the non-nil fields keyed by variable name.
+func (o *SecretsOptions) Vars(t *testing.T) map[string]any {
+	t.Helper()
+	return moduleVars(t, o)
+}
+
+// TerraformOptions returns tofu options for the module copy in terraformDir
+// (see NewTerraformOptions).
+func (o *SecretsOptions) TerraformOptions(t *testing.T, terraformDir string) *terraform.Options {
+	t.Helper()
+	return moduleTerraformOptions(t, terraformDir, o.Vars(t))
+}
+
+// SecretsSecret is the object type behind the "secret" variable.
+type SecretsSecret struct {
+	Description    string            `tf:"description,required"`
+	ExpireTime     *string           `tf:"expire_time"`
+	Labels         map[string]string `tf:"labels"`
+	Replication    *string           `tf:"replication"`
+	TTL            *string           `tf:"ttl"`
+	VersionAliases map[string]string `tf:"version_aliases"`
+}
+
+// ServiceAccountsOptions holds the input variables of infra/modules/service_accounts.
+// Optional variables left nil use the module default.
+type ServiceAccountsOptions struct {
+	// GCP project to create service accounts in. Required.
+	ProjectID string `tf:"project_id,required"`
+
+	// Map of service-account-key => object describing each account. Default: {}.
+	ServiceAccounts map[string]ServiceAccountsServiceAccount `tf:"service_accounts"`
+
+	// Optional prefix applied to every service account ID (sanitized). Default:
+	// "".
+	SAPrefix *string `tf:"sa_prefix"`
+
+	// Optional suffix applied to every service account ID (sanitized). Default:
+	// "".
+	SASuffix *string `tf:"sa_suffix"`
+
+	// Optional stable input whose sha1 (6 hex) is appended to the ID to reduce
+	// collisions. Default: null.
+	IDHashSuffixFrom *string `tf:"id_hash_suffix_from"`
+
+	// Protect service accounts from accidental destroy (recommended for prod).
+	// Default: true.
+	PreventDestroyServiceAccounts *bool `tf:"prevent_destroy_service_accounts"`
+}
+
+// Module returns the module directory relative to infra/modules.
+func (o *ServiceAccountsOptions) Module() string { return "service_accounts" }
+
+// Vars returns the non-nil fields keyed by variable name.
+func (o *ServiceAccountsOptions) Vars(t *testing.T) map[string]any {
+	t.Helper()
+	return moduleVars(t, o)
+}
+
+// TerraformOptions returns tofu options for the module copy in terraformDir
+// (see NewTerraformOptions).
+func (o *ServiceAccountsOptions) TerraformOptions(t *testing.T, terraformDir string) *terraform.Options {
+	t.Helper()
+	return moduleTerraformOptions(t, terraformDir, o.Vars(t))
+}
+
+// ServiceAccountsServiceAccount is the object type behind the "service_account" variable.
+type ServiceAccountsServiceAccount struct {
+	Description string  `tf:"description,required"`
+	Disabled    *bool   `tf:"disabled"`
+	DisplayName *string `tf:"display_name"`
+}
+
+// VPCOptions holds the input variables of infra/modules/vpc.
+// Optional variables left nil use the module default.
+type VPCOptions struct {
+	// GCP project to create VPC resources in. Required.
+	ProjectID string `tf:"project_id,required"`
+
+	// GCP region for regional resources (subnet, Cloud NAT). Required.
+	Region string `tf:"region,required"`
+
+	// Name of the VPC network. Default: "neo4j-vpc".
+	VPCName *string `tf:"vpc_name"`
+
+	// Name of the subnet. Default: null.
+	SubnetName *string `tf:"subnet_name"`
+
+	// Primary IP CIDR range for the subnet (node IPs). Default: "10.0.0.0/24".
+	SubnetIPRange *string `tf:"subnet_ip_range"`
+
+	// Secondary IP CIDR range for GKE pods. Default: "10.1.0.0/16".
+	PodsIPRange *string `tf:"pods_ip_range"`
+
+	// Secondary IP CIDR range for GKE services. Default: "10.2.0.0/20".
+	ServicesIPRange *string `tf:"services_ip_range"`
+
+	// Name for the pods secondary range. Default: "pods".
+	PodsRangeName *string `tf:"pods_range_name"`
+
+	// Name for the services secondary range. Default: "services".
+	ServicesRangeName *string `tf:"services_range_name"`
+
+	// Whether to create Cloud NAT for egress from private nodes. Default: true.
+	EnableCloudNAT *bool `tf:"enable_cloud_nat"`
+
+	// Enable VPC flow logs on the subnet. Default: true.
+	EnableFlowLogs *bool `tf:"enable_flow_logs"`
+
+	// How NAT IPs are allocated: AUTO_ONLY or MANUAL_ONLY. Default: "AUTO_ONLY".
+	NATIPAllocateOption *string `tf:"nat_ip_allocate_option"`
+
+	// List of self_links of reserved external IPs for Cloud NAT. Default: [].
+	NATIPs []string `tf:"nat_ips"`
+
+	// Which subnet IP ranges to NAT. Default: "ALL_SUBNETWORKS_ALL_IP_RANGES".
+	NATSourceSubnetworkIPRangesToNAT *string `tf:"nat_source_subnetwork_ip_ranges_to_nat"`
+}
+
+// Module returns the module directory relative to infra/modules.
+func (o *VPCOptions) Module() string { return "vpc" }
+
+// Vars returns the non-nil fields keyed by variable name.
+func (o *VPCOptions) Vars(t *testing.T) map[string]any {
+	t.Helper()
+	return moduleVars(t, o)
+}
+
+// TerraformOptions returns tofu options for the module copy in terraformDir
+// (see NewTerraformOptions).
+func (o *VPCOptions) TerraformOptions(t *testing.T, terraformDir string) *terraform.Options {
+	t.Helper()
+	return moduleTerraformOptions(t, terraformDir, o.Vars(t))
+}
+
+// WIFOptions holds the input variables of infra/modules/wif.
+// Optional variables left nil use the module default.
+type WIFOptions struct {
+	// GCP project in which to create the WIF pool and provider. Required.
+	ProjectID string `tf:"project_id,required"`
+
+	// Workload Identity Pool ID (short name). Default: "github-pool".
+	PoolID *string `tf:"pool_id"`
+
+	// Workload Identity Provider ID (short name). Default: "github-actions".
+	ProviderID *string `tf:"provider_id"`
+
+	// OIDC issuer. Default: "https://token.actions.githubusercontent.com".
+	IssuerURI *string `tf:"issuer_uri"`
+
+	// Set of allowed GitHub repositories (org/repo). Default: [].
+	AllowedRepositories []string `tf:"allowed_repositories"`
+
+	// Optional allowed GitHub orgs (repository_owner). Default: [].
+	AllowedRepositoryOwners []string `tf:"allowed_repository_owners"`
+
+	// Optional list of refs to allow. Default: [].
+	AllowedRefs []string `tf:"allowed_refs"`
+
+	// Optional allowed audience (aud) values for the OIDC token. Default: [].
+	AllowedAudiences []string `tf:"allowed_audiences"`
+
+	// Optional extra assertion -> attribute pairs to merge into the base mapping.
+	// Default: {}.
+	AttributeMappingExtra map[string]string `tf:"attribute_mapping_extra"`
+
+	// If set, use this CEL expression verbatim for provider.attribute_condition.
+	// Default: null.
+	AttributeConditionOverride *string `tf:"attribute_condition_override"`
+
+	// Prevent accidental destroy of the pool in production. Default: true.
+	PreventDestroyPool *bool `tf:"prevent_destroy_pool"`
+
+	// Prevent accidental destroy of the provider in production. Default: true.
+	PreventDestroyProvider *bool `tf:"prevent_destroy_provider"`
+}
+
+// Module returns the module directory relative to infra/modules.
+func (o *WIFOptions) Module() string { return "wif" }
+
+// Vars returns the non-nil fields keyed by variable name.
+func (o *WIFOptions) Vars(t *testing.T) map[string]any {
+	t.Helper()
+	return moduleVars(t, o)
+}
+
+// TerraformOptions returns tofu options for the module copy in terraformDir
+// (see NewTerraformOptions).
+func (o *WIFOptions) TerraformOptions(t *testing.T, terraformDir string) *terraform.Options {
+	t.Helper()
+	return moduleTerraformOptions(t, terraformDir, o.Vars(t))
+}
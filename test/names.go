@@ -0,0 +1,71 @@
+package test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/stretchr/testify/require"
+)
+
+// NameKind describes the naming limits of a GCP resource type.
+type NameKind struct {
+	Kind string
+	// MaxLen is the longest name the tests may pass in. It is lower than the
+	// API limit when a module derives other names from it.
+	MaxLen int
+}
+
+// Name limits for resources created by the tests.
+var (
+	// The vpc module derives <vpc>-router, <vpc>-nat and <vpc>-subnet (63 max).
+	NameVPC            = NameKind{Kind: "vpc", MaxLen: 63 - len("-subnet")}
+	NameCluster        = NameKind{Kind: "cluster", MaxLen: 40}
+	NameBucket         = NameKind{Kind: "bucket", MaxLen: 63}
+	NameServiceAccount = NameKind{Kind: "service-account", MaxLen: 30}
+	NameSecret         = NameKind{Kind: "secret", MaxLen: 255}
+	NameWIFPool        = NameKind{Kind: "wif-pool", MaxLen: 32}
+	NameLogSink        = NameKind{Kind: "log-sink", MaxLen: 100}
+)
+
+// nameSuffixLen is the length of the random suffix UniqueName appends.
+const nameSuffixLen = 6
+
+var (
+	allocatedMu    sync.Mutex
+	allocatedNames = map[string]bool{}
+
+	validNamePrefix = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+)
+
+// UniqueName returns prefix-<suffix>, where the random suffix makes the name
+// unique across the project and is never handed out twice in this process.
+// When the result would exceed kind.MaxLen the prefix is shortened, never the
+// suffix, so parallel tests cannot collide on truncated names.
+func UniqueName(t *testing.T, kind NameKind, prefix string) string {
+	t.Helper()
+
+	require.Regexpf(t, validNamePrefix, prefix,
+		"name prefix %q must start with a lowercase letter and contain only [a-z0-9-]", prefix)
+	require.Greaterf(t, kind.MaxLen, nameSuffixLen+1, "%s names are too short for a unique suffix", kind.Kind)
+
+	if max := kind.MaxLen - nameSuffixLen - 1; len(prefix) > max {
+		prefix = strings.TrimRight(prefix[:max], "-")
+	}
+
+	allocatedMu.Lock()
+	defer allocatedMu.Unlock()
+	for range 10 {
+		name := fmt.Sprintf("%s-%s", prefix, strings.ToLower(random.UniqueId()))
+		key := kind.Kind + "/" + name
+		if !allocatedNames[key] {
+			allocatedNames[key] = true
+			return name
+		}
+	}
+	require.FailNowf(t, "name allocation failed", "could not allocate a unique %s name for prefix %q", kind.Kind, prefix)
+	return ""
+}
@@ -0,0 +1,32 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueName_RespectsLimits(t *testing.T) {
+	name := UniqueName(t, NameCluster, "gke-test")
+	require.Regexp(t, `^gke-test-[a-z0-9]{6}$`, name)
+
+	// Long prefixes are shortened, the suffix is kept.
+	name = UniqueName(t, NameWIFPool, "gha-terratest-with-a-lon-prefix")
+	require.LessOrEqual(t, len(name), NameWIFPool.MaxLen)
+	require.Regexp(t, `^gha-terratest-with-a-lon-[a-z0-9]{6}$`, name)
+	require.NotContains(t, name, "--")
+
+	// Room is left for names derived by the vpc module.
+	name = UniqueName(t, NameVPC, strings.Repeat("v", 80))
+	require.LessOrEqual(t, len(name+"-subnet"), 63)
+}
+
+func TestUniqueName_NoDuplicates(t *testing.T) {
+	seen := map[string]bool{}
+	for range 2000 {
+		name := UniqueName(t, NameSecret, "test-secret")
+		require.False(t, seen[name], "duplicate name %s", name)
+		seen[name] = true
+	}
+}
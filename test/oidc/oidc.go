@@ -0,0 +1,256 @@
+// Package oidc is a stand-in for an OIDC token issuer such as
+// token.actions.githubusercontent.com. An Issuer serves discovery and JWKS
+// over TLS and mints RS256 tokens with whatever claims a test asks for;
+// Verify checks a token the way a relying party (e.g. a workload identity
+// pool) does before looking at its claims.
+//
+// Only what the wif tests need is implemented: one RSA key, RS256, and the
+// iss, exp and nbf checks. Audience and claim checks belong to the caller.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Well-known paths, as GitHub serves them.
+const (
+	DiscoveryPath = "/.well-known/openid-configuration"
+	JWKSPath      = "/.well-known/jwks"
+)
+
+// TokenLifetime is the lifetime Mint gives tokens without an exp claim.
+const TokenLifetime = 5 * time.Minute
+
+// Discovery is the subset of the OpenID provider metadata this package uses.
+type Discovery struct {
+	Issuer           string   `json:"issuer"`
+	JWKSURI          string   `json:"jwks_uri"`
+	SigningAlgValues []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// JWK is an RSA public key in a JWKS document.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// Issuer is a running test issuer. URL has no trailing slash and is what a
+// provider's issuer_uri must be set to. Client trusts the issuer's
+// self-signed certificate.
+type Issuer struct {
+	URL    string
+	Client *http.Client
+
+	key   *rsa.PrivateKey
+	keyID string
+}
+
+// NewIssuer starts an issuer that stops when the test ends.
+func NewIssuer(t testing.TB) *Issuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate issuer key: %v", err)
+	}
+	iss := &Issuer{key: key, keyID: "test-key-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+DiscoveryPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, Discovery{Issuer: iss.URL, JWKSURI: iss.URL + JWKSPath, SigningAlgValues: []string{"RS256"}})
+	})
+	mux.HandleFunc("GET "+JWKSPath, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string][]JWK{"keys": {iss.JWK()}})
+	})
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+
+	iss.URL = srv.URL
+	iss.Client = srv.Client()
+	return iss
+}
+
+// JWK returns the public half of the signing key.
+func (i *Issuer) JWK() JWK {
+	return JWK{
+		KeyType:   "RSA",
+		Algorithm: "RS256",
+		Use:       "sig",
+		KeyID:     i.keyID,
+		N:         b64(i.key.N.Bytes()),
+		E:         b64(big.NewInt(int64(i.key.E)).Bytes()),
+	}
+}
+
+// Mint signs a token carrying claims. iss defaults to the issuer's URL, iat
+// and nbf to now and exp to now plus TokenLifetime; set them in claims to
+// mint wrong-issuer or expired tokens.
+func (i *Issuer) Mint(claims map[string]any) (string, error) {
+	now := time.Now()
+	c := map[string]any{
+		"iss": i.URL,
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(TokenLifetime).Unix(),
+	}
+	maps.Copy(c, claims)
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": i.keyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + b64(sig), nil
+}
+
+// Verify checks token against the issuer at issuerURL: it fetches discovery
+// and JWKS with client, checks the RS256 signature, and checks iss, exp and
+// nbf against now. It returns the token's claims.
+func Verify(ctx context.Context, client *http.Client, issuerURL, token string, now time.Time) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is not a JWS compact serialization")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("token header: %w", err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", header.Alg)
+	}
+
+	var disc Discovery
+	if err := getJSON(ctx, client, strings.TrimSuffix(issuerURL, "/")+DiscoveryPath, &disc); err != nil {
+		return nil, err
+	}
+	if disc.Issuer != issuerURL {
+		return nil, fmt.Errorf("discovery issuer %q does not match %q", disc.Issuer, issuerURL)
+	}
+	var jwks struct {
+		Keys []JWK `json:"keys"`
+	}
+	if err := getJSON(ctx, client, disc.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+	for _, k := range jwks.Keys {
+		if k.KeyID != header.Kid || k.KeyType != "RSA" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %q: %w", k.KeyID, err)
+		}
+		return verifyWith(pub, parts, issuerURL, now)
+	}
+	return nil, fmt.Errorf("no RSA key %q in the issuer's JWKS", header.Kid)
+}
+
+func verifyWith(pub *rsa.PublicKey, parts []string, issuerURL string, now time.Time) (map[string]any, error) {
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("token signature: %w", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+		return nil, errors.New("token signature does not verify")
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("token payload: %w", err)
+	}
+	if claims["iss"] != issuerURL {
+		return nil, fmt.Errorf("token issuer %v is not %s", claims["iss"], issuerURL)
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errors.New("token has no exp claim")
+	}
+	if now.Unix() >= int64(exp) {
+		return nil, fmt.Errorf("token expired at %s", time.Unix(int64(exp), 0).UTC())
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Unix() < int64(nbf) {
+		return nil, fmt.Errorf("token not valid before %s", time.Unix(int64(nbf), 0).UTC())
+	}
+	return claims, nil
+}
+
+func (k JWK) publicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GET %s: %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("GET %s: %w", url, err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func decodeSegment(s string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,69 @@
+package oidc
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssuer_MintVerify(t *testing.T) {
+	iss := NewIssuer(t)
+	token, err := iss.Mint(map[string]any{"sub": "repo:acme/app:ref:refs/heads/main", "aud": "neo4j-gke", "actor": "octocat"})
+	require.NoError(t, err)
+
+	claims, err := Verify(context.Background(), iss.Client, iss.URL, token, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, iss.URL, claims["iss"])
+	require.Equal(t, "repo:acme/app:ref:refs/heads/main", claims["sub"])
+	require.Equal(t, "neo4j-gke", claims["aud"])
+	require.Equal(t, "octocat", claims["actor"])
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	iss := NewIssuer(t)
+	other := NewIssuer(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	mint := func(t *testing.T, i *Issuer, claims map[string]any) string {
+		t.Helper()
+		token, err := i.Mint(claims)
+		require.NoError(t, err)
+		return token
+	}
+	tamper := func(token string) string {
+		parts := strings.Split(token, ".")
+		parts[1] = b64([]byte(`{"iss":"` + iss.URL + `","exp":9999999999,"sub":"admin"}`))
+		return strings.Join(parts, ".")
+	}
+
+	tests := []struct {
+		name, token, want string
+	}{
+		{"other issuer's key", mint(t, other, map[string]any{"iss": iss.URL}), "does not verify"},
+		{"tampered payload", tamper(mint(t, iss, nil)), "does not verify"},
+		{"wrong issuer claim", mint(t, iss, map[string]any{"iss": "https://token.actions.githubusercontent.com"}), "is not " + iss.URL},
+		{"expired", mint(t, iss, map[string]any{"exp": now.Add(-time.Minute).Unix()}), "expired"},
+		{"not yet valid", mint(t, iss, map[string]any{"nbf": now.Add(time.Hour).Unix()}), "not valid before"},
+		{"not a JWT", "abc", "compact serialization"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Verify(ctx, iss.Client, iss.URL, tc.token, now)
+			require.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestVerify_DiscoveryMismatch(t *testing.T) {
+	iss := NewIssuer(t)
+	token, err := iss.Mint(nil)
+	require.NoError(t, err)
+
+	// A trailing slash is a different issuer string, as in the OIDC spec.
+	_, err = Verify(context.Background(), iss.Client, iss.URL+"/", token, time.Now())
+	require.ErrorContains(t, err, "does not match")
+}
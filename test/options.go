@@ -0,0 +1,119 @@
+package test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+//go:generate go run ../cmd/optgen -modules ../infra/modules -extra neo4j_app/tests/e2e=NeoAppE2E -extra neo4j_app/tests/local=NeoAppLocal -out module_options_gen.go
+
+// Typed module options (module_options_gen.go) are generated from each
+// module's variables.tf. Build Vars with them instead of map[string]any so a
+// misspelled or retyped variable fails to compile:
+//
+//	gke := &GKEOptions{ProjectID: projectID, Region: region, ClusterName: name,
+//		DeletionProtection: Ptr(false)}
+//	tf := gke.TerraformOptions(t, CopyModuleToTemp(t, gke.Module()))
+//
+// Regenerate after changing a module's variables with `go generate ./test/`.
+
+// Ptr returns a pointer to v, for optional option fields.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// moduleTerraformOptions is the shared body of the generated TerraformOptions
+// methods.
+func moduleTerraformOptions(t *testing.T, terraformDir string, vars map[string]any) *terraform.Options {
+	t.Helper()
+	return NewTerraformOptions(t, &terraform.Options{
+		TerraformDir:    terraformDir,
+		TerraformBinary: "tofu",
+		Vars:            vars,
+		NoColor:         true,
+	})
+}
+
+// moduleVars converts a generated options struct into Terraform Vars, keyed by
+// the `tf` struct tags. Nil pointers, slices and maps are omitted so the module
+// default applies; a zero required field fails the test.
+func moduleVars(t *testing.T, options any) map[string]any {
+	t.Helper()
+	v := reflect.ValueOf(options)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	vars, missing := structVars(v, v.Type().Name())
+	require.Emptyf(t, missing, "required module variables not set: %s", strings.Join(missing, ", "))
+	return vars
+}
+
+func structVars(v reflect.Value, path string) (map[string]any, []string) {
+	out := map[string]any{}
+	var missing []string
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tag := field.Tag.Get("tf")
+		if tag == "" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+		fieldPath := path + "." + field.Name
+
+		if opts == "required" && fv.IsZero() && fv.Kind() != reflect.Bool {
+			missing = append(missing, fieldPath)
+			continue
+		}
+		if isNil(fv) {
+			continue
+		}
+		value, m := tfValue(fv, fieldPath)
+		missing = append(missing, m...)
+		out[name] = value
+	}
+	return out, missing
+}
+
+// tfValue converts a field value into the plain Go value terratest serializes.
+func tfValue(v reflect.Value, path string) (any, []string) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		return tfValue(v.Elem(), path)
+	case reflect.Struct:
+		return structVars(v, path)
+	case reflect.Slice:
+		out := make([]any, v.Len())
+		var missing []string
+		for i := range v.Len() {
+			item, m := tfValue(v.Index(i), path)
+			missing = append(missing, m...)
+			out[i] = item
+		}
+		return out, missing
+	case reflect.Map:
+		out := make(map[string]any, v.Len())
+		var missing []string
+		iter := v.MapRange()
+		for iter.Next() {
+			item, m := tfValue(iter.Value(), path+"["+iter.Key().String()+"]")
+			missing = append(missing, m...)
+			out[iter.Key().String()] = item
+		}
+		return out, missing
+	default:
+		return v.Interface(), nil
+	}
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleVars_OmitsNilAndConvertsNested(t *testing.T) {
+	gke := &GKEOptions{
+		ProjectID:          "p",
+		Region:             "us-central1",
+		NetworkID:          "net",
+		SubnetID:           "sub",
+		PodsRangeName:      "pods",
+		ServicesRangeName:  "services",
+		DeletionProtection: Ptr(false),
+		MasterAuthorizedNetworks: []GKEMasterAuthorizedNetwork{
+			{CIDRBlock: "10.0.0.0/8", DisplayName: "internal"},
+		},
+	}
+
+	vars := gke.Vars(t)
+	require.Equal(t, false, vars["deletion_protection"])
+	require.NotContains(t, vars, "cluster_name", "nil optional fields use the module default")
+	require.NotContains(t, vars, "labels")
+	require.Equal(t, []any{map[string]any{"cidr_block": "10.0.0.0/8", "display_name": "internal"}},
+		vars["master_authorized_networks"])
+
+	sa := &ServiceAccountsOptions{
+		ProjectID: "p",
+		ServiceAccounts: map[string]ServiceAccountsServiceAccount{
+			"ci": {Description: "CI", Disabled: Ptr(true)},
+		},
+	}
+	require.Equal(t, map[string]any{"ci": map[string]any{"description": "CI", "disabled": true}},
+		sa.Vars(t)["service_accounts"])
+}
+
+func TestModuleVars_ReportsMissingRequired(t *testing.T) {
+	secrets := SecretsOptions{
+		Secrets: map[string]SecretsSecret{"s": {}},
+	}
+	_, missing := structVars(reflect.ValueOf(secrets), "SecretsOptions")
+	require.ElementsMatch(t, []string{"SecretsOptions.ProjectID", "SecretsOptions.Secrets[s].Description"}, missing)
+}
+
+func TestModuleOptions_TerraformOptions(t *testing.T) {
+	vpc := &VPCOptions{ProjectID: "p", Region: "us-central1", EnableCloudNAT: Ptr(true)}
+	tf := vpc.TerraformOptions(t, t.TempDir())
+
+	require.Equal(t, "vpc", vpc.Module())
+	require.Equal(t, "tofu", tf.TerraformBinary)
+	require.Equal(t, true, tf.Vars["enable_cloud_nat"])
+}
@@ -0,0 +1,312 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/simon-lentz/neo4j_gke/test/ledger"
+)
+
+// Failed destroys are retried with backoff while the error looks transient,
+// then written to the orphan ledger (see package ledger) with a copy of the
+// state and variables so `go run ./cmd/orphans replay` can finish the job
+// after the test's temp dir is gone. Sensitive variables and credentials in
+// the environment are left out; replay needs them supplied again.
+
+// DestroyBackoff controls how RunTerraformCleanup retries a failed destroy.
+type DestroyBackoff struct {
+	Attempts int
+	Initial  time.Duration
+	Max      time.Duration
+}
+
+var (
+	// destroyBackoff is the retry policy for cleanup destroys. Retries stop
+	// early when the next wait would run past the test deadline.
+	destroyBackoff = DestroyBackoff{Attempts: 4, Initial: 30 * time.Second, Max: 5 * time.Minute}
+
+	// destroyE is terraform.DestroyE; replaced in tests.
+	destroyE = terraform.DestroyE
+
+	// transientDestroyErrors match GCP errors that usually clear on their own:
+	// dependants still being torn down, concurrent operations and API blips.
+	transientDestroyErrors = []*regexp.Regexp{
+		regexp.MustCompile(`resourceInUseByAnotherResource`),
+		regexp.MustCompile(`is already being used by`),
+		regexp.MustCompile(`(?i)operation .*(is )?(already )?in progress`),
+		regexp.MustCompile(`(?i)concurrent policy changes`),
+		regexp.MustCompile(`Error 409: .*(aborted|conflict)`),
+		regexp.MustCompile(`Error (429|500|502|503|504)\b`),
+		regexp.MustCompile(`rateLimitExceeded|backendError|internalError`),
+		regexp.MustCompile(`connection reset by peer|TLS handshake timeout|i/o timeout`),
+	}
+
+	// credentialEnvVar matches environment variables RecordOrphan does not
+	// write to the ledger.
+	credentialEnvVar = regexp.MustCompile(`(?i)token|secret|password`)
+)
+
+// IsTransientDestroyError reports whether err is worth retrying.
+func IsTransientDestroyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	for _, re := range transientDestroyErrors {
+		if re.MatchString(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// destroyWithRetry runs destroy, retrying transient failures per destroyBackoff.
+func destroyWithRetry(t *testing.T, options *terraform.Options) error {
+	t.Helper()
+
+	wait := destroyBackoff.Initial
+	for attempt := 1; ; attempt++ {
+		_, err := destroyE(t, options)
+		if err == nil {
+			return nil
+		}
+		if attempt >= destroyBackoff.Attempts || !IsTransientDestroyError(err) {
+			return err
+		}
+		if deadline, ok := t.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			t.Logf("Not retrying destroy: next attempt would run past the test deadline")
+			return err
+		}
+		t.Logf("Destroy attempt %d/%d hit a transient error; retrying in %s: %v",
+			attempt, destroyBackoff.Attempts, wait, err)
+		time.Sleep(wait)
+		wait = min(2*wait, destroyBackoff.Max)
+	}
+}
+
+// RecordOrphan copies the state and variables of a workspace whose destroy
+// failed into the orphan ledger and appends a ledger record. Variables the
+// root declares sensitive and credential environment variables are recorded
+// by name only. A state without managed resources leaves nothing to replay,
+// so nothing is recorded and the zero Record is returned.
+func RecordOrphan(t *testing.T, options *terraform.Options, destroyErr error) (ledger.Record, error) {
+	t.Helper()
+
+	// Read the state before touching the ledger, so a state that can't be
+	// read or holds nothing leaves no entry behind.
+	state, err := readState(t, options)
+	if err != nil {
+		return ledger.Record{}, fmt.Errorf("read state of %s: %w", options.TerraformDir, err)
+	}
+	addresses, err := ledger.Addresses(state)
+	if err != nil {
+		return ledger.Record{}, fmt.Errorf("read state of %s: %w", options.TerraformDir, err)
+	}
+	if len(addresses) == 0 {
+		return ledger.Record{}, nil
+	}
+
+	dir, err := ledger.DefaultDir()
+	if err != nil {
+		return ledger.Record{}, err
+	}
+	l := ledger.Ledger{Dir: dir}
+
+	module := moduleFromTerraformDir(options.TerraformDir)
+	id := fmt.Sprintf("%s-%s-%s", TestRunID(),
+		invalidLeaseKeyChars.ReplaceAllString(strings.ReplaceAll(t.Name(), "/", "-"), "_"),
+		strings.ToLower(filepath.Base(module)))
+	id = uniqueEntryID(dir, id)
+
+	entry, err := l.NewEntry(id)
+	if err != nil {
+		return ledger.Record{}, err
+	}
+	if err := os.WriteFile(filepath.Join(entry, ledger.StateFile), state, 0o600); err != nil {
+		return ledger.Record{}, err
+	}
+
+	sensitive, err := sensitiveVariables(options.TerraformDir)
+	if err != nil {
+		return ledger.Record{}, err
+	}
+	var sensitiveVars []string
+	saved := map[string]any{}
+	for k, v := range options.Vars {
+		if sensitive[k] {
+			sensitiveVars = append(sensitiveVars, k)
+			continue
+		}
+		saved[k] = v
+	}
+	sort.Strings(sensitiveVars)
+
+	vars, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return ledger.Record{}, err
+	}
+	if err := os.WriteFile(filepath.Join(entry, ledger.VarFile), vars, 0o600); err != nil {
+		return ledger.Record{}, err
+	}
+	for i, vf := range options.VarFiles {
+		if !filepath.IsAbs(vf) {
+			vf = filepath.Join(options.TerraformDir, vf)
+		}
+		data, err := os.ReadFile(vf)
+		if err != nil {
+			return ledger.Record{}, err
+		}
+		name := fmt.Sprintf("%02d-%s", i, filepath.Base(vf))
+		if err := os.WriteFile(filepath.Join(entry, name), data, 0o600); err != nil {
+			return ledger.Record{}, err
+		}
+	}
+
+	var envNames, redacted []string
+	if len(options.EnvVars) > 0 {
+		savedEnv := map[string]string{}
+		for k, v := range options.EnvVars {
+			envNames = append(envNames, k)
+			name, isVar := strings.CutPrefix(k, "TF_VAR_")
+			if credentialEnvVar.MatchString(k) || (isVar && sensitive[name]) {
+				redacted = append(redacted, k)
+				continue
+			}
+			savedEnv[k] = v
+		}
+		sort.Strings(envNames)
+		sort.Strings(redacted)
+		env, err := json.MarshalIndent(savedEnv, "", "  ")
+		if err != nil {
+			return ledger.Record{}, err
+		}
+		if err := os.WriteFile(filepath.Join(entry, "env.json"), env, 0o600); err != nil {
+			return ledger.Record{}, err
+		}
+	}
+
+	rec := ledger.Record{
+		ID:              id,
+		RunID:           TestRunID(),
+		Test:            t.Name(),
+		Module:          module,
+		Recorded:        time.Now().UTC(),
+		Dir:             id,
+		Addresses:       addresses,
+		EnvVarNames:     envNames,
+		SensitiveVars:   sensitiveVars,
+		RedactedEnvVars: redacted,
+	}
+	if destroyErr != nil {
+		rec.Error = lastLines(destroyErr.Error(), 20)
+	}
+	return rec, l.Append(rec)
+}
+
+// sensitiveVariables returns the variables declared with sensitive = true in
+// the .tf files of dir.
+func sensitiveVariables(dir string) (map[string]bool, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	sensitive := map[string]bool{}
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		file, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+			if block.Type != "variable" || len(block.Labels) != 1 {
+				continue
+			}
+			attr, ok := block.Body.Attributes["sensitive"]
+			if !ok {
+				continue
+			}
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			if val.Type() == cty.Bool && val.IsKnown() && !val.IsNull() && val.True() {
+				sensitive[block.Labels[0]] = true
+			}
+		}
+	}
+	return sensitive, nil
+}
+
+// readState returns the workspace state: the local state file when present,
+// otherwise `tofu state pull` for remote backends.
+func readState(t *testing.T, options *terraform.Options) ([]byte, error) {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(options.TerraformDir, ledger.StateFile))
+	if err == nil {
+		return data, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	binary := options.TerraformBinary
+	if binary == "" {
+		binary = "tofu"
+	}
+	out, err := shell.RunCommandAndGetStdOutE(t, shell.Command{
+		Command:    binary,
+		Args:       []string{"state", "pull"},
+		WorkingDir: options.TerraformDir,
+		Env:        options.EnvVars,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// moduleFromTerraformDir recovers the repo-relative module path (infra/...)
+// from a copied TerraformDir.
+func moduleFromTerraformDir(dir string) string {
+	slashed := filepath.ToSlash(dir)
+	if i := strings.LastIndex(slashed, "/infra/"); i >= 0 {
+		return slashed[i+1:]
+	}
+	return slashed
+}
+
+// uniqueEntryID appends -2, -3, ... when id already has an entry directory.
+func uniqueEntryID(dir, id string) string {
+	candidate := id
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(dir, candidate)); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", id, n)
+	}
+}
+
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
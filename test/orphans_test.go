@@ -0,0 +1,113 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	terratesting "github.com/gruntwork-io/terratest/modules/testing"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simon-lentz/neo4j_gke/test/ledger"
+)
+
+// stubDestroy replaces destroyE with one returning errs in turn (nil after).
+func stubDestroy(t *testing.T, errs ...error) *int {
+	calls := new(int)
+	origDestroy, origBackoff := destroyE, destroyBackoff
+	destroyE = func(_ terratesting.TestingT, _ *terraform.Options) (string, error) {
+		*calls++
+		if *calls <= len(errs) {
+			return "", errs[*calls-1]
+		}
+		return "", nil
+	}
+	destroyBackoff = DestroyBackoff{Attempts: 3, Initial: time.Millisecond, Max: time.Millisecond}
+	t.Cleanup(func() { destroyE, destroyBackoff = origDestroy, origBackoff })
+	return calls
+}
+
+func TestIsTransientDestroyError(t *testing.T) {
+	require.True(t, IsTransientDestroyError(errors.New(
+		"googleapi: Error 400: The subnetwork resource 'x' is already being used by 'y', resourceInUseByAnotherResource")))
+	require.True(t, IsTransientDestroyError(errors.New("googleapi: Error 503: backendError")))
+	require.False(t, IsTransientDestroyError(errors.New("googleapi: Error 403: Permission denied")))
+	require.False(t, IsTransientDestroyError(nil))
+}
+
+func TestRunTerraformCleanup_RetriesTransientErrors(t *testing.T) {
+	t.Setenv("NEO4J_GKE_ORPHAN_LEDGER_DIR", t.TempDir())
+	calls := stubDestroy(t, errors.New("googleapi: Error 503: backendError"))
+
+	require.NoError(t, RunTerraformCleanup(t, &terraform.Options{TerraformDir: t.TempDir()}))
+	require.Equal(t, 2, *calls)
+}
+
+func TestRunTerraformCleanup_RecordsOrphan(t *testing.T) {
+	ledgerDir := t.TempDir()
+	t.Setenv("NEO4J_GKE_ORPHAN_LEDGER_DIR", ledgerDir)
+	calls := stubDestroy(t, errors.New("googleapi: Error 403: Permission denied"))
+
+	tfDir := filepath.Join(t.TempDir(), "infra", "modules", "vpc")
+	require.NoError(t, os.MkdirAll(tfDir, 0o755))
+	state := `{"version": 4, "resources": [{"mode": "managed", "type": "google_compute_network", "name": "vpc", "instances": [{}]}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(tfDir, "terraform.tfstate"), []byte(state), 0o644))
+
+	variables := `variable "vpc_name" {}
+variable "admin_password" {
+  sensitive = true
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tfDir, "variables.tf"), []byte(variables), 0o644))
+
+	err := RunTerraformCleanup(t, &terraform.Options{
+		TerraformDir: tfDir,
+		Vars:         map[string]any{"project_id": "p", "vpc_name": "test-vpc-abc123", "admin_password": "hunter2"},
+		EnvVars: map[string]string{
+			"GOOGLE_PROJECT":            "p",
+			"GOOGLE_OAUTH_ACCESS_TOKEN": "ya29.token",
+			"TF_VAR_admin_password":     "hunter2",
+		},
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, *calls, "non-transient errors are not retried")
+
+	records, err := ledger.Ledger{Dir: ledgerDir}.Outstanding()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	rec := records[0]
+	require.Equal(t, t.Name(), rec.Test)
+	require.Equal(t, "infra/modules/vpc", rec.Module)
+	require.Equal(t, []string{"google_compute_network.vpc"}, rec.Addresses)
+	require.Contains(t, rec.Error, "Permission denied")
+
+	entry := ledger.Ledger{Dir: ledgerDir}.EntryDir(rec)
+	savedState, err := os.ReadFile(filepath.Join(entry, ledger.StateFile))
+	require.NoError(t, err)
+	require.JSONEq(t, state, string(savedState))
+
+	var vars map[string]any
+	data, err := os.ReadFile(filepath.Join(entry, ledger.VarFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &vars))
+	require.Equal(t, "test-vpc-abc123", vars["vpc_name"])
+
+	// Sensitive values are recorded by name only.
+	require.Equal(t, []string{"admin_password"}, rec.SensitiveVars)
+	require.Equal(t, []string{"GOOGLE_OAUTH_ACCESS_TOKEN", "TF_VAR_admin_password"}, rec.RedactedEnvVars)
+	require.Equal(t, []string{"GOOGLE_OAUTH_ACCESS_TOKEN", "GOOGLE_PROJECT", "TF_VAR_admin_password"}, rec.EnvVarNames)
+	require.NotContains(t, vars, "admin_password")
+	env, err := os.ReadFile(filepath.Join(entry, "env.json"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"GOOGLE_PROJECT": "p"}`, string(env))
+	for _, name := range []string{ledger.VarFile, "env.json", filepath.Join("..", ledger.FileName)} {
+		data, err := os.ReadFile(filepath.Join(entry, name))
+		require.NoError(t, err)
+		require.NotContains(t, string(data), "hunter2", "%s holds a sensitive value", name)
+		require.NotContains(t, string(data), "ya29", "%s holds a credential", name)
+	}
+}
@@ -0,0 +1,209 @@
+package test
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+)
+
+// --- plan JSON helpers ---
+
+// PlanJSON runs init and plan against a saved plan file, then decodes
+// `tofu show -json` into terratest's typed PlanStruct. The caller's options are
+// not mutated; the plan file lives in a per-test temp directory.
+//
+// Usage:
+//
+//	plan := PlanJSON(t, tf)
+//	RequireResourceCreated(t, plan, "google_container_cluster.autopilot")
+//	RequirePlannedAttribute(t, plan, "google_container_cluster.autopilot", "enable_autopilot", true)
+func PlanJSON(t *testing.T, options *terraform.Options) *terraform.PlanStruct {
+	t.Helper()
+	plan, err := PlanJSONE(t, options)
+	require.NoError(t, err, "tofu plan failed")
+	return plan
+}
+
+// PlanJSONE is like PlanJSON but returns the plan error instead of failing the
+// test. Use it for precondition/validation tests that expect plan to fail.
+func PlanJSONE(t *testing.T, options *terraform.Options) (*terraform.PlanStruct, error) {
+	t.Helper()
+
+	opts := *options
+	opts.PlanFilePath = filepath.Join(t.TempDir(), "tfplan")
+
+	return terraform.InitAndPlanAndShowWithStructE(t, &opts)
+}
+
+// ParsePlanJSON decodes raw `tofu show -json` output. It exists so offline tests
+// can feed recorded plans through the same assertions.
+func ParsePlanJSON(t *testing.T, raw string) *terraform.PlanStruct {
+	t.Helper()
+	plan, err := terraform.ParsePlanJSON(raw)
+	require.NoError(t, err, "failed to decode plan JSON")
+	return plan
+}
+
+// RequireResourceChange returns the planned change for addr, failing the test if
+// the plan does not mention the resource at all.
+func RequireResourceChange(t *testing.T, plan *terraform.PlanStruct, addr string) *tfjson.ResourceChange {
+	t.Helper()
+	rc, ok := plan.ResourceChangesMap[addr]
+	require.Truef(t, ok, "plan has no resource change for %s (have: %s)", addr, strings.Join(plannedAddresses(plan), ", "))
+	require.NotNilf(t, rc.Change, "resource change for %s has no change body", addr)
+	return rc
+}
+
+// RequireResourceActions asserts the exact action set planned for addr
+// (e.g. tfjson.Actions{tfjson.ActionCreate}).
+func RequireResourceActions(t *testing.T, plan *terraform.PlanStruct, addr string, want tfjson.Actions) {
+	t.Helper()
+	rc := RequireResourceChange(t, plan, addr)
+	require.Equalf(t, want, rc.Change.Actions, "unexpected planned actions for %s", addr)
+}
+
+// RequireResourceCreated asserts addr will be created (and only created).
+func RequireResourceCreated(t *testing.T, plan *terraform.PlanStruct, addr string) {
+	t.Helper()
+	rc := RequireResourceChange(t, plan, addr)
+	require.Truef(t, rc.Change.Actions.Create(), "expected %s to be created, got actions %v", addr, rc.Change.Actions)
+}
+
+// RequireResourceAbsent asserts the plan does not touch addr at all, which is how
+// count = 0 resources appear.
+func RequireResourceAbsent(t *testing.T, plan *terraform.PlanStruct, addr string) {
+	t.Helper()
+	_, ok := plan.ResourceChangesMap[addr]
+	require.Falsef(t, ok, "expected no resource change for %s", addr)
+}
+
+// RequireNoResourceDestroyed asserts no resource is deleted or replaced by the plan.
+func RequireNoResourceDestroyed(t *testing.T, plan *terraform.PlanStruct) {
+	t.Helper()
+	destroyed := destroyedAddresses(plan)
+	require.Emptyf(t, destroyed, "plan destroys resources: %s", strings.Join(destroyed, ", "))
+}
+
+// RequirePlannedAttribute asserts the planned ("after") value of an attribute.
+// path uses dot notation with numeric list indices, matching the JSON plan
+// shape of nested blocks (e.g. "private_cluster_config.0.enable_private_nodes").
+// want is compared after a JSON round-trip, so Go ints, string slices and maps
+// compare equal to their decoded plan counterparts.
+func RequirePlannedAttribute(t *testing.T, plan *terraform.PlanStruct, addr, path string, want any) {
+	t.Helper()
+	got := RequirePlannedAttributeValue(t, plan, addr, path)
+	require.Equalf(t, normalizeJSONValue(t, want), got, "%s: unexpected planned value for %q", addr, path)
+}
+
+// RequirePlannedAttributeValue returns the planned value of an attribute,
+// failing if the attribute is missing or only known after apply.
+func RequirePlannedAttributeValue(t *testing.T, plan *terraform.PlanStruct, addr, path string) any {
+	t.Helper()
+	rc := RequireResourceChange(t, plan, addr)
+
+	if unknown, ok := lookupPath(rc.Change.AfterUnknown, path); ok && unknown == true {
+		require.FailNowf(t, "attribute unknown", "%s: %q is only known after apply", addr, path)
+	}
+
+	got, ok := lookupPath(rc.Change.After, path)
+	require.Truef(t, ok, "%s: attribute %q not present in planned values", addr, path)
+	return got
+}
+
+// RequireConfigReference asserts that argument key of addr references ref in
+// the configuration, for wiring whose value is only known after apply (e.g. a
+// service account email). addr is a root module resource
+// ("google_kms_crypto_key_iam_member.backup_sa_kms") or module call
+// ("module.neo4j"); ref is as tofu reports it, e.g.
+// `module.secrets.secret_ids["neo4j-admin-password-dev"]`.
+func RequireConfigReference(t *testing.T, plan *terraform.PlanStruct, addr, key, ref string) {
+	t.Helper()
+	require.Truef(t, plan.RawPlan.Config != nil && plan.RawPlan.Config.RootModule != nil, "plan has no configuration")
+	root := plan.RawPlan.Config.RootModule
+
+	var exprs map[string]*tfjson.Expression
+	if name, ok := strings.CutPrefix(addr, "module."); ok {
+		call, ok := root.ModuleCalls[name]
+		require.Truef(t, ok, "configuration has no module call %s", addr)
+		exprs = call.Expressions
+	} else {
+		for _, r := range root.Resources {
+			if r.Address == addr {
+				exprs = r.Expressions
+			}
+		}
+		require.NotNilf(t, exprs, "configuration has no resource %s", addr)
+	}
+
+	expr, ok := exprs[key]
+	require.Truef(t, ok && expr.ExpressionData != nil, "%s: no argument %q in configuration", addr, key)
+	require.Containsf(t, expr.References, ref, "%s: %q does not reference %s", addr, key, ref)
+}
+
+// plannedAddresses lists the addresses in plan order for error messages.
+func plannedAddresses(plan *terraform.PlanStruct) []string {
+	addrs := make([]string, 0, len(plan.RawPlan.ResourceChanges))
+	for _, rc := range plan.RawPlan.ResourceChanges {
+		addrs = append(addrs, rc.Address)
+	}
+	return addrs
+}
+
+// destroyedAddresses lists resources the plan deletes or replaces, in plan order.
+func destroyedAddresses(plan *terraform.PlanStruct) []string {
+	var destroyed []string
+	for _, rc := range plan.RawPlan.ResourceChanges {
+		if rc.Change == nil {
+			continue
+		}
+		if rc.Change.Actions.Delete() || rc.Change.Actions.Replace() {
+			destroyed = append(destroyed, rc.Address)
+		}
+	}
+	return destroyed
+}
+
+// lookupPath walks a decoded JSON value using dot notation. Numeric segments
+// index into lists; all other segments index into objects.
+func lookupPath(v any, path string) (any, bool) {
+	if path == "" {
+		return v, true
+	}
+	cur := v
+	for _, seg := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			next, ok := node[seg]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			cur = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// normalizeJSONValue converts a Go value into the shape encoding/json produces
+// when decoding into any (float64 numbers, []any, map[string]any).
+func normalizeJSONValue(t *testing.T, v any) any {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoErrorf(t, err, "cannot marshal expected value %#v", v)
+	var out any
+	require.NoError(t, json.Unmarshal(data, &out))
+	return out
+}
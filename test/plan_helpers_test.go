@@ -0,0 +1,57 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPlanHelpers_RecordedPlan exercises the plan assertions against a recorded
+// `tofu show -json` document so the helpers can be verified without GCP.
+func TestPlanHelpers_RecordedPlan(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "plan", "gke.json"))
+	require.NoError(t, err)
+
+	plan := ParsePlanJSON(t, string(raw))
+
+	const cluster = "google_container_cluster.autopilot"
+	RequireResourceCreated(t, plan, cluster)
+	RequireResourceActions(t, plan, cluster, tfjson.Actions{tfjson.ActionCreate})
+	RequireResourceAbsent(t, plan, "google_project_service.container[0]")
+
+	RequirePlannedAttribute(t, plan, cluster, "name", "plan-test-cluster")
+	RequirePlannedAttribute(t, plan, cluster, "enable_autopilot", true)
+	RequirePlannedAttribute(t, plan, cluster, "private_cluster_config.0.enable_private_endpoint", false)
+	RequirePlannedAttribute(t, plan, cluster, "release_channel.0.channel", "REGULAR")
+	RequirePlannedAttribute(t, plan, cluster, "resource_labels", map[string]string{"test": "true"})
+
+	// The recorded plan replaces one network; it must be reported as destroyed.
+	require.Equal(t, []string{"google_compute_network.old"}, destroyedAddresses(plan))
+}
+
+func TestPlanHelpers_LookupPath(t *testing.T) {
+	doc := map[string]any{
+		"a": []any{map[string]any{"b": "c"}},
+		"n": float64(3),
+	}
+
+	v, ok := lookupPath(doc, "a.0.b")
+	require.True(t, ok)
+	require.Equal(t, "c", v)
+
+	v, ok = lookupPath(doc, "n")
+	require.True(t, ok)
+	require.Equal(t, float64(3), v)
+
+	_, ok = lookupPath(doc, "a.1.b")
+	require.False(t, ok, "out of range index should not resolve")
+
+	_, ok = lookupPath(doc, "a.x")
+	require.False(t, ok, "non-numeric list index should not resolve")
+
+	_, ok = lookupPath(doc, "missing")
+	require.False(t, ok)
+}
@@ -0,0 +1,182 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// --- test regions ---
+
+// TestRegion is a region the module tests run in. The CIDRs override the vpc
+// module's subnet_ip_range, pods_ip_range and services_ip_range defaults;
+// empty ones keep the default.
+type TestRegion struct {
+	Name         string `json:"-"`
+	SubnetCIDR   string `json:"subnet_ip_range"`
+	PodsCIDR     string `json:"pods_ip_range"`
+	ServicesCIDR string `json:"services_ip_range"`
+}
+
+var validRegion = regexp.MustCompile(`^[a-z]+-[a-z]+[0-9]+$`)
+
+// GetTestRegions returns the regions region-dependent module tests run in, from
+// NEO4J_GKE_TEST_REGIONS (comma-separated, e.g. "us-central1,europe-west4").
+// Without it there is one region, GetTestRegion's.
+//
+// NEO4J_GKE_TEST_REGION_OVERRIDES names a JSON file of per-region CIDRs,
+// keyed by region with the vpc module's variable names:
+//
+//	{"europe-west4": {"subnet_ip_range": "10.20.0.0/24", "pods_ip_range": "10.21.0.0/16",
+//	                  "services_ip_range": "10.22.0.0/20"}}
+//
+// An override for a region that is not tested fails, as it is likely a typo.
+func GetTestRegions(t *testing.T) []TestRegion {
+	t.Helper()
+
+	names := []string{GetTestRegion(t)}
+	if list := strings.TrimSpace(os.Getenv("NEO4J_GKE_TEST_REGIONS")); list != "" {
+		names = nil
+		for _, n := range strings.Split(list, ",") {
+			if n = strings.TrimSpace(n); n != "" {
+				names = append(names, n)
+			}
+		}
+		require.NotEmpty(t, names, "NEO4J_GKE_TEST_REGIONS lists no regions")
+	}
+
+	var overrides map[string]TestRegion
+	if path := os.Getenv("NEO4J_GKE_TEST_REGION_OVERRIDES"); path != "" {
+		data, err := os.ReadFile(path)
+		require.NoErrorf(t, err, "read NEO4J_GKE_TEST_REGION_OVERRIDES")
+		require.NoErrorf(t, json.Unmarshal(data, &overrides), "decode region overrides %s", path)
+	}
+	regions, err := parseTestRegions(names, overrides)
+	require.NoError(t, err, "bad test region configuration")
+	return regions
+}
+
+// parseTestRegions validates names and attaches their overrides.
+func parseTestRegions(names []string, overrides map[string]TestRegion) ([]TestRegion, error) {
+	seen := map[string]bool{}
+	regions := make([]TestRegion, 0, len(names))
+	for _, n := range names {
+		if !validRegion.MatchString(n) {
+			return nil, fmt.Errorf("%q is not a GCP region name (e.g. us-central1)", n)
+		}
+		if seen[n] {
+			return nil, fmt.Errorf("region %s is listed twice", n)
+		}
+		seen[n] = true
+
+		r := overrides[n]
+		r.Name = n
+		for _, cidr := range []string{r.SubnetCIDR, r.PodsCIDR, r.ServicesCIDR} {
+			if cidr == "" {
+				continue
+			}
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return nil, fmt.Errorf("region %s: %w", n, err)
+			}
+		}
+		regions = append(regions, r)
+	}
+
+	var unused []string
+	for n := range overrides {
+		if !seen[n] {
+			unused = append(unused, n)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return nil, fmt.Errorf("CIDR overrides for regions that are not tested: %s", strings.Join(unused, ", "))
+	}
+	return regions, nil
+}
+
+// ForEachRegion runs fn as a parallel subtest per GetTestRegions entry, named
+// after the region (e.g. TestVPC_CreateDescribeDestroy/europe-west4). Create
+// the budget inside fn, so each region is checked against the deadline.
+//
+// Usage:
+//
+//	ForEachRegion(t, func(t *testing.T, region TestRegion) {
+//		budget := NewBudget(t, "vpc")
+//		vpc := &VPCOptions{ProjectID: projectID, VPCName: Ptr(region.UniqueName(t, NameVPC, "test-vpc"))}
+//		region.ConfigureVPC(vpc)
+//		// ...
+//	})
+func ForEachRegion(t *testing.T, fn func(t *testing.T, region TestRegion)) {
+	t.Helper()
+	for _, r := range GetTestRegions(t) {
+		t.Run(r.Name, func(t *testing.T) {
+			t.Parallel()
+			fn(t, r)
+		})
+	}
+}
+
+// ConfigureVPC sets the region and any CIDR overrides on o.
+func (r TestRegion) ConfigureVPC(o *VPCOptions) {
+	o.Region = r.Name
+	if r.SubnetCIDR != "" {
+		o.SubnetIPRange = Ptr(r.SubnetCIDR)
+	}
+	if r.PodsCIDR != "" {
+		o.PodsIPRange = Ptr(r.PodsCIDR)
+	}
+	if r.ServicesCIDR != "" {
+		o.ServicesIPRange = Ptr(r.ServicesCIDR)
+	}
+}
+
+// UniqueName calls UniqueName with the region's Code appended to prefix, so
+// resources from different regions can be told apart. When the name would be
+// too long the prefix is shortened, not the code.
+func (r TestRegion) UniqueName(t *testing.T, kind NameKind, prefix string) string {
+	t.Helper()
+	code := r.Code()
+	if max := kind.MaxLen - nameSuffixLen - 1 - len(code) - 1; len(prefix) > max {
+		prefix = strings.TrimRight(prefix[:max], "-")
+	}
+	return UniqueName(t, kind, prefix+"-"+code)
+}
+
+// regionAreas and regionDirections abbreviate the parts of a region name.
+var (
+	regionAreas = map[string]string{
+		"africa": "af", "asia": "ap", "australia": "au", "europe": "eu", "me": "me",
+		"northamerica": "na", "southamerica": "sa", "us": "us",
+	}
+	regionDirections = map[string]string{
+		"central": "c", "east": "e", "west": "w", "north": "n", "south": "s",
+		"northeast": "ne", "northwest": "nw", "southeast": "se", "southwest": "sw",
+	}
+)
+
+// Code is a short form of the region name for resource names, e.g. usc1 for
+// us-central1 and apse1 for asia-southeast1. Unknown parts are cut to their
+// first two letters (area) or first letter (direction).
+func (r TestRegion) Code() string {
+	area, rest, _ := strings.Cut(r.Name, "-")
+	dir := strings.TrimRight(rest, "0123456789")
+	num := rest[len(dir):]
+
+	a, ok := regionAreas[area]
+	if !ok {
+		a = area[:min(2, len(area))]
+	}
+	d, ok := regionDirections[dir]
+	if !ok && dir != "" {
+		d = dir[:1]
+	}
+	return a + d + num
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTestRegions_Default(t *testing.T) {
+	t.Setenv("NEO4J_GKE_TEST_REGION", "europe-west4")
+	t.Setenv("NEO4J_GKE_TEST_REGIONS", "")
+	t.Setenv("NEO4J_GKE_TEST_REGION_OVERRIDES", "")
+
+	require.Equal(t, []TestRegion{{Name: "europe-west4"}}, GetTestRegions(t))
+}
+
+func TestGetTestRegions_ListAndOverrides(t *testing.T) {
+	overrides := filepath.Join(t.TempDir(), "regions.json")
+	require.NoError(t, os.WriteFile(overrides, []byte(`{
+  "europe-west4": {"subnet_ip_range": "10.20.0.0/24", "pods_ip_range": "10.21.0.0/16", "services_ip_range": "10.22.0.0/20"},
+  "asia-southeast1": {"pods_ip_range": "10.31.0.0/16"}
+}`), 0600))
+	t.Setenv("NEO4J_GKE_TEST_REGIONS", " us-central1, europe-west4,asia-southeast1 ")
+	t.Setenv("NEO4J_GKE_TEST_REGION_OVERRIDES", overrides)
+
+	require.Equal(t, []TestRegion{
+		{Name: "us-central1"},
+		{Name: "europe-west4", SubnetCIDR: "10.20.0.0/24", PodsCIDR: "10.21.0.0/16", ServicesCIDR: "10.22.0.0/20"},
+		{Name: "asia-southeast1", PodsCIDR: "10.31.0.0/16"},
+	}, GetTestRegions(t))
+}
+
+func TestParseTestRegions_Rejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		names     []string
+		overrides map[string]TestRegion
+		want      string
+	}{
+		{"bad region", []string{"us-central"}, nil, "not a GCP region name"},
+		{"duplicate", []string{"us-central1", "us-central1"}, nil, "listed twice"},
+		{"bad CIDR", []string{"us-central1"}, map[string]TestRegion{"us-central1": {PodsCIDR: "10.1.0.0"}}, "invalid CIDR"},
+		{"override for untested region", []string{"us-central1"}, map[string]TestRegion{"us-east1": {}}, "not tested: us-east1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseTestRegions(tc.names, tc.overrides)
+			require.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestTestRegion_ConfigureVPC(t *testing.T) {
+	vpc := &VPCOptions{ProjectID: "p", Region: "us-central1"}
+	TestRegion{Name: "europe-west4", PodsCIDR: "10.21.0.0/16"}.ConfigureVPC(vpc)
+
+	require.Equal(t, "europe-west4", vpc.Region)
+	require.Equal(t, "10.21.0.0/16", *vpc.PodsIPRange)
+	require.Nil(t, vpc.SubnetIPRange, "unset overrides keep the module default")
+	require.Nil(t, vpc.ServicesIPRange)
+}
+
+func TestTestRegion_Names(t *testing.T) {
+	codes := map[string]string{
+		"us-central1":             "usc1",
+		"europe-west4":            "euw4",
+		"asia-southeast1":         "apse1",
+		"northamerica-northeast2": "nane2",
+		"me-central2":             "mec2",
+		"antarctica-polar1":       "anp1",
+	}
+	for region, code := range codes {
+		require.Equal(t, code, TestRegion{Name: region}.Code(), region)
+	}
+
+	r := TestRegion{Name: "asia-southeast1"}
+	require.Regexp(t, `^test-vpc-apse1-[a-z0-9]{6}$`, r.UniqueName(t, NameVPC, "test-vpc"))
+
+	// The code survives shortening.
+	name := r.UniqueName(t, NameServiceAccount, "backup-service-account")
+	require.LessOrEqual(t, len(name), NameServiceAccount.MaxLen)
+	require.Regexp(t, `^backup-service-ac-apse1-[a-z0-9]{6}$`, name)
+}
@@ -2,48 +2,41 @@ package test
 
 import (
 	"fmt"
-	"strings"
 	"testing"
 
-	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/require"
 )
 
 func TestSecrets_CreateDescribeDestroy(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
+	t.Parallel()
 
-	// Validate timeout before creating resources
-	RequireMinimumTimeout(t, DefaultTestTimeout)
+	budget := NewBudget(t, "secrets")
 
 	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
 
-	tfDir := CopyModuleToTemp(t, "secrets")
-	suffix := strings.ToLower(random.UniqueId())
-	secretName := fmt.Sprintf("test-secret-%s", suffix)
-
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    tfDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id": projectID,
-			"secrets": map[string]any{
-				secretName: map[string]any{
-					"description": "Integration test secret",
-					"labels": map[string]string{
-						"test": "true",
-					},
-				},
+	secretName := UniqueName(t, NameSecret, "test-secret")
+
+	secrets := &SecretsOptions{
+		ProjectID: projectID,
+		Secrets: map[string]SecretsSecret{
+			secretName: {
+				Description: "Integration test secret",
+				Labels:      map[string]string{"test": "true"},
 			},
-			"enable_secret_manager_api": true,
 		},
-		NoColor: true,
-	})
+		EnableSecretManagerAPI: Ptr(true),
+	}
+	tf := secrets.TerraformOptions(t, CopyModuleToTemp(t, secrets.Module()))
 
 	// Register cleanup BEFORE creating resources
-	DeferredTerraformCleanup(t, tf)
-	terraform.InitAndApply(t, tf)
+	budget.Cleanup(secrets.Module(), tf)
+
+	// The API enablement is shared with other tests; its destroy is a no-op
+	// (disable_on_destroy = false), so the lease only needs to cover the apply.
+	apiLease := AcquireLease(t, ProjectServiceLeaseKey(projectID, "secretmanager.googleapis.com"))
+	budget.Apply(secrets.Module(), tf)
+	apiLease.Release(t)
 
 	// Verify secret was created
 	secretIDs := terraform.OutputMap(t, tf, "secret_ids")
@@ -51,148 +44,102 @@ func TestSecrets_CreateDescribeDestroy(t *testing.T) {
 	require.Equal(t, secretName, secretIDs[secretName])
 
 	// Verify secret exists via gcloud
-	out := runGCLOUD(t, projectID, "secrets", "describe", secretName, "--format=value(name)")
-	require.Contains(t, out, secretName)
+	secret := DescribeSecret(t, projectID, secretName)
+	require.Equal(t, secretName, secret.ID())
 
 	// Verify labels
-	out = runGCLOUD(t, projectID, "secrets", "describe", secretName, "--format=value(labels.test)")
-	require.Equal(t, "true", strings.TrimSpace(out))
+	require.Equal(t, "true", secret.Labels["test"])
 }
 
 func TestSecrets_WithAccessors(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
+	t.Parallel()
 
-	// Validate timeout before creating resources
-	RequireMinimumTimeout(t, DefaultTestTimeout)
+	budget := NewBudget(t, "service_accounts", "secrets")
 
 	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
 
 	// First create a service account to grant access to
-	saDir := CopyModuleToTemp(t, "service_accounts")
-	saSuffix := strings.ToLower(random.UniqueId())
-	saName := fmt.Sprintf("test-sa-%s", saSuffix)
-
-	saTf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    saDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id": projectID,
-			"service_accounts": map[string]any{
-				saName: map[string]any{
-					"description": "Test SA for secrets accessor",
-				},
-			},
-			"prevent_destroy_service_accounts": false,
+	saName := UniqueName(t, NameServiceAccount, "test-sa")
+
+	sa := &ServiceAccountsOptions{
+		ProjectID: projectID,
+		ServiceAccounts: map[string]ServiceAccountsServiceAccount{
+			saName: {Description: "Test SA for secrets accessor"},
 		},
-		NoColor: true,
-	})
+		PreventDestroyServiceAccounts: Ptr(false),
+	}
+	saTf := sa.TerraformOptions(t, CopyModuleToTemp(t, sa.Module()))
+
+	// SA email is known before creation
+	saEmail := fmt.Sprintf("%s@%s.iam.gserviceaccount.com", saName, projectID)
 
 	// Now create secret with accessor
-	secretDir := CopyModuleToTemp(t, "secrets")
-	suffix := strings.ToLower(random.UniqueId())
-	secretName := fmt.Sprintf("test-secret-acl-%s", suffix)
-
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    secretDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id": projectID,
-			"secrets": map[string]any{
-				secretName: map[string]any{
-					"description": "Secret with accessor",
-				},
-			},
-			"accessors": map[string][]string{
-				// Will be set after SA is created
-				secretName: {},
-			},
-			"enable_secret_manager_api": false, // Already enabled
+	secretName := UniqueName(t, NameSecret, "test-secret-acl")
+
+	secrets := &SecretsOptions{
+		ProjectID: projectID,
+		Secrets: map[string]SecretsSecret{
+			secretName: {Description: "Secret with accessor"},
+		},
+		Accessors: map[string][]string{
+			secretName: {fmt.Sprintf("serviceAccount:%s", saEmail)},
 		},
-		NoColor: true,
-	})
+		EnableSecretManagerAPI: Ptr(true),
+	}
+	tf := secrets.TerraformOptions(t, CopyModuleToTemp(t, secrets.Module()))
 
 	// Register cleanup for both resources BEFORE creating anything
 	// Order: secret cleanup first, then SA (LIFO)
-	DeferredTerraformCleanup(t, saTf)
-	DeferredTerraformCleanup(t, tf)
+	budget.Cleanup(sa.Module(), saTf)
+	budget.Cleanup(secrets.Module(), tf)
 
-	terraform.InitAndApply(t, saTf)
-
-	// Compute SA email from known format
-	saEmail := fmt.Sprintf("%s@%s.iam.gserviceaccount.com", saName, projectID)
-
-	// Create new secret options with the SA email (avoid mutating original)
-	tfApply := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    secretDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id": projectID,
-			"secrets": map[string]any{
-				secretName: map[string]any{
-					"description": "Secret with accessor",
-				},
-			},
-			"accessors": map[string][]string{
-				secretName: {fmt.Sprintf("serviceAccount:%s", saEmail)},
-			},
-			"enable_secret_manager_api": false,
-		},
-		NoColor: true,
-	})
-
-	terraform.InitAndApply(t, tfApply)
+	budget.Apply(sa.Module(), saTf)
+	apiLease := AcquireLease(t, ProjectServiceLeaseKey(projectID, "secretmanager.googleapis.com"))
+	budget.Apply(secrets.Module(), tf)
+	apiLease.Release(t)
 
 	// Verify secret was created
-	secretIDs := terraform.OutputMap(t, tfApply, "secret_ids")
+	secretIDs := terraform.OutputMap(t, tf, "secret_ids")
 	require.Contains(t, secretIDs, secretName)
 
 	// Verify IAM binding via gcloud
-	out := runGCLOUD(t, projectID, "secrets", "get-iam-policy", secretName, "--format=json")
-	require.Contains(t, out, saEmail)
-	require.Contains(t, out, "roles/secretmanager.secretAccessor")
+	policy := GetSecretIAMPolicy(t, projectID, secretName)
+	RequireIAMBinding(t, policy, "roles/secretmanager.secretAccessor", saEmail)
 }
 
 func TestSecrets_MultipleSecrets(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
+	t.Parallel()
 
-	// Validate timeout before creating resources
-	RequireMinimumTimeout(t, DefaultTestTimeout)
+	budget := NewBudget(t, "secrets")
 
 	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
 
-	tfDir := CopyModuleToTemp(t, "secrets")
-	suffix := strings.ToLower(random.UniqueId())
-
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    tfDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id": projectID,
-			"secrets": map[string]any{
-				fmt.Sprintf("secret-one-%s", suffix): map[string]any{
-					"description": "First secret",
-				},
-				fmt.Sprintf("secret-two-%s", suffix): map[string]any{
-					"description": "Second secret",
-					"labels": map[string]string{
-						"priority": "high",
-					},
-				},
+	secretOne := UniqueName(t, NameSecret, "secret-one")
+	secretTwo := UniqueName(t, NameSecret, "secret-two")
+
+	secrets := &SecretsOptions{
+		ProjectID: projectID,
+		Secrets: map[string]SecretsSecret{
+			secretOne: {Description: "First secret"},
+			secretTwo: {
+				Description: "Second secret",
+				Labels:      map[string]string{"priority": "high"},
 			},
-			"enable_secret_manager_api": false,
 		},
-		NoColor: true,
-	})
+		EnableSecretManagerAPI: Ptr(true),
+	}
+	tf := secrets.TerraformOptions(t, CopyModuleToTemp(t, secrets.Module()))
 
 	// Register cleanup BEFORE creating resources
-	DeferredTerraformCleanup(t, tf)
-	terraform.InitAndApply(t, tf)
+	budget.Cleanup(secrets.Module(), tf)
+
+	apiLease := AcquireLease(t, ProjectServiceLeaseKey(projectID, "secretmanager.googleapis.com"))
+	budget.Apply(secrets.Module(), tf)
+	apiLease.Release(t)
 
 	// Verify both secrets were created
 	secretIDs := terraform.OutputMap(t, tf, "secret_ids")
 	require.Len(t, secretIDs, 2)
-	require.Contains(t, secretIDs, fmt.Sprintf("secret-one-%s", suffix))
-	require.Contains(t, secretIDs, fmt.Sprintf("secret-two-%s", suffix))
+	require.Contains(t, secretIDs, secretOne)
+	require.Contains(t, secretIDs, secretTwo)
 }
@@ -2,11 +2,8 @@ package test
 
 import (
 	"encoding/json"
-	"fmt"
-	"strings"
 	"testing"
 
-	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/require"
 )
@@ -19,38 +16,27 @@ type saInfo struct {
 }
 
 func TestServiceAccounts_CreateDescribeDestroy(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
+	t.Parallel()
 
-	// Validate timeout before creating resources
-	RequireMinimumTimeout(t, DefaultTestTimeout)
+	budget := NewBudget(t, "service_accounts")
 
 	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
 
-	tfDir := CopyModuleToTemp(t, "service_accounts")
-	suffix := strings.ToLower(random.UniqueId())
-	prefix := fmt.Sprintf("it-%s-", suffix) // keeps ID <= 30 with short keys
+	prefix := UniqueName(t, NameServiceAccount, "it") + "-" // keeps ID <= 30 with short keys
 
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    tfDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id": projectID,
-			"sa_prefix":  prefix,
-			"service_accounts": map[string]any{
-				"ci": map[string]any{
-					"description": "Integration CI SA",
-					"disabled":    false,
-				},
-			},
-			"prevent_destroy_service_accounts": false, // allow cleanup
+	sa := &ServiceAccountsOptions{
+		ProjectID: projectID,
+		SAPrefix:  Ptr(prefix),
+		ServiceAccounts: map[string]ServiceAccountsServiceAccount{
+			"ci": {Description: "Integration CI SA", Disabled: Ptr(false)},
 		},
-		NoColor: true,
-	})
+		PreventDestroyServiceAccounts: Ptr(false), // allow cleanup
+	}
+	tf := sa.TerraformOptions(t, CopyModuleToTemp(t, sa.Module()))
 
 	// Register cleanup BEFORE creating resources
-	DeferredTerraformCleanup(t, tf)
-	terraform.InitAndApply(t, tf)
+	budget.Cleanup(sa.Module(), tf)
+	budget.Apply(sa.Module(), tf)
 
 	// Parse the output JSON
 	raw := terraform.OutputJson(t, tf, "service_accounts")
@@ -0,0 +1,181 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/require"
+)
+
+// --- state JSON helpers ---
+
+// State is a root's applied state, decoded from `tofu show -json`, with the
+// resource instances of the root and all of its child modules indexed by
+// address.
+//
+// Assert "what we applied" (bucket settings, labels, key names) from State;
+// keep gcloud for "what GCP enforces" (IAM, anonymous access, object
+// encryption), which state cannot show.
+type State struct {
+	Raw       *tfjson.State
+	Resources map[string]*tfjson.StateResource
+}
+
+// StateJSON decodes the current state of the root in options.TerraformDir.
+// Call it after apply. The caller's options are not mutated.
+//
+// Usage:
+//
+//	state := StateJSON(t, tf)
+//	RequireStateAttribute(t, state, "google_storage_bucket.backups", "versioning.0.enabled", true)
+//	bucket := StateBucket(t, state, "google_storage_bucket.backups")
+func StateJSON(t *testing.T, options *terraform.Options) *State {
+	t.Helper()
+	state, err := StateJSONE(t, options)
+	require.NoError(t, err, "tofu show failed")
+	return state
+}
+
+// StateJSONE is like StateJSON but returns the error instead of failing the
+// test.
+func StateJSONE(t *testing.T, options *terraform.Options) (*State, error) {
+	t.Helper()
+
+	// Without a plan file, show reports the state.
+	opts := *options
+	opts.PlanFilePath = ""
+
+	raw, err := terraform.ShowE(t, &opts)
+	if err != nil {
+		return nil, err
+	}
+	return parseStateJSON(raw)
+}
+
+// ParseStateJSON decodes raw `tofu show -json` state output, so offline tests
+// can feed recorded states through the same assertions.
+func ParseStateJSON(t *testing.T, raw string) *State {
+	t.Helper()
+	state, err := parseStateJSON(raw)
+	require.NoError(t, err, "failed to decode state JSON")
+	return state
+}
+
+func parseStateJSON(raw string) (*State, error) {
+	var s tfjson.State
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return nil, fmt.Errorf("decode state JSON: %w", err)
+	}
+	state := &State{Raw: &s, Resources: map[string]*tfjson.StateResource{}}
+	if s.Values != nil {
+		state.index(s.Values.RootModule)
+	}
+	return state, nil
+}
+
+func (s *State) index(m *tfjson.StateModule) {
+	if m == nil {
+		return
+	}
+	for _, r := range m.Resources {
+		// A deposed object shares its address with the current one.
+		if r.DeposedKey == "" {
+			s.Resources[r.Address] = r
+		}
+	}
+	for _, child := range m.ChildModules {
+		s.index(child)
+	}
+}
+
+// Addresses lists the resource addresses in state, sorted.
+func (s *State) Addresses() []string {
+	addrs := make([]string, 0, len(s.Resources))
+	for addr := range s.Resources {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// RequireStateResource returns the resource at addr, failing the test if it
+// is not in state.
+func RequireStateResource(t *testing.T, state *State, addr string) *tfjson.StateResource {
+	t.Helper()
+	r, ok := state.Resources[addr]
+	require.Truef(t, ok, "state has no resource %s (have: %s)", addr, strings.Join(state.Addresses(), ", "))
+	return r
+}
+
+// RequireStateAttribute asserts the applied value of an attribute. path and
+// want work as in RequirePlannedAttribute.
+func RequireStateAttribute(t *testing.T, state *State, addr, path string, want any) {
+	t.Helper()
+	got := RequireStateAttributeValue(t, state, addr, path)
+	require.Equalf(t, normalizeJSONValue(t, want), got, "%s: unexpected value for %q in state", addr, path)
+}
+
+// RequireStateAttributeValue returns the applied value of an attribute,
+// failing if it is not in state.
+func RequireStateAttributeValue(t *testing.T, state *State, addr, path string) any {
+	t.Helper()
+	r := RequireStateResource(t, state, addr)
+	got, ok := lookupPath(r.AttributeValues, path)
+	require.Truef(t, ok, "%s: attribute %q not present in state", addr, path)
+	return got
+}
+
+// DecodeStateResource decodes the attributes of the resource at addr into v,
+// a pointer to a struct whose json tags are attribute names. Nested blocks
+// decode as slices.
+func DecodeStateResource(t *testing.T, state *State, addr string, v any) {
+	t.Helper()
+	r := RequireStateResource(t, state, addr)
+	data, err := json.Marshal(r.AttributeValues)
+	require.NoError(t, err)
+	require.NoErrorf(t, json.Unmarshal(data, v), "decode %s from state", addr)
+}
+
+// bucketState is the part of a google_storage_bucket's state a Bucket holds.
+type bucketState struct {
+	Name                     string            `json:"name"`
+	Location                 string            `json:"location"`
+	Labels                   map[string]string `json:"labels"`
+	UniformBucketLevelAccess bool              `json:"uniform_bucket_level_access"`
+	PublicAccessPrevention   string            `json:"public_access_prevention"`
+	Versioning               []struct {
+		Enabled bool `json:"enabled"`
+	} `json:"versioning"`
+	Encryption []struct {
+		DefaultKMSKeyName string `json:"default_kms_key_name"`
+	} `json:"encryption"`
+}
+
+// StateBucket reads the google_storage_bucket at addr from state into the
+// same Bucket DescribeBucket returns. Labels are the configured labels, not
+// the provider's effective_labels.
+func StateBucket(t *testing.T, state *State, addr string) *Bucket {
+	t.Helper()
+	var raw bucketState
+	DecodeStateResource(t, state, addr, &raw)
+
+	b := &Bucket{
+		Name:                     raw.Name,
+		Location:                 raw.Location,
+		Labels:                   raw.Labels,
+		UniformBucketLevelAccess: raw.UniformBucketLevelAccess,
+		PublicAccessPrevention:   strings.ToLower(raw.PublicAccessPrevention),
+	}
+	if len(raw.Versioning) > 0 {
+		b.VersioningEnabled = raw.Versioning[0].Enabled
+	}
+	if len(raw.Encryption) > 0 {
+		b.DefaultKMSKey = raw.Encryption[0].DefaultKMSKeyName
+	}
+	return b
+}
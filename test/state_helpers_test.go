@@ -0,0 +1,55 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestStateHelpers_RecordedState exercises the state assertions against a
+// recorded `tofu show -json` state of the backup_bucket module.
+func TestStateHelpers_RecordedState(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "state", "backup_bucket.json"))
+	require.NoError(t, err)
+
+	state := ParseStateJSON(t, string(raw))
+
+	const addr = "google_storage_bucket.backups"
+	RequireStateAttribute(t, state, addr, "uniform_bucket_level_access", true)
+	RequireStateAttribute(t, state, addr, "versioning.0.enabled", true)
+	RequireStateAttribute(t, state, addr, "lifecycle_rule.1.condition.0.num_newer_versions", 5)
+	RequireStateAttribute(t, state, "google_storage_bucket_iam_member.backup_viewer", "role", "roles/storage.objectViewer")
+
+	bucket := StateBucket(t, state, addr)
+	require.Equal(t, &Bucket{
+		Name:                     "test-project-backup-test-abc123",
+		Location:                 "US-CENTRAL1",
+		UniformBucketLevelAccess: true,
+		PublicAccessPrevention:   "enforced",
+		VersioningEnabled:        true,
+		DefaultKMSKey:            "projects/test-project/locations/us-central1/keyRings/test-ring/cryptoKeys/test-key",
+		Labels:                   map[string]string{"test": "true", "test-run-id": "run-1"},
+	}, bucket)
+
+	// Child module instances are indexed by their full address, and a
+	// deposed object does not shadow the current one.
+	key := `module.keys.google_kms_crypto_key.keys["backup"]`
+	RequireStateAttribute(t, state, key, "name", "test-key")
+	require.Equal(t, []string{
+		"google_storage_bucket.backups",
+		"google_storage_bucket_iam_member.backup_creator",
+		"google_storage_bucket_iam_member.backup_viewer",
+		key,
+	}, state.Addresses())
+}
+
+func TestStateHelpers_EmptyState(t *testing.T) {
+	// `tofu show -json` after destroy has no values.
+	state := ParseStateJSON(t, `{"format_version":"1.0"}`)
+	require.Empty(t, state.Addresses())
+
+	_, err := parseStateJSON(`{"format_version":"9.0"}`)
+	require.Error(t, err, "unsupported state format versions should not decode")
+}
@@ -1,111 +1,97 @@
 package test
 
 import (
-	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
-	"time"
 
+	"github.com/gruntwork-io/terratest/modules/files"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	testStructure "github.com/gruntwork-io/terratest/modules/test-structure"
 	"github.com/stretchr/testify/require"
 )
 
-// Default test timeouts for different resource types.
-// These are minimum recommended timeouts to ensure cleanup runs.
-const (
-	// DefaultTestTimeout is the minimum timeout for quick tests
-	DefaultTestTimeout = 10 * time.Minute
-	// GKETestTimeout is the minimum timeout for tests that create GKE clusters
-	GKETestTimeout = 30 * time.Minute
-	// VPCTestTimeout is the minimum timeout for tests that create VPCs
-	VPCTestTimeout = 15 * time.Minute
-)
-
-// RequireMinimumTimeout validates that the test has sufficient timeout configured.
-// If the timeout is insufficient, the test is skipped rather than failed.
-// This prevents tests from timing out before cleanup can run while allowing
-// quick test runs (e.g., -short mode or default timeout) to skip long tests gracefully.
-// Call this at the start of any test that creates cloud resources.
-func RequireMinimumTimeout(t *testing.T, minimumTimeout time.Duration) {
-	t.Helper()
-
-	// Get the test timeout from the -timeout flag
-	// Default is 10 minutes if not specified
-	timeout := getTestTimeout()
-
-	if timeout > 0 && timeout < minimumTimeout {
-		t.Skipf("Skipping: test timeout (%v) is less than minimum required (%v). "+
-			"Run with -timeout=%v or higher. "+
-			"Example: go test -timeout=%v ./test/...",
-			timeout, minimumTimeout, minimumTimeout, minimumTimeout)
-	}
-}
-
-// getTestTimeout returns the test timeout from the -timeout flag.
-// Returns 0 if no timeout was specified (infinite).
-func getTestTimeout() time.Duration {
-	// The -timeout flag is parsed by the testing package
-	f := flag.Lookup("test.timeout")
-	if f == nil {
-		return 10 * time.Minute // Go's default
-	}
-
-	// Parse the duration value
-	if d, err := time.ParseDuration(f.Value.String()); err == nil {
-		return d
-	}
-
-	return 10 * time.Minute // Go's default
-}
-
 // DeferredTerraformCleanup registers terraform destroy as a cleanup function.
 // This uses t.Cleanup() which has better guarantees than defer:
 // - Runs even if the test calls t.FailNow() or t.Fatal()
 // - Runs cleanup functions in LIFO order
-// - Still subject to test timeout (see RequireMinimumTimeout)
+// - Still subject to test timeout (see Budget)
 //
-// IMPORTANT: For long-running tests, always call RequireMinimumTimeout() first
-// to ensure the test has enough time for both execution AND cleanup.
+// IMPORTANT: For long-running tests, use Budget.Cleanup and Budget.Apply so
+// the test keeps enough time for both execution AND cleanup.
 //
 // Usage:
 //
-//	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{...})
+//	tf := NewTerraformOptions(t, &terraform.Options{...})
 //	DeferredTerraformCleanup(t, tf)
 //	terraform.InitAndApply(t, tf)
 func DeferredTerraformCleanup(t *testing.T, options *terraform.Options) {
 	t.Helper()
 
 	t.Cleanup(func() {
-		// Use a recovery to handle any panics during cleanup
-		defer func() {
-			if r := recover(); r != nil {
-				t.Logf("CLEANUP PANIC (resources may be orphaned): %v", r)
-				t.Logf("TerraformDir: %s", options.TerraformDir)
-				t.Logf("Manual cleanup may be required")
-			}
-		}()
-
-		t.Logf("Running terraform destroy for cleanup...")
-		if _, err := terraform.DestroyE(t, options); err != nil {
-			t.Logf("CLEANUP ERROR (resources may be orphaned): %v", err)
-			t.Logf("TerraformDir: %s", options.TerraformDir)
-			t.Logf("Manual cleanup may be required")
-		}
+		_ = RunTerraformCleanup(t, options)
 	})
 }
 
+// RunTerraformCleanup runs terraform destroy immediately, with the same panic
+// recovery, retries and orphan recording as DeferredTerraformCleanup. Use it
+// from custom teardown code (e.g. staged e2e tests) that decides itself when
+// to destroy. Transient GCP errors are retried with backoff; a destroy that
+// still fails is written to the orphan ledger (see RecordOrphan). The error is
+// returned for callers that need to know whether anything may have been
+// orphaned; it has already been logged.
+func RunTerraformCleanup(t *testing.T, options *terraform.Options) (err error) {
+	t.Helper()
+
+	// Use a recovery to handle any panics during cleanup
+	defer func() {
+		if r := recover(); r != nil {
+			t.Logf("CLEANUP PANIC (resources may be orphaned): %v", r)
+			err = fmt.Errorf("panic during terraform destroy: %v", r)
+		}
+		if err != nil {
+			recordOrphanAndLog(t, options, err)
+		}
+	}()
+
+	t.Logf("Running terraform destroy for cleanup...")
+	if err = destroyWithRetry(t, options); err != nil {
+		t.Logf("CLEANUP ERROR (resources may be orphaned): %v", err)
+	}
+	return err
+}
+
+// recordOrphanAndLog writes a failed destroy to the orphan ledger and tells
+// the reader how to replay it.
+func recordOrphanAndLog(t *testing.T, options *terraform.Options, destroyErr error) {
+	t.Helper()
+
+	t.Logf("TerraformDir: %s", options.TerraformDir)
+	rec, err := RecordOrphan(t, options, destroyErr)
+	if err != nil {
+		t.Logf("WARNING: failed to record orphan in ledger: %v", err)
+		t.Logf("Manual cleanup may be required")
+		return
+	}
+	if len(rec.Addresses) == 0 {
+		t.Logf("State holds no managed resources; nothing recorded in the orphan ledger")
+		return
+	}
+	t.Logf("Recorded %d orphaned resource(s) as %s; replay with: go run ./cmd/orphans replay -id %s",
+		len(rec.Addresses), rec.ID, rec.ID)
+}
+
 // DeferredTerraformCleanupMultiple registers multiple terraform options for cleanup.
 // Cleanup runs in reverse order (LIFO) - last registered is cleaned up first.
 // This is useful for tests that create dependent resources (e.g., GKE depends on VPC).
 //
 // Usage:
 //
-//	vpcTf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{...})
-//	gkeTf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{...})
+//	vpcTf := NewTerraformOptions(t, &terraform.Options{...})
+//	gkeTf := NewTerraformOptions(t, &terraform.Options{...})
 //	DeferredTerraformCleanupMultiple(t, vpcTf, gkeTf)  // gkeTf destroyed first, then vpcTf
 //	terraform.InitAndApply(t, vpcTf)
 //	terraform.InitAndApply(t, gkeTf)
@@ -118,11 +104,16 @@ func DeferredTerraformCleanupMultiple(t *testing.T, options ...*terraform.Option
 	}
 }
 
-// MustEnv fetches an environment variable or fails the test.
+// MustEnv fetches an environment variable or fails the test. In -short mode
+// a missing variable skips the test instead, so the offline tier runs
+// without a GCP project.
 func MustEnv(t *testing.T, k string) string {
 	t.Helper()
 	v := os.Getenv(k)
 	if v == "" {
+		if testing.Short() {
+			t.Skipf("Skipping in short mode: %s is not set", k)
+		}
 		t.Fatalf("required environment variable %s is not set", k)
 	}
 	return v
@@ -165,6 +156,19 @@ func CopyModuleToTemp(t *testing.T, moduleRelativePath string) string {
 	)
 }
 
+// CopyModuleToDir copies a module into a fresh directory under destRoot.
+// Unlike CopyModuleToTemp it always copies, even when a SKIP_<stage> variable
+// is set (terratest otherwise falls back to the source folder), so staged tests
+// never write state into the repository.
+func CopyModuleToDir(t *testing.T, moduleRelativePath, destRoot string) string {
+	t.Helper()
+
+	root, err := files.CopyTerraformFolderToDest(RepoRoot(t), destRoot, strings.ReplaceAll(moduleRelativePath, "/", "-"))
+	require.NoError(t, err, "failed to copy module %s to %s", moduleRelativePath, destRoot)
+
+	return filepath.Join(root, "infra", "modules", moduleRelativePath)
+}
+
 // CopyEnvToTemp copies an environment configuration to a temp directory for testing.
 // The envPath should be relative to infra/envs/ (e.g., "bootstrap", "dev").
 func CopyEnvToTemp(t *testing.T, envPath string) string {
@@ -178,7 +182,8 @@ func CopyEnvToTemp(t *testing.T, envPath string) string {
 }
 
 // GetTestRegion returns the GCP region for tests, defaulting to us-central1.
-// Override via NEO4J_GKE_TEST_REGION environment variable.
+// Override via NEO4J_GKE_TEST_REGION environment variable. Tests that run in
+// every configured region use ForEachRegion instead.
 func GetTestRegion(t *testing.T) string {
 	t.Helper()
 	region := os.Getenv("NEO4J_GKE_TEST_REGION")
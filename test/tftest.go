@@ -0,0 +1,292 @@
+package test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/terratest/modules/logger"
+	"github.com/gruntwork-io/terratest/modules/shell"
+	"github.com/stretchr/testify/require"
+)
+
+// --- tofu test suites ---
+
+// tofuTestGlobs find the roots whose tests/ directory holds tofu test files.
+var tofuTestGlobs = []string{
+	"infra/modules/*/tests/*.tftest.hcl",
+	"infra/envs/*/tests/*.tftest.hcl",
+}
+
+// TofuTestSuites returns the module and environment roots with a tofu test
+// suite, relative to the repo root and sorted (e.g. "infra/modules/vpc").
+func TofuTestSuites(t *testing.T) []string {
+	t.Helper()
+	root := RepoRoot(t)
+	seen := map[string]bool{}
+	for _, glob := range tofuTestGlobs {
+		matches, err := filepath.Glob(filepath.Join(root, glob))
+		require.NoError(t, err)
+		for _, m := range matches {
+			rel, err := filepath.Rel(root, filepath.Dir(filepath.Dir(m)))
+			require.NoError(t, err)
+			seen[filepath.ToSlash(rel)] = true
+		}
+	}
+	suites := make([]string, 0, len(seen))
+	for s := range seen {
+		suites = append(suites, s)
+	}
+	sort.Strings(suites)
+	return suites
+}
+
+// TofuTestRun is one run block's outcome.
+type TofuTestRun struct {
+	File string
+	Name string
+	// Status is pass, fail, error, skip or pending (not reached).
+	Status      string
+	Diagnostics []TofuDiagnostic
+}
+
+// TofuDiagnostic is a diagnostic from `tofu test -json`. For a failed assert
+// Detail is the assert's error_message.
+type TofuDiagnostic struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail"`
+}
+
+func (d TofuDiagnostic) String() string {
+	if d.Detail == "" {
+		return d.Summary
+	}
+	return d.Summary + ": " + d.Detail
+}
+
+// TofuTestReport is a decoded `tofu test -json` stream.
+type TofuTestReport struct {
+	// Runs are in the order of the test abstract, or of their first
+	// message when tofu gave no abstract.
+	Runs []*TofuTestRun
+	// FileDiagnostics are diagnostics raised by a file outside any run
+	// (e.g. a bad variables block), keyed by file.
+	FileDiagnostics map[string][]TofuDiagnostic
+	// Diagnostics are diagnostics not tied to a file (e.g. init problems).
+	Diagnostics []TofuDiagnostic
+	// Status is the test_summary status, empty when tofu stopped before it.
+	Status string
+}
+
+// Files returns the test files in the order of their first run, followed by
+// files that only have file diagnostics.
+func (r *TofuTestReport) Files() []string {
+	var files, extra []string
+	seen := map[string]bool{}
+	for _, run := range r.Runs {
+		if !seen[run.File] {
+			seen[run.File] = true
+			files = append(files, run.File)
+		}
+	}
+	for f := range r.FileDiagnostics {
+		if !seen[f] {
+			extra = append(extra, f)
+		}
+	}
+	sort.Strings(extra)
+	return append(files, extra...)
+}
+
+// tofuTestMessage is one line of `tofu test -json`.
+type tofuTestMessage struct {
+	Type       string          `json:"type"`
+	File       string          `json:"@testfile"`
+	Run        string          `json:"@testrun"`
+	Diagnostic *TofuDiagnostic `json:"diagnostic"`
+	TestRun    *struct {
+		Path     string `json:"path"`
+		Run      string `json:"run"`
+		Status   string `json:"status"`
+		Progress string `json:"progress"`
+	} `json:"test_run"`
+	TestSummary *struct {
+		Status string `json:"status"`
+	} `json:"test_summary"`
+	TestAbstract map[string][]string `json:"test_abstract"`
+}
+
+// ParseTofuTestJSON decodes the output of `tofu test -json`. Lines that are
+// not JSON are ignored, as are message types the report does not use.
+func ParseTofuTestJSON(r io.Reader) (*TofuTestReport, error) {
+	report := &TofuTestReport{FileDiagnostics: map[string][]TofuDiagnostic{}}
+	runs := map[[2]string]*TofuTestRun{}
+	run := func(file, name string) *TofuTestRun {
+		key := [2]string{file, name}
+		if runs[key] == nil {
+			runs[key] = &TofuTestRun{File: file, Name: name, Status: "pending"}
+			report.Runs = append(report.Runs, runs[key])
+		}
+		return runs[key]
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var msg tofuTestMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			return nil, fmt.Errorf("decode tofu test output %q: %w", line, err)
+		}
+		switch {
+		case msg.Type == "test_abstract":
+			// The abstract lists every run up front, so runs tofu never
+			// reaches are still reported (as pending).
+			files := make([]string, 0, len(msg.TestAbstract))
+			for f := range msg.TestAbstract {
+				files = append(files, f)
+			}
+			sort.Strings(files)
+			for _, f := range files {
+				for _, name := range msg.TestAbstract[f] {
+					run(f, name)
+				}
+			}
+		case msg.Type == "test_run" && msg.TestRun != nil:
+			r := run(msg.TestRun.Path, msg.TestRun.Run)
+			// Newer versions also report starting/running progress; only
+			// the completed status counts.
+			if msg.TestRun.Progress == "" || msg.TestRun.Progress == "complete" {
+				r.Status = msg.TestRun.Status
+			}
+		case msg.Type == "diagnostic" && msg.Diagnostic != nil:
+			switch {
+			case msg.Run != "":
+				r := run(msg.File, msg.Run)
+				r.Diagnostics = append(r.Diagnostics, *msg.Diagnostic)
+			case msg.File != "":
+				report.FileDiagnostics[msg.File] = append(report.FileDiagnostics[msg.File], *msg.Diagnostic)
+			default:
+				report.Diagnostics = append(report.Diagnostics, *msg.Diagnostic)
+			}
+		case msg.Type == "test_summary" && msg.TestSummary != nil:
+			report.Status = msg.TestSummary.Status
+		}
+	}
+	return report, sc.Err()
+}
+
+// RunTofuTest runs `tofu test -json` in a copy of the root at dir (relative
+// to the repo root) after `tofu init -backend=false`. A failing suite is not
+// an error; the report carries the failures. It errors when init fails or
+// tofu exits without a summary.
+func RunTofuTest(t *testing.T, dir string) (*TofuTestReport, error) {
+	t.Helper()
+
+	var work string
+	switch {
+	case strings.HasPrefix(dir, "infra/modules/"):
+		work = CopyModuleToTemp(t, strings.TrimPrefix(dir, "infra/modules/"))
+	case strings.HasPrefix(dir, "infra/envs/"):
+		work = CopyEnvToTemp(t, strings.TrimPrefix(dir, "infra/envs/"))
+	default:
+		return nil, fmt.Errorf("%s is not under infra/modules or infra/envs", dir)
+	}
+
+	tofu := func(args ...string) (string, error) {
+		return shell.RunCommandAndGetStdOutE(t, shell.Command{
+			Command:    "tofu",
+			Args:       args,
+			WorkingDir: work,
+			// Suites on the real google provider only plan, which makes no
+			// API calls, but the provider still wants credentials.
+			Env: map[string]string{
+				"TF_IN_AUTOMATION":          "1",
+				"GOOGLE_OAUTH_ACCESS_TOKEN": "plan-only",
+			},
+			Logger: logger.Discard,
+		})
+	}
+	if _, err := tofu("init", "-backend=false", "-input=false", "-no-color"); err != nil {
+		return nil, fmt.Errorf("tofu init in %s: %w", dir, err)
+	}
+	out, runErr := tofu("test", "-json", "-no-color")
+	report, err := ParseTofuTestJSON(strings.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+	if report.Status == "" {
+		if runErr == nil {
+			runErr = fmt.Errorf("no test_summary in output")
+		}
+		return report, fmt.Errorf("tofu test in %s: %w", dir, runErr)
+	}
+	return report, nil
+}
+
+// RequireTofuTestSuite runs the suite at dir and reports each test file as a
+// subtest, with each of its run blocks as a subtest of that. A failed run
+// fails its subtest with the diagnostics tofu gave, which for a failed assert
+// is its error_message; skipped and unreached runs are skipped.
+//
+// Usage:
+//
+//	RequireTofuTestSuite(t, "infra/modules/vpc")
+//	// go test -run 'TestTofuTestSuites/vpc/vpc/plan_basic_vpc'
+func RequireTofuTestSuite(t *testing.T, dir string) {
+	t.Helper()
+	report, err := RunTofuTest(t, dir)
+	if report != nil {
+		for _, d := range report.Diagnostics {
+			t.Errorf("%s: %s", dir, d)
+		}
+	}
+	require.NoError(t, err)
+	require.NotEmptyf(t, report.Runs, "tofu test in %s reported no runs", dir)
+
+	for _, file := range report.Files() {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".tftest.hcl"), func(t *testing.T) {
+			for _, d := range report.FileDiagnostics[file] {
+				t.Errorf("%s: %s", file, d)
+			}
+			for _, run := range report.Runs {
+				if run.File != file {
+					continue
+				}
+				t.Run(run.Name, func(t *testing.T) {
+					reportTofuTestRun(t, run)
+				})
+			}
+		})
+	}
+}
+
+func reportTofuTestRun(t *testing.T, run *TofuTestRun) {
+	t.Helper()
+	switch run.Status {
+	case "pass":
+		for _, d := range run.Diagnostics {
+			t.Logf("%s: %s", d.Severity, d)
+		}
+	case "skip":
+		t.Skipf("run %q skipped by tofu test", run.Name)
+	case "pending":
+		t.Skipf("run %q not reached (an earlier run in %s failed)", run.Name, run.File)
+	default:
+		if len(run.Diagnostics) == 0 {
+			t.Errorf("run %q: %s", run.Name, run.Status)
+		}
+		for _, d := range run.Diagnostics {
+			t.Errorf("run %q (%s): %s", run.Name, run.Status, d)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestTofuTestSuites runs every tests/*.tftest.hcl suite under infra/ through
+// `tofu test -json`, one subtest per suite, test file and run block
+// (TestTofuTestSuites/<root>/<file>/<run>). The suites only plan, against mock
+// providers or the google provider with a placeholder token, so this runs in
+// -short mode with no GCP project or credentials.
+func TestTofuTestSuites(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("tofu"); err != nil {
+		t.Skip("Skipping tofu test suites: tofu is not on PATH")
+	}
+
+	suites := TofuTestSuites(t)
+	require.NotEmpty(t, suites)
+	for _, dir := range suites {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			t.Parallel()
+			RequireTofuTestSuite(t, dir)
+		})
+	}
+}
+
+func TestTofuTestSuites_Discovery(t *testing.T) {
+	suites := TofuTestSuites(t)
+	require.Contains(t, suites, "infra/modules/vpc")
+	require.Contains(t, suites, "infra/modules/neo4j_app")
+	require.Contains(t, suites, "infra/envs/bootstrap")
+
+	// Subtests are named by the root's base name, so it must be unique.
+	names := map[string]string{}
+	for _, dir := range suites {
+		name := filepath.Base(dir)
+		require.NotContainsf(t, names, name, "%s and %s share a subtest name", names[name], dir)
+		names[name] = dir
+	}
+}
+
+func TestParseTofuTestJSON(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "tofutest", "vpc.jsonl"))
+	require.NoError(t, err)
+
+	report, err := ParseTofuTestJSON(strings.NewReader("Initializing...\n" + string(raw)))
+	require.NoError(t, err)
+	require.Equal(t, "fail", report.Status)
+	require.Equal(t, []string{"tests/extra.tftest.hcl", "tests/vpc.tftest.hcl"}, report.Files())
+
+	byName := map[string]*TofuTestRun{}
+	for _, r := range report.Runs {
+		byName[r.Name] = r
+	}
+	require.Len(t, byName, 4)
+	require.Equal(t, "pass", byName["plan_basic_vpc"].Status)
+	require.Equal(t, "skip", byName["plan_after_nat"].Status)
+	require.Equal(t, "pending", byName["plan_skipped"].Status, "runs only in the abstract were never reached")
+
+	nat := byName["plan_nat"]
+	require.Equal(t, "fail", nat.Status, "the starting progress message must not win")
+	require.Equal(t, "tests/vpc.tftest.hcl", nat.File)
+	require.Len(t, nat.Diagnostics, 2)
+	require.Equal(t, "Test assertion failed: Cloud NAT should be created when enabled.", nat.Diagnostics[0].String())
+	require.Equal(t, "warning", nat.Diagnostics[1].Severity)
+
+	require.Equal(t, []TofuDiagnostic{{Severity: "error", Summary: "Invalid value for variable", Detail: "region must be a GCP region."}},
+		report.FileDiagnostics["tests/extra.tftest.hcl"])
+	require.Empty(t, report.Diagnostics)
+}
+
+func TestParseTofuTestJSON_NoSummary(t *testing.T) {
+	report, err := ParseTofuTestJSON(strings.NewReader(
+		`{"type":"diagnostic","diagnostic":{"severity":"error","summary":"Failed to load plugin schemas"}}` + "\n"))
+	require.NoError(t, err)
+	require.Empty(t, report.Status)
+	require.Empty(t, report.Runs)
+	require.Equal(t, "Failed to load plugin schemas", report.Diagnostics[0].String())
+
+	_, err = ParseTofuTestJSON(strings.NewReader("{not json}\n"))
+	require.ErrorContains(t, err, "decode tofu test output")
+}
@@ -2,10 +2,8 @@ package test
 
 import (
 	"fmt"
-	"strings"
 	"testing"
 
-	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/require"
 )
@@ -16,128 +14,121 @@ import (
 //
 //	go test -timeout 15m -v ./test/... -run TestVPC_CreateDescribeDestroy
 func TestVPC_CreateDescribeDestroy(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
-
-	// Validate timeout before creating resources
-	RequireMinimumTimeout(t, VPCTestTimeout)
-
-	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
-	region := GetTestRegion(t)
-
-	tfDir := CopyModuleToTemp(t, "vpc")
-	suffix := strings.ToLower(random.UniqueId())
-	vpcName := fmt.Sprintf("test-vpc-%s", suffix)
-
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    tfDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":       projectID,
-			"region":           region,
-			"vpc_name":         vpcName,
-			"enable_cloud_nat": true,
-		},
-		NoColor: true,
+	t.Parallel()
+
+	ForEachRegion(t, func(t *testing.T, region TestRegion) {
+		budget := NewBudget(t, "vpc")
+
+		projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
+
+		vpcName := region.UniqueName(t, NameVPC, "test-vpc")
+
+		vpc := &VPCOptions{
+			ProjectID:      projectID,
+			VPCName:        Ptr(vpcName),
+			EnableCloudNAT: Ptr(true),
+		}
+		region.ConfigureVPC(vpc)
+		tf := vpc.TerraformOptions(t, CopyModuleToTemp(t, vpc.Module()))
+
+		// Register cleanup BEFORE creating resources
+		budget.Cleanup(vpc.Module(), tf)
+		budget.Apply(vpc.Module(), tf)
+
+		// Verify VPC exists
+		networkName, err := terraform.OutputE(t, tf, "network_name")
+		require.NoError(t, err, "failed to get network_name output")
+		require.Equal(t, vpcName, networkName)
+
+		// Verify subnet exists with secondary ranges
+		subnetName, err := terraform.OutputE(t, tf, "subnet_name")
+		require.NoError(t, err, "failed to get subnet_name output")
+		require.NotEmpty(t, subnetName)
+
+		// Verify secondary range names
+		podsRangeName, err := terraform.OutputE(t, tf, "pods_range_name")
+		require.NoError(t, err, "failed to get pods_range_name output")
+		require.Equal(t, "pods", podsRangeName)
+
+		servicesRangeName, err := terraform.OutputE(t, tf, "services_range_name")
+		require.NoError(t, err, "failed to get services_range_name output")
+		require.Equal(t, "services", servicesRangeName)
+
+		// Verify VPC exists via gcloud and is custom mode (not auto)
+		network := DescribeNetwork(t, projectID, vpcName)
+		require.Equal(t, vpcName, network.Name)
+		require.False(t, network.AutoCreateSubnetworks, "expected a custom-mode VPC")
+
+		// Verify subnet exists with private Google access
+		subnet := DescribeSubnet(t, projectID, region.Name, subnetName)
+		require.True(t, subnet.PrivateIPGoogleAccess, "expected private Google access on the subnet")
+
+		// Verify subnet has secondary ranges
+		pods, ok := subnet.SecondaryRange("pods")
+		require.True(t, ok, "missing pods secondary range")
+		services, ok := subnet.SecondaryRange("services")
+		require.True(t, ok, "missing services secondary range")
+
+		// Verify the region's CIDR overrides, where it has them
+		for _, c := range []struct{ want, got, what string }{
+			{region.SubnetCIDR, subnet.IPCIDRRange, "subnet"},
+			{region.PodsCIDR, pods.IPCIDRRange, "pods"},
+			{region.ServicesCIDR, services.IPCIDRRange, "services"},
+		} {
+			if c.want != "" {
+				require.Equalf(t, c.want, c.got, "%s range is not the %s override", c.what, region.Name)
+			}
+		}
+
+		// Verify Cloud NAT exists
+		natName, err := terraform.OutputE(t, tf, "nat_name")
+		require.NoError(t, err, "failed to get nat_name output")
+		require.NotEmpty(t, natName)
+
+		nat := DescribeRouterNAT(t, projectID, region.Name, fmt.Sprintf("%s-router", vpcName), natName)
+		require.Equal(t, natName, nat.Name)
 	})
-
-	// Register cleanup BEFORE creating resources
-	DeferredTerraformCleanup(t, tf)
-	terraform.InitAndApply(t, tf)
-
-	// Verify VPC exists
-	networkName, err := terraform.OutputE(t, tf, "network_name")
-	require.NoError(t, err, "failed to get network_name output")
-	require.Equal(t, vpcName, networkName)
-
-	// Verify subnet exists with secondary ranges
-	subnetName, err := terraform.OutputE(t, tf, "subnet_name")
-	require.NoError(t, err, "failed to get subnet_name output")
-	require.NotEmpty(t, subnetName)
-
-	// Verify secondary range names
-	podsRangeName, err := terraform.OutputE(t, tf, "pods_range_name")
-	require.NoError(t, err, "failed to get pods_range_name output")
-	require.Equal(t, "pods", podsRangeName)
-
-	servicesRangeName, err := terraform.OutputE(t, tf, "services_range_name")
-	require.NoError(t, err, "failed to get services_range_name output")
-	require.Equal(t, "services", servicesRangeName)
-
-	// Verify VPC exists via gcloud
-	out := runGCLOUD(t, projectID, "compute", "networks", "describe", vpcName, "--format=value(name)")
-	require.Equal(t, vpcName, strings.TrimSpace(out))
-
-	// Verify VPC is custom mode (not auto)
-	out = runGCLOUD(t, projectID, "compute", "networks", "describe", vpcName, "--format=value(autoCreateSubnetworks)")
-	require.Equal(t, "false", strings.ToLower(strings.TrimSpace(out)))
-
-	// Verify subnet exists with private Google access
-	out = runGCLOUD(t, projectID, "compute", "networks", "subnets", "describe", subnetName,
-		"--region", region, "--format=value(privateIpGoogleAccess)")
-	requireGcloudBoolTrue(t, out)
-
-	// Verify subnet has secondary ranges
-	out = runGCLOUD(t, projectID, "compute", "networks", "subnets", "describe", subnetName,
-		"--region", region, "--format=value(secondaryIpRanges[0].rangeName)")
-	require.Equal(t, "pods", strings.TrimSpace(out))
-
-	// Verify Cloud NAT exists
-	natName, err := terraform.OutputE(t, tf, "nat_name")
-	require.NoError(t, err, "failed to get nat_name output")
-	require.NotEmpty(t, natName)
-
-	out = runGCLOUD(t, projectID, "compute", "routers", "nats", "describe", natName,
-		"--router", fmt.Sprintf("%s-router", vpcName), "--region", region, "--format=value(name)")
-	require.Equal(t, natName, strings.TrimSpace(out))
 }
 
 func TestVPC_WithoutCloudNAT(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
-
-	// Validate timeout before creating resources
-	RequireMinimumTimeout(t, VPCTestTimeout)
-
-	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
-	region := GetTestRegion(t)
-
-	tfDir := CopyModuleToTemp(t, "vpc")
-	suffix := strings.ToLower(random.UniqueId())
-	vpcName := fmt.Sprintf("test-vpc-nonat-%s", suffix)
-
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    tfDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":       projectID,
-			"region":           region,
-			"vpc_name":         vpcName,
-			"enable_cloud_nat": false,
-		},
-		NoColor: true,
+	t.Parallel()
+
+	ForEachRegion(t, func(t *testing.T, region TestRegion) {
+		budget := NewBudget(t, "vpc")
+
+		projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
+
+		vpcName := region.UniqueName(t, NameVPC, "test-vpc-nonat")
+
+		vpc := &VPCOptions{
+			ProjectID:      projectID,
+			VPCName:        Ptr(vpcName),
+			EnableCloudNAT: Ptr(false),
+		}
+		region.ConfigureVPC(vpc)
+		tf := vpc.TerraformOptions(t, CopyModuleToTemp(t, vpc.Module()))
+
+		// Register cleanup BEFORE creating resources
+		budget.Cleanup(vpc.Module(), tf)
+		budget.Apply(vpc.Module(), tf)
+
+		// Verify VPC exists
+		networkName, err := terraform.OutputE(t, tf, "network_name")
+		require.NoError(t, err, "failed to get network_name output")
+		require.Equal(t, vpcName, networkName)
+
+		// Verify no NAT was created - outputs are null when NAT disabled,
+		// which causes OutputE to error (OpenTofu treats null as "not found")
+		natName, err := terraform.OutputE(t, tf, "nat_name")
+		if err == nil {
+			require.Empty(t, natName, "nat_name should be empty when Cloud NAT is disabled")
+		}
+		// If err != nil, that's expected - output value is null
+
+		routerName, err := terraform.OutputE(t, tf, "router_name")
+		if err == nil {
+			require.Empty(t, routerName, "router_name should be empty when Cloud NAT is disabled")
+		}
+		// If err != nil, that's expected - output value is null
 	})
-
-	// Register cleanup BEFORE creating resources
-	DeferredTerraformCleanup(t, tf)
-	terraform.InitAndApply(t, tf)
-
-	// Verify VPC exists
-	networkName, err := terraform.OutputE(t, tf, "network_name")
-	require.NoError(t, err, "failed to get network_name output")
-	require.Equal(t, vpcName, networkName)
-
-	// Verify no NAT was created - outputs are null when NAT disabled,
-	// which causes OutputE to error (OpenTofu treats null as "not found")
-	natName, err := terraform.OutputE(t, tf, "nat_name")
-	if err == nil {
-		require.Empty(t, natName, "nat_name should be empty when Cloud NAT is disabled")
-	}
-	// If err != nil, that's expected - output value is null
-
-	routerName, err := terraform.OutputE(t, tf, "router_name")
-	if err == nil {
-		require.Empty(t, routerName, "router_name should be empty when Cloud NAT is disabled")
-	}
-	// If err != nil, that's expected - output value is null
 }
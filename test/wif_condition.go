@@ -0,0 +1,217 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+// --- WIF attribute condition evaluation ---
+
+// wifProviderAddrs are the two variants of the wif module's provider; exactly
+// one is planned.
+var wifProviderAddrs = []string{
+	"google_iam_workload_identity_pool_provider.provider_protected[0]",
+	"google_iam_workload_identity_pool_provider.provider_unprotected[0]",
+}
+
+// PlannedWIFProvider reads the provider the wif module renders from a plan,
+// so its trust policy can be evaluated without creating a pool. Only the
+// attribute mapping and condition and the OIDC settings are filled in.
+func PlannedWIFProvider(t *testing.T, plan *terraform.PlanStruct) *WIFProvider {
+	t.Helper()
+	for _, addr := range wifProviderAddrs {
+		if _, ok := plan.ResourceChangesMap[addr]; !ok {
+			continue
+		}
+		var p WIFProvider
+		p.DisplayName, _ = RequirePlannedAttributeValue(t, plan, addr, "display_name").(string)
+		condition, ok := RequirePlannedAttributeValue(t, plan, addr, "attribute_condition").(string)
+		require.Truef(t, ok, "%s: attribute_condition is not a string", addr)
+		p.AttributeCondition = condition
+		mapping, ok := RequirePlannedAttributeValue(t, plan, addr, "attribute_mapping").(map[string]any)
+		require.Truef(t, ok, "%s: attribute_mapping is not a map", addr)
+		p.AttributeMapping = map[string]string{}
+		for k, v := range mapping {
+			p.AttributeMapping[k] = fmt.Sprint(v)
+		}
+		p.OIDC.IssuerURI, _ = RequirePlannedAttributeValue(t, plan, addr, "oidc.0.issuer_uri").(string)
+		audiences, _ := RequirePlannedAttributeValue(t, plan, addr, "oidc.0.allowed_audiences").([]any)
+		for _, a := range audiences {
+			p.OIDC.AllowedAudiences = append(p.OIDC.AllowedAudiences, fmt.Sprint(a))
+		}
+		return &p
+	}
+	require.FailNowf(t, "no wif provider", "plan has neither %s (have: %s)",
+		strings.Join(wifProviderAddrs, " nor "), strings.Join(plannedAddresses(plan), ", "))
+	return nil
+}
+
+// wifEnv declares the variables Google exposes to mappings and conditions:
+// the token's claims as assertion, and the mapped google.* and attribute.*
+// values.
+var wifEnv = func() *cel.Env {
+	env, err := cel.NewEnv(
+		cel.Variable("assertion", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("google", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("attribute", cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return env
+}()
+
+// Accepts reports whether a token with claims passes p: each mapping is
+// evaluated against the claims, then the condition against the mapped
+// attributes. A mapping whose claim is missing leaves its attribute unset. A
+// condition that fails at runtime (e.g. on an unset attribute) rejects the
+// token, as Google does. Expressions that do not compile are errors; Google
+// would reject them when the provider is created.
+func (p *WIFProvider) Accepts(claims map[string]any) (bool, error) {
+	google, attribute, err := p.mapClaims(claims)
+	if err != nil {
+		return false, err
+	}
+	return p.evalCondition(claims, google, attribute)
+}
+
+// mapClaims evaluates the attribute mapping against claims into the google
+// and attribute variables.
+func (p *WIFProvider) mapClaims(claims map[string]any) (google, attribute map[string]any, err error) {
+	google, attribute = map[string]any{}, map[string]any{}
+	for key, expr := range p.AttributeMapping {
+		ns, name, ok := strings.Cut(key, ".")
+		if !ok || (ns != "google" && ns != "attribute") {
+			return nil, nil, fmt.Errorf("attribute mapping key %q must be google.<name> or attribute.<name>", key)
+		}
+		prg, err := compileWIF(expr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("attribute mapping %s: %w", key, err)
+		}
+		out, _, err := prg.Eval(map[string]any{"assertion": claims})
+		if err != nil {
+			continue
+		}
+		if ns == "google" {
+			google[name] = out.Value()
+		} else {
+			attribute[name] = out.Value()
+		}
+	}
+	return google, attribute, nil
+}
+
+func (p *WIFProvider) evalCondition(claims, google, attribute map[string]any) (bool, error) {
+	if p.AttributeCondition == "" {
+		return true, nil
+	}
+	prg, err := compileWIF(p.AttributeCondition)
+	if err != nil {
+		return false, fmt.Errorf("attribute condition: %w", err)
+	}
+	out, _, err := prg.Eval(map[string]any{"assertion": claims, "google": google, "attribute": attribute})
+	if err != nil {
+		return false, nil
+	}
+	return out == types.True, nil
+}
+
+func compileWIF(expr string) (cel.Program, error) {
+	ast, iss := wifEnv.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	return wifEnv.Program(ast)
+}
+
+// GitHubToken describes a GitHub Actions OIDC token by the fields that vary
+// between workflows; Claims fills in the rest the way GitHub does.
+type GitHubToken struct {
+	// Repository is owner/repo of the repository the workflow runs in. For a
+	// pull request from a fork that is the base repository, not the fork.
+	Repository string
+	// Ref is e.g. refs/heads/main, refs/tags/v1.0.0 or refs/pull/7/merge.
+	Ref string
+	// EventName defaults to pull_request for refs/pull/ refs and push
+	// otherwise.
+	EventName string
+	// Audience defaults to https://github.com/<owner>, GitHub's default.
+	Audience string
+	// Actor defaults to the repository owner.
+	Actor string
+}
+
+// Claims returns the token's claims as the wif module's mapping sees them.
+// iss and the time claims are left to the issuer (see oidc.Issuer.Mint).
+func (g GitHubToken) Claims() map[string]any {
+	owner, _, _ := strings.Cut(g.Repository, "/")
+	event := g.EventName
+	if event == "" {
+		event = "push"
+		if strings.HasPrefix(g.Ref, "refs/pull/") {
+			event = "pull_request"
+		}
+	}
+	aud := g.Audience
+	if aud == "" {
+		aud = "https://github.com/" + owner
+	}
+	actor := g.Actor
+	if actor == "" {
+		actor = owner
+	}
+	refType := "branch"
+	if strings.HasPrefix(g.Ref, "refs/tags/") {
+		refType = "tag"
+	}
+	sub := fmt.Sprintf("repo:%s:ref:%s", g.Repository, g.Ref)
+	if event == "pull_request" {
+		sub = fmt.Sprintf("repo:%s:pull_request", g.Repository)
+	}
+	return map[string]any{
+		"sub":              sub,
+		"aud":              aud,
+		"repository":       g.Repository,
+		"repository_owner": owner,
+		"ref":              g.Ref,
+		"ref_type":         refType,
+		"event_name":       event,
+		"actor":            actor,
+		"workflow":         "ci",
+		"sha":              "0123456789abcdef0123456789abcdef01234567",
+	}
+}
+
+// WIFCase is one token and whether the provider must accept it.
+type WIFCase struct {
+	Name   string
+	Token  GitHubToken
+	Accept bool
+}
+
+// RequireWIFDecisions runs each case as a subtest against p, which may come
+// from PlannedWIFProvider or DescribeWIFProvider.
+//
+// Usage:
+//
+//	p := PlannedWIFProvider(t, PlanJSON(t, tf))
+//	RequireWIFDecisions(t, p, []WIFCase{
+//		{Name: "main", Token: GitHubToken{Repository: "acme/app", Ref: "refs/heads/main"}, Accept: true},
+//		{Name: "tag", Token: GitHubToken{Repository: "acme/app", Ref: "refs/tags/v1.0.0"}},
+//	})
+func RequireWIFDecisions(t *testing.T, p *WIFProvider, cases []WIFCase) {
+	t.Helper()
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := p.Accepts(c.Token.Claims())
+			require.NoError(t, err)
+			require.Equalf(t, c.Accept, got, "token %+v against condition %s", c.Token, p.AttributeCondition)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// acmeCases are GitHub tokens against a provider allowing acme/app and
+// acme/infra, owner acme, on main and release/* branches.
+var acmeCases = []WIFCase{
+	{Name: "push to main", Token: GitHubToken{Repository: "acme/app", Ref: "refs/heads/main"}, Accept: true},
+	{Name: "second repository", Token: GitHubToken{Repository: "acme/infra", Ref: "refs/heads/main"}, Accept: true},
+	{Name: "release branch", Token: GitHubToken{Repository: "acme/app", Ref: "refs/heads/release/1.2"}, Accept: true},
+	{Name: "nested release branch", Token: GitHubToken{Repository: "acme/app", Ref: "refs/heads/release/1.2/hotfix"}, Accept: true},
+
+	{Name: "fork with the same name", Token: GitHubToken{Repository: "mallory/app", Ref: "refs/heads/main"}},
+	{Name: "pull request from a fork", Token: GitHubToken{Repository: "acme/app", Ref: "refs/pull/7/merge", Actor: "mallory"}},
+	{Name: "other owner", Token: GitHubToken{Repository: "other/infra", Ref: "refs/heads/main"}},
+	{Name: "unlisted repository", Token: GitHubToken{Repository: "acme/website", Ref: "refs/heads/main"}},
+	{Name: "repository name prefix", Token: GitHubToken{Repository: "acme/app-fork", Ref: "refs/heads/main"}},
+	{Name: "tag", Token: GitHubToken{Repository: "acme/app", Ref: "refs/tags/v1.0.0"}},
+	{Name: "tag named like a branch", Token: GitHubToken{Repository: "acme/app", Ref: "refs/tags/main"}},
+	{Name: "feature branch", Token: GitHubToken{Repository: "acme/app", Ref: "refs/heads/feature/x"}},
+	{Name: "branch prefixed main", Token: GitHubToken{Repository: "acme/app", Ref: "refs/heads/main-old"}},
+	{Name: "release without slash", Token: GitHubToken{Repository: "acme/app", Ref: "refs/heads/release"}},
+	{Name: "branch prefixed release", Token: GitHubToken{Repository: "acme/app", Ref: "refs/heads/releases/1.2"}},
+}
+
+// TestWIFCondition_RecordedPlan evaluates a recorded wif plan so the CEL
+// evaluation runs without tofu (see TestWIF_AttributeConditionCEL for the
+// same cases against a live plan).
+func TestWIFCondition_RecordedPlan(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "plan", "wif.json"))
+	require.NoError(t, err)
+
+	p := PlannedWIFProvider(t, ParsePlanJSON(t, string(raw)))
+	require.Contains(t, p.AttributeMapping, "attribute.ref")
+	RequireWIFDecisions(t, p, acmeCases)
+}
+
+func TestWIFCondition_Override(t *testing.T) {
+	p := &WIFProvider{
+		AttributeCondition: `assertion.repository_owner == "acme" && assertion.event_name != "pull_request"`,
+		AttributeMapping:   map[string]string{"google.subject": "assertion.sub"},
+	}
+	RequireWIFDecisions(t, p, []WIFCase{
+		{Name: "push", Token: GitHubToken{Repository: "acme/any", Ref: "refs/tags/v2"}, Accept: true},
+		{Name: "pull request", Token: GitHubToken{Repository: "acme/any", Ref: "refs/pull/1/merge"}},
+		{Name: "other owner", Token: GitHubToken{Repository: "other/any", Ref: "refs/heads/main"}},
+	})
+}
+
+func TestWIFCondition_UnmappedAttributeRejects(t *testing.T) {
+	// attribute.ref is never mapped, so the condition fails at runtime and
+	// the token is rejected rather than the comparison being skipped.
+	p := &WIFProvider{
+		AttributeCondition: `attribute.ref == "refs/heads/main"`,
+		AttributeMapping:   map[string]string{"attribute.repository": "assertion.repository"},
+	}
+	ok, err := p.Accepts(GitHubToken{Repository: "acme/app", Ref: "refs/heads/main"}.Claims())
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestWIFCondition_InvalidCEL(t *testing.T) {
+	// The global form of startsWith is not standard CEL.
+	p := &WIFProvider{AttributeCondition: `startsWith(attribute.ref, "refs/heads/")`}
+	_, err := p.Accepts(GitHubToken{Repository: "acme/app", Ref: "refs/heads/main"}.Claims())
+	require.ErrorContains(t, err, "attribute condition")
+
+	p = &WIFProvider{AttributeMapping: map[string]string{"subject": "assertion.sub"}}
+	_, err = p.Accepts(GitHubToken{Repository: "acme/app", Ref: "refs/heads/main"}.Claims())
+	require.ErrorContains(t, err, "must be google.<name> or attribute.<name>")
+}
+
+func TestGitHubToken_Claims(t *testing.T) {
+	push := GitHubToken{Repository: "acme/app", Ref: "refs/tags/v1"}.Claims()
+	require.Equal(t, "repo:acme/app:ref:refs/tags/v1", push["sub"])
+	require.Equal(t, "tag", push["ref_type"])
+	require.Equal(t, "push", push["event_name"])
+	require.Equal(t, "acme", push["repository_owner"])
+	require.Equal(t, "https://github.com/acme", push["aud"])
+
+	pr := GitHubToken{Repository: "acme/app", Ref: "refs/pull/3/merge"}.Claims()
+	require.Equal(t, "repo:acme/app:pull_request", pr["sub"])
+	require.Equal(t, "pull_request", pr["event_name"])
+}
@@ -1,88 +1,206 @@
 package test
 
 import (
-	"fmt"
-	"strings"
 	"testing"
 
-	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/require"
+
+	"github.com/simon-lentz/neo4j_gke/test/oidc"
 )
 
 func TestWIF_CreateDescribeDestroy(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
+	t.Parallel()
 
-	// Validate timeout before creating resources
-	RequireMinimumTimeout(t, DefaultTestTimeout)
+	budget := NewBudget(t, "wif")
 
 	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
 
-	tfDir := CopyModuleToTemp(t, "wif")
-	suffix := strings.ToLower(random.UniqueId())
-	poolID := fmt.Sprintf("gha-terratest-%s", suffix)
-
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    tfDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":               projectID,
-			"pool_id":                  poolID,
-			"provider_id":              "github",
-			"issuer_uri":               "https://token.actions.githubusercontent.com",
-			"allowed_repositories":     []string{"acme/example"},
-			"allowed_refs":             []string{"refs/heads/main"},
-			"prevent_destroy_pool":     false,
-			"prevent_destroy_provider": false,
-		},
-		NoColor: true,
-	})
+	// A stable pool ID, so the pool a previous run destroyed is found
+	// soft-deleted and adopted. The lease is held until destroy finishes.
+	poolID := "gha-terratest"
+	AcquireLease(t, WIFPoolLeaseKey(projectID, poolID))
+
+	wif := &WIFOptions{
+		ProjectID:              projectID,
+		PoolID:                 Ptr(poolID),
+		ProviderID:             Ptr("github"),
+		IssuerURI:              Ptr("https://token.actions.githubusercontent.com"),
+		AllowedRepositories:    []string{"acme/example"},
+		AllowedRefs:            []string{"refs/heads/main"},
+		PreventDestroyPool:     Ptr(false),
+		PreventDestroyProvider: Ptr(false),
+	}
+	tf := wif.TerraformOptions(t, CopyModuleToTemp(t, wif.Module()))
 
 	// Register cleanup BEFORE creating resources
-	DeferredTerraformCleanup(t, tf)
-	terraform.InitAndApply(t, tf)
+	budget.Cleanup(wif.Module(), tf)
+	// Pools and providers linger soft-deleted for 30 days after destroy and
+	// block their names; restore and import the previous run's.
+	Adopt(t, tf, projectID, map[string]string{"pool": *wif.PoolID, "provider": *wif.ProviderID}, WIFAdoption)
+	budget.Apply(wif.Module(), tf)
 
 	providerName, err := terraform.OutputE(t, tf, "provider_name")
 	require.NoError(t, err, "failed to get provider_name output")
 
 	// gcloud describe the provider and assert issuer + condition
-	out := runGCLOUD(t, projectID, "iam", "workload-identity-pools", "providers", "describe",
-		providerName,
-		"--format=value(oidc.issuerUri,attributeCondition)",
-	)
-	parts := strings.Split(strings.TrimSpace(out), "\n")
-	require.GreaterOrEqual(t, len(parts), 1)
-	require.Contains(t, parts[0], "https://token.actions.githubusercontent.com")
+	provider := DescribeWIFProvider(t, projectID, providerName)
+	require.Equal(t, "https://token.actions.githubusercontent.com", provider.OIDC.IssuerURI)
 	// attribute condition should contain both repo and ref
-	require.Contains(t, out, `attribute.repository == "acme/example"`)
-	require.Contains(t, out, `attribute.ref == "refs/heads/main"`)
+	require.Contains(t, provider.AttributeCondition, `attribute.repository == "acme/example"`)
+	require.Contains(t, provider.AttributeCondition, `attribute.ref == "refs/heads/main"`)
+
+	// The condition Google stored must decide tokens as planned.
+	RequireWIFDecisions(t, provider, []WIFCase{
+		{Name: "main", Token: GitHubToken{Repository: "acme/example", Ref: "refs/heads/main"}, Accept: true},
+		{Name: "fork", Token: GitHubToken{Repository: "mallory/example", Ref: "refs/heads/main"}},
+		{Name: "tag", Token: GitHubToken{Repository: "acme/example", Ref: "refs/tags/v1"}},
+	})
+}
+
+// TestWIF_AttributeConditionCEL renders the attribute condition through the
+// module and evaluates it against GitHub token fixtures, proving which tokens
+// each selector combination accepts without creating a pool. It needs tofu but
+// no GCP project.
+func TestWIF_AttributeConditionCEL(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("Skipping WIF plan test in short mode (runs tofu init and plan)")
+	}
+
+	tests := []struct {
+		name  string
+		wif   WIFOptions
+		cases []WIFCase
+	}{
+		{
+			name: "repositories owners and refs",
+			wif: WIFOptions{
+				AllowedRepositories:     []string{"acme/app", "acme/infra"},
+				AllowedRepositoryOwners: []string{"acme"},
+				AllowedRefs:             []string{"refs/heads/main", "refs/heads/release/*"},
+			},
+			cases: acmeCases,
+		},
+		{
+			name: "owner only",
+			wif:  WIFOptions{AllowedRepositoryOwners: []string{"acme"}},
+			cases: []WIFCase{
+				{Name: "any repository", Token: GitHubToken{Repository: "acme/website", Ref: "refs/heads/main"}, Accept: true},
+				{Name: "tag", Token: GitHubToken{Repository: "acme/app", Ref: "refs/tags/v1.0.0"}, Accept: true},
+				{Name: "pull request", Token: GitHubToken{Repository: "acme/app", Ref: "refs/pull/7/merge"}, Accept: true},
+				{Name: "fork", Token: GitHubToken{Repository: "mallory/app", Ref: "refs/heads/main"}},
+				{Name: "owner prefix", Token: GitHubToken{Repository: "acme-corp/app", Ref: "refs/heads/main"}},
+			},
+		},
+		{
+			name: "tags and audience",
+			wif: WIFOptions{
+				AllowedRepositories: []string{"acme/app"},
+				AllowedRefs:         []string{"refs/tags/*"},
+				AllowedAudiences:    []string{"neo4j-gke"},
+			},
+			cases: []WIFCase{
+				{Name: "tag", Token: GitHubToken{Repository: "acme/app", Ref: "refs/tags/v1.0.0", Audience: "neo4j-gke"}, Accept: true},
+				{Name: "default audience", Token: GitHubToken{Repository: "acme/app", Ref: "refs/tags/v1.0.0"}},
+				{Name: "branch", Token: GitHubToken{Repository: "acme/app", Ref: "refs/heads/main", Audience: "neo4j-gke"}},
+			},
+		},
+		{
+			name: "override",
+			wif: WIFOptions{
+				AllowedRepositories:        []string{"acme/app"},
+				AttributeConditionOverride: Ptr(`attribute.repository_owner == "acme" && attribute.event_name != "pull_request"`),
+			},
+			cases: []WIFCase{
+				{Name: "unlisted repository", Token: GitHubToken{Repository: "acme/website", Ref: "refs/heads/main"}, Accept: true},
+				{Name: "pull request", Token: GitHubToken{Repository: "acme/app", Ref: "refs/pull/7/merge"}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			wif := tc.wif
+			wif.ProjectID = "plan-only-project"
+			wif.PreventDestroyPool = Ptr(false)
+			wif.PreventDestroyProvider = Ptr(false)
+			tf := wif.TerraformOptions(t, CopyModuleToTemp(t, wif.Module()))
+			// Planning new resources makes no API calls, but the provider
+			// still wants credentials.
+			tf.EnvVars = map[string]string{"GOOGLE_OAUTH_ACCESS_TOKEN": "plan-only"}
+
+			RequireWIFDecisions(t, PlannedWIFProvider(t, PlanJSON(t, tf)), tc.cases)
+		})
+	}
 }
 
 func TestWIF_PreconditionFailsWithoutSelectors(t *testing.T) {
-	// Sequential execution required: Tests share GCP project resources
-	// and lack isolation mechanisms for safe parallel execution.
+	t.Parallel()
 
 	projectID := MustEnv(t, "NEO4J_GKE_GCP_PROJECT_ID")
-	tfDir := CopyModuleToTemp(t, "wif")
-	suffix := strings.ToLower(random.UniqueId())
-	poolID := fmt.Sprintf("gha-precond-%s", suffix)
-
-	tf := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:    tfDir,
-		TerraformBinary: "tofu",
-		Vars: map[string]any{
-			"project_id":  projectID,
-			"pool_id":     poolID,
-			"provider_id": "github",
-			// NOTE: all selectors empty, no override
-			"prevent_destroy_pool":     false,
-			"prevent_destroy_provider": false,
-		},
-		NoColor: true,
-	})
 
-	_, err := terraform.InitAndPlanE(t, tf)
+	wif := &WIFOptions{
+		ProjectID:  projectID,
+		PoolID:     Ptr(UniqueName(t, NameWIFPool, "gha-precond")),
+		ProviderID: Ptr("github"),
+		// NOTE: all selectors empty, no override
+		PreventDestroyPool:     Ptr(false),
+		PreventDestroyProvider: Ptr(false),
+	}
+	tf := wif.TerraformOptions(t, CopyModuleToTemp(t, wif.Module()))
+
+	_, err := PlanJSONE(t, tf)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "You must specify at least one selector")
 }
+
+// TestWIF_TokenExchange points issuer_uri at a local OIDC issuer and exchanges
+// minted tokens against the planned provider, covering the module's merge of
+// attribute_mapping_extra and its allowed_audiences. It needs tofu but no GCP
+// project.
+func TestWIF_TokenExchange(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("Skipping WIF plan test in short mode (runs tofu init and plan)")
+	}
+
+	iss := oidc.NewIssuer(t)
+	wif := &WIFOptions{
+		ProjectID:              "plan-only-project",
+		IssuerURI:              Ptr(iss.URL),
+		AllowedRepositories:    []string{"acme/app"},
+		AllowedRefs:            []string{"refs/heads/main"},
+		AllowedAudiences:       []string{"neo4j-gke", "neo4j-gke-ci"},
+		AttributeMappingExtra:  map[string]string{"attribute.environment": "assertion.environment"},
+		PreventDestroyPool:     Ptr(false),
+		PreventDestroyProvider: Ptr(false),
+	}
+	tf := wif.TerraformOptions(t, CopyModuleToTemp(t, wif.Module()))
+	tf.EnvVars = map[string]string{"GOOGLE_OAUTH_ACCESS_TOKEN": "plan-only"}
+
+	p := PlannedWIFProvider(t, PlanJSON(t, tf))
+	require.Equal(t, iss.URL, p.OIDC.IssuerURI)
+	require.ElementsMatch(t, wif.AllowedAudiences, p.OIDC.AllowedAudiences)
+
+	ctx := t.Context()
+	tok := GitHubToken{Repository: "acme/app", Ref: "refs/heads/main", Audience: "neo4j-gke-ci", Actor: "octocat"}
+	principal, err := p.Exchange(ctx, iss.Client, mintGitHub(t, iss, tok, map[string]any{"environment": "dev"}))
+	require.NoError(t, err)
+	require.Equal(t, "repo:acme/app:ref:refs/heads/main", principal.Subject)
+	require.Equal(t, "dev", principal.Attributes["environment"], "attribute_mapping_extra not applied")
+	require.Equal(t, "octocat", principal.Attributes["actor"], "base mapping lost in the merge")
+
+	// Wrong audience, then a pull request ref, are refused.
+	tok.Audience = "https://github.com/acme"
+	_, err = p.Exchange(ctx, iss.Client, mintGitHub(t, iss, tok, nil))
+	require.ErrorContains(t, err, "audience")
+
+	tok.Audience, tok.Ref = "neo4j-gke", "refs/pull/7/merge"
+	_, err = p.Exchange(ctx, iss.Client, mintGitHub(t, iss, tok, nil))
+	require.ErrorContains(t, err, "attribute condition")
+}
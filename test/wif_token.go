@@ -0,0 +1,81 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/simon-lentz/neo4j_gke/test/oidc"
+)
+
+// WIFPrincipal is the federated identity an accepted token maps to.
+type WIFPrincipal struct {
+	// Subject is google.subject, the principal://.../subject/<Subject> part.
+	Subject string
+	// Attributes are the attribute.* values, usable in principalSet members.
+	Attributes map[string]any
+}
+
+// Exchange validates token the way the pool's token exchange does, without a
+// pool: it verifies the token against the issuer at p.OIDC.IssuerURI (using
+// client, e.g. an oidc.Issuer's), checks the audience, applies the attribute
+// mapping and then the attribute condition.
+//
+// The audience must be one of p.OIDC.AllowedAudiences. When there are none,
+// Google accepts the provider's own resource name with or without the https:
+// prefix, which needs p.Name (set by DescribeWIFProvider, not by
+// PlannedWIFProvider).
+func (p *WIFProvider) Exchange(ctx context.Context, client *http.Client, token string) (*WIFPrincipal, error) {
+	claims, err := oidc.Verify(ctx, client, p.OIDC.IssuerURI, token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := p.OIDC.AllowedAudiences
+	if len(allowed) == 0 {
+		if p.Name == "" {
+			return nil, errors.New("provider has no allowed audiences and no name to derive the default audience from")
+		}
+		allowed = []string{"//iam.googleapis.com/" + p.Name, "https://iam.googleapis.com/" + p.Name}
+	}
+	if !slices.ContainsFunc(tokenAudiences(claims), func(a string) bool { return slices.Contains(allowed, a) }) {
+		return nil, fmt.Errorf("token audience %v is not one of %v", claims["aud"], allowed)
+	}
+
+	google, attribute, err := p.mapClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+	subject, ok := google["subject"].(string)
+	if !ok || subject == "" {
+		return nil, errors.New("the attribute mapping yields no google.subject")
+	}
+	accepted, err := p.evalCondition(claims, google, attribute)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		return nil, fmt.Errorf("token rejected by attribute condition %s", p.AttributeCondition)
+	}
+	return &WIFPrincipal{Subject: subject, Attributes: attribute}, nil
+}
+
+// tokenAudiences returns the aud claim, which is a string or a list.
+func tokenAudiences(claims map[string]any) []string {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []any:
+		out := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/simon-lentz/neo4j_gke/test/oidc"
+)
+
+// mintGitHub mints tok's claims, plus extra, from iss.
+func mintGitHub(t *testing.T, iss *oidc.Issuer, tok GitHubToken, extra map[string]any) string {
+	t.Helper()
+	claims := tok.Claims()
+	for k, v := range extra {
+		claims[k] = v
+	}
+	token, err := iss.Mint(claims)
+	require.NoError(t, err)
+	return token
+}
+
+func TestWIFProvider_Exchange(t *testing.T) {
+	iss := oidc.NewIssuer(t)
+	raw, err := os.ReadFile(filepath.Join("testdata", "plan", "wif.json"))
+	require.NoError(t, err)
+
+	// The recorded plan's provider, pointed at the test issuer, with an
+	// audience and an attribute_mapping_extra entry.
+	p := PlannedWIFProvider(t, ParsePlanJSON(t, string(raw)))
+	p.OIDC.IssuerURI = iss.URL
+	p.OIDC.AllowedAudiences = []string{"neo4j-gke"}
+	p.AttributeMapping["attribute.repository_id"] = "assertion.repository_id"
+
+	ctx := context.Background()
+	onMain := GitHubToken{Repository: "acme/app", Ref: "refs/heads/main", Audience: "neo4j-gke", Actor: "octocat"}
+
+	principal, err := p.Exchange(ctx, iss.Client, mintGitHub(t, iss, onMain, map[string]any{"repository_id": "4242"}))
+	require.NoError(t, err)
+	require.Equal(t, "repo:acme/app:ref:refs/heads/main", principal.Subject)
+	require.Equal(t, "4242", principal.Attributes["repository_id"])
+	require.Equal(t, "octocat", principal.Attributes["actor"])
+	require.Equal(t, "acme", principal.Attributes["repository_owner"])
+
+	// A list audience passes when any entry is allowed.
+	_, err = p.Exchange(ctx, iss.Client, mintGitHub(t, iss, onMain, map[string]any{"aud": []string{"other", "neo4j-gke"}}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"default GitHub audience", mintGitHub(t, iss, GitHubToken{Repository: "acme/app", Ref: "refs/heads/main"}, nil), "audience"},
+		{"fork", mintGitHub(t, iss, GitHubToken{Repository: "mallory/app", Ref: "refs/heads/main", Audience: "neo4j-gke"}, nil), "attribute condition"},
+		{"tag", mintGitHub(t, iss, GitHubToken{Repository: "acme/app", Ref: "refs/tags/v1", Audience: "neo4j-gke"}, nil), "attribute condition"},
+		{"expired", mintGitHub(t, iss, onMain, map[string]any{"exp": time.Now().Add(-time.Minute).Unix()}), "expired"},
+		{"other issuer", mintGitHub(t, oidc.NewIssuer(t), onMain, map[string]any{"iss": iss.URL}), "does not verify"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := p.Exchange(ctx, iss.Client, tc.token)
+			require.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestWIFProvider_ExchangeDefaultAudience(t *testing.T) {
+	iss := oidc.NewIssuer(t)
+	p := &WIFProvider{
+		Name:             "projects/123/locations/global/workloadIdentityPools/github-pool/providers/github",
+		AttributeMapping: map[string]string{"google.subject": "assertion.sub"},
+	}
+	p.OIDC.IssuerURI = iss.URL
+	tok := GitHubToken{Repository: "acme/app", Ref: "refs/heads/main"}
+
+	for _, aud := range []string{"//iam.googleapis.com/" + p.Name, "https://iam.googleapis.com/" + p.Name} {
+		tok.Audience = aud
+		_, err := p.Exchange(context.Background(), iss.Client, mintGitHub(t, iss, tok, nil))
+		require.NoError(t, err, aud)
+	}
+
+	tok.Audience = ""
+	_, err := p.Exchange(context.Background(), iss.Client, mintGitHub(t, iss, tok, nil))
+	require.ErrorContains(t, err, "audience")
+
+	p.Name = ""
+	_, err = p.Exchange(context.Background(), iss.Client, mintGitHub(t, iss, tok, nil))
+	require.ErrorContains(t, err, "no allowed audiences")
+}
+
+func TestWIFProvider_ExchangeRequiresSubject(t *testing.T) {
+	iss := oidc.NewIssuer(t)
+	p := &WIFProvider{AttributeMapping: map[string]string{"attribute.repository": "assertion.repository"}}
+	p.OIDC.IssuerURI = iss.URL
+	p.OIDC.AllowedAudiences = []string{"neo4j-gke"}
+
+	tok := GitHubToken{Repository: "acme/app", Ref: "refs/heads/main", Audience: "neo4j-gke"}
+	_, err := p.Exchange(context.Background(), iss.Client, mintGitHub(t, iss, tok, nil))
+	require.ErrorContains(t, err, "google.subject")
+}